@@ -15,6 +15,8 @@
 package aidl_library
 
 import (
+	"regexp"
+
 	"android/soong/android"
 	"android/soong/bazel"
 
@@ -22,6 +24,10 @@ import (
 	"github.com/google/blueprint/proptools"
 )
 
+// versionedAidlBackendSuffix matches the language backend suffix of modules generated for a
+// frozen version of an aidl_interface, e.g. "-ndk" in "foo-V2-ndk".
+var versionedAidlBackendSuffix = regexp.MustCompile(`(-V[0-9]+)-(cpp|ndk|ndk_platform|java|rust)$`)
+
 var PrepareForTestWithAidlLibrary = android.FixtureRegisterWithContext(func(ctx android.RegistrationContext) {
 	registerAidlLibraryBuildComponents(ctx)
 })
@@ -57,6 +63,17 @@ type AidlLibrary struct {
 	properties aidlLibraryProperties
 }
 
+// bp2buildAidlLibraryDeps returns the labels of the given aidl_library deps. Deps on a language
+// backend of a versioned aidl_interface (e.g. foo-V2-ndk) are resolved to the aidl_library
+// target generated for that version (e.g. foo-V2), since only the .aidl files are needed.
+func bp2buildAidlLibraryDeps(ctx android.Bp2buildMutatorContext, deps []string) bazel.LabelList {
+	labels := android.BazelLabelForModuleDeps(ctx, deps)
+	for i, label := range labels.Includes {
+		labels.Includes[i].Label = versionedAidlBackendSuffix.ReplaceAllString(label.Label, "$1")
+	}
+	return labels
+}
+
 type bazelAidlLibraryAttributes struct {
 	Srcs                bazel.LabelListAttribute
 	Hdrs                bazel.LabelListAttribute
@@ -80,7 +97,7 @@ func (lib *AidlLibrary) ConvertWithBp2build(ctx android.Bp2buildMutatorContext)
 	)
 
 	tags := []string{"apex_available=//apex_available:anyapex"}
-	deps := bazel.MakeLabelListAttribute(android.BazelLabelForModuleDeps(ctx, lib.properties.Deps))
+	deps := bazel.MakeLabelListAttribute(bp2buildAidlLibraryDeps(ctx, lib.properties.Deps))
 
 	attrs := &bazelAidlLibraryAttributes{
 		Srcs:                srcs,
@@ -0,0 +1,55 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"android/soong/bazel"
+)
+
+var (
+	aidlIncludeDirGeneratedSuffix = ".include_dir_bp2build_generated_aidl"
+	aidlIncludeDirsBp2buildKey    = NewOnceKey("aidlIncludeDirsBp2build")
+)
+
+// bazelAidlIncludeDirAttributes are the attributes of the aidl_library created for an
+// aidl.include_dirs entry. The .aidl files are only imported by the srcs of the modules
+// listing the directory, so they are hdrs rather than srcs.
+type bazelAidlIncludeDirAttributes struct {
+	Hdrs bazel.LabelListAttribute
+}
+
+// CreateAidlLibraryTargetsForIncludeDirs creates additional aidl_library targets for .aidl files
+// in includeDirs, to be used as deps of the aidl_library of the srcs of a module.
+// Since Bazel imposes a constraint that the aidl_library must be in the same package as the .aidl
+// file, and the import path of an aidl_library is relative to its package, the .aidl files of
+// includeDir must not be in a subpackage of includeDir.
+// Returns the labels of the aidl_library targets
+func CreateAidlLibraryTargetsForIncludeDirs(ctx Bp2buildMutatorContext, includeDirs []string) bazel.LabelList {
+	return createLibraryTargetsForIncludeDirs(ctx, includeDirLibraries{
+		property: "aidl.include_dir",
+		pattern:  "**/*.aidl",
+		suffix:   aidlIncludeDirGeneratedSuffix,
+		onceKey:  aidlIncludeDirsBp2buildKey,
+		props: bazel.BazelTargetModuleProperties{
+			Rule_class:        "aidl_library",
+			Bzl_load_location: "//build/bazel/rules/aidl:aidl_library.bzl",
+		},
+		attrs: func(dir, pkg string, srcs bazel.LabelList) interface{} {
+			return &bazelAidlIncludeDirAttributes{
+				Hdrs: bazel.MakeLabelListAttribute(srcs),
+			}
+		},
+	}, includeDirs)
+}
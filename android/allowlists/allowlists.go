@@ -996,6 +996,19 @@ var (
 		"fake_device_config",
 	}
 
+	// Properties which only affect installation and are known to be dropped by bp2build. Modules
+	// converted while setting any of these get a warning in the bp2build metrics report, unless
+	// one of their targets has an attribute of the same name.
+	Bp2buildKnownDroppedProperties = []string{
+		// go/keep-sorted start
+		"host_required",
+		"init_rc",
+		"required",
+		"target_required",
+		"vintf_fragments",
+		// go/keep-sorted end
+	}
+
 	Bp2buildModuleTypeAlwaysConvertList = []string{
 		// go/keep-sorted start
 		"aconfig_declarations",
@@ -1357,6 +1357,14 @@ func getArchProperties(ctx BaseMutatorContext, archProperties interface{}, arch
 			}
 		}
 
+		if os.Linux() && os.Class == Host {
+			field := "Host_linux_" + archType.Name
+			userFriendlyField := "target.host_linux_" + archType.Name
+			if hostLinuxProperties, ok := getChildPropertyStruct(ctx, targetProp, field, userFriendlyField); ok {
+				result = append(result, hostLinuxProperties)
+			}
+		}
+
 		if os.Bionic() {
 			field := "Bionic_" + archType.Name
 			userFriendlyField := "target.bionic_" + archType.Name
@@ -2186,14 +2194,19 @@ func (m *ModuleBase) GetArchVariantProperties(ctx ArchVariantContext, propertySe
 		for _, arch := range osArchTypeMap[os] {
 			osArchStructs := make([]reflect.Value, 0)
 
-			// Auto-combine with Linux_ and Bionic_ targets. This potentially results in
-			// repetition and select() bloat, but use of Linux_* and Bionic_* targets is rare.
+			// Auto-combine with Linux_, Host_linux_ and Bionic_ targets. This potentially results in
+			// repetition and select() bloat, but use of these targets is rare.
 			// TODO(b/201423152): Look into cleanup.
 			if os.Linux() {
 				targetField := "Linux_" + arch.Name
 				targetStructs := getTargetStructs(ctx, archProperties, targetField)
 				osArchStructs = append(osArchStructs, targetStructs...)
 			}
+			if os.Linux() && os.Class == Host {
+				targetField := "Host_linux_" + arch.Name
+				targetStructs := getTargetStructs(ctx, archProperties, targetField)
+				osArchStructs = append(osArchStructs, targetStructs...)
+			}
 			if os.Bionic() {
 				targetField := "Bionic_" + arch.Name
 				targetStructs := getTargetStructs(ctx, archProperties, targetField)
@@ -2224,6 +2237,23 @@ func (m *ModuleBase) GetArchVariantProperties(ctx ArchVariantContext, propertySe
 	return axisToProps
 }
 
+// GetNativeBridgeProperties returns a struct matching the propertySet interface, containing the
+// properties of the target.native_bridge block of the module, which only apply to its variants
+// translated with native bridge. It returns nil if the module does not have the property set
+// requested.
+func (m *ModuleBase) GetNativeBridgeProperties(ctx ArchVariantContext, propertySet interface{}) interface{} {
+	if !m.ArchSpecific() {
+		return nil
+	}
+	dstType := reflect.ValueOf(propertySet).Type()
+	for i, generalProp := range m.GetProperties() {
+		if reflect.ValueOf(generalProp).Type() == dstType {
+			return mergeStructs(ctx, getTargetStructs(ctx, m.archProperties[i], "Native_bridge"), propertySet)
+		}
+	}
+	return nil
+}
+
 // Returns a struct matching the propertySet interface, containing properties specific to the targetName
 // For example, given these arguments:
 //
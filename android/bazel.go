@@ -16,8 +16,11 @@ package android
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"reflect"
 	"strings"
 
 	"android/soong/ui/metrics/bp2build_metrics_proto"
@@ -78,6 +81,10 @@ type BazelConversionStatus struct {
 	// MissingBp2buildDep stores the module names of direct dependency that were not found
 	MissingDeps []string `blueprint:"mutated"`
 
+	// Warnings stores messages about parts of the module that bp2build dropped or altered while
+	// converting it. Unlike UnconvertedReason, they do not prevent the module from being converted.
+	Warnings []string `blueprint:"mutated"`
+
 	// If non-nil, indicates that the module could not be converted successfully
 	// with bp2build. This will describe the reason the module could not be converted.
 	UnconvertedReason *UnconvertedReason
@@ -115,6 +122,11 @@ type BazelModuleProperties struct {
 	// To defer the default setting for the directory, do not set the value.
 	Bp2build_available *bool
 
+	// If true, bp2build will add the "manual" tag to all Bazel targets generated for this module,
+	// excluding them from wildcard target patterns such as //... while keeping them buildable as
+	// explicit dependencies.
+	Manual *bool
+
 	// CanConvertToBazel is set via InitBazelModule to indicate that a module type can be converted to
 	// Bazel with Bp2build.
 	CanConvertToBazel bool `blueprint:"mutated"`
@@ -405,6 +417,83 @@ func (a Bp2BuildConversionAllowlist) ShouldKeepExistingBuildFileForDir(dir strin
 	}
 }
 
+// bp2buildAllowlistFile is the JSON format of a file adding entries to the built-in allowlists
+// without recompiling soong_build, e.g.
+//
+//	{
+//	  "Default_config": {"vendor/foo": "Bp2BuildDefaultTrueRecursively", "vendor/foo/bar": "Bp2BuildDefaultFalse"},
+//	  "Keep_existing_build_file": {"vendor/foo/bazel": true},
+//	  "Module_always_convert": ["libbaz"],
+//	  "Module_type_always_convert": ["foo_module_type"],
+//	  "Module_do_not_convert": ["libqux"]
+//	}
+type bp2buildAllowlistFile struct {
+	Default_config             map[string]string
+	Keep_existing_build_file   map[string]bool
+	Module_always_convert      []string
+	Module_type_always_convert []string
+	Module_do_not_convert      []string
+}
+
+var bp2buildConfigEntries = map[string]allowlists.BazelConversionConfigEntry{
+	"Bp2BuildDefaultTrueRecursively":  allowlists.Bp2BuildDefaultTrueRecursively,
+	"Bp2BuildDefaultTrue":             allowlists.Bp2BuildDefaultTrue,
+	"Bp2BuildDefaultFalse":            allowlists.Bp2BuildDefaultFalse,
+	"Bp2BuildDefaultFalseRecursively": allowlists.Bp2BuildDefaultFalseRecursively,
+}
+
+// WithAllowlistFiles returns a copy of the allowlist with the entries of the given JSON allowlist
+// files (see bp2buildAllowlistFile) added. Directory entries of later files override those of
+// earlier files and of the built-in allowlist. Likewise, a module listed in Module_always_convert
+// or Module_do_not_convert of a file is removed from the other list of earlier files and of the
+// built-in allowlist, so that a file can opt individual modules in or out.
+func (a Bp2BuildConversionAllowlist) WithAllowlistFiles(files []string) (Bp2BuildConversionAllowlist, error) {
+	if len(files) == 0 {
+		return a, nil
+	}
+	ret := NewBp2BuildAllowlist().SetDefaultConfig(a.defaultConfig).
+		SetKeepExistingBuildFile(a.keepExistingBuildFile).
+		SetModuleAlwaysConvertList(SortedKeys(a.moduleAlwaysConvert)).
+		SetModuleTypeAlwaysConvertList(SortedKeys(a.moduleTypeAlwaysConvert)).
+		SetModuleDoNotConvertList(SortedKeys(a.moduleDoNotConvert))
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return a, fmt.Errorf("cannot read bp2build allowlist file: %s", err)
+		}
+		var entries bp2buildAllowlistFile
+		decoder := json.NewDecoder(f)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&entries)
+		f.Close()
+		if err != nil {
+			return a, fmt.Errorf("%s: cannot parse bp2build allowlist file: %s", file, err)
+		}
+
+		defaultConfig := allowlists.Bp2BuildConfig{}
+		for dir, name := range entries.Default_config {
+			entry, ok := bp2buildConfigEntries[name]
+			if !ok {
+				return a, fmt.Errorf("%s: invalid default config %q for %q, expected one of %s",
+					file, name, dir, strings.Join(SortedKeys(bp2buildConfigEntries), ", "))
+			}
+			defaultConfig[dir] = entry
+		}
+		for _, m := range entries.Module_always_convert {
+			delete(ret.moduleDoNotConvert, m)
+		}
+		for _, m := range entries.Module_do_not_convert {
+			delete(ret.moduleAlwaysConvert, m)
+		}
+		ret = ret.SetDefaultConfig(defaultConfig).
+			SetKeepExistingBuildFile(entries.Keep_existing_build_file).
+			SetModuleAlwaysConvertList(entries.Module_always_convert).
+			SetModuleTypeAlwaysConvertList(entries.Module_type_always_convert).
+			SetModuleDoNotConvertList(entries.Module_do_not_convert)
+	}
+	return ret, nil
+}
+
 var bp2BuildAllowListKey = NewOnceKey("Bp2BuildAllowlist")
 var bp2buildAllowlist OncePer
 
@@ -678,6 +767,13 @@ func bp2buildConversionMutator(ctx BottomUpMutatorContext) {
 	}
 
 	bModule.ConvertWithBp2build(ctx)
+	if ctx.Module().base().GetUnconvertedReason() == nil {
+		convertRegisteredAttributes(ctx)
+	}
+
+	if dropped := droppedBp2buildProperties(ctx.Module().GetProperties(), ctx.Module().base().Bp2buildTargets()); len(dropped) > 0 {
+		ctx.AddBp2buildWarning("install-time only properties are not converted: %s", strings.Join(dropped, ", "))
+	}
 
 	installCtx := &baseModuleContextToModuleInstallPathContext{ctx}
 	ctx.Module().base().setPartitionForBp2build(modulePartition(installCtx, true))
@@ -691,6 +787,10 @@ func bp2buildConversionMutator(ctx BottomUpMutatorContext) {
 	// is an existing definition for this target. (These generated target names
 	// may be different than the module name, as checked at the beginning of this function!)
 	for _, targetInfo := range ctx.Module().base().Bp2buildTargets() {
+		if targetInfo.Optional {
+			// Optional targets are dropped instead, see CreateOptionalBazelTargetAlias.
+			continue
+		}
 		if ctx.Config().HasBazelBuildTargetInSource(targetInfo.TargetPackage(), targetInfo.TargetName()) {
 			// Defer to the BUILD target. Generating an additional target would
 			// cause a BUILD file conflict.
@@ -700,6 +800,53 @@ func bp2buildConversionMutator(ctx BottomUpMutatorContext) {
 	}
 }
 
+// droppedBp2buildProperties returns the sorted names of the properties from
+// allowlists.Bp2buildKnownDroppedProperties which are set in the given property structs,
+// except for those carried by an attribute of the same name of one of the given targets,
+// e.g. required for the cc converters.
+func droppedBp2buildProperties(properties []interface{}, targets []bp2buildInfo) []string {
+	known := make(map[string]bool, len(allowlists.Bp2buildKnownDroppedProperties))
+	for _, name := range allowlists.Bp2buildKnownDroppedProperties {
+		known[name] = true
+	}
+	for _, target := range targets {
+		visitExportedFields(target.Attrs, func(name string, _ reflect.Value) {
+			delete(known, proptools.PropertyNameForField(name))
+		})
+	}
+	var dropped []string
+	for _, p := range properties {
+		visitExportedFields(p, func(name string, field reflect.Value) {
+			if name := proptools.PropertyNameForField(name); known[name] && !field.IsZero() {
+				dropped = append(dropped, name)
+			}
+		})
+	}
+	return SortedUniqueStrings(dropped)
+}
+
+// visitExportedFields calls visit with the name and value of each exported field of the
+// struct pointed to by ptr, including the fields of its embedded structs.
+func visitExportedFields(ptr interface{}, visit func(name string, field reflect.Value)) {
+	var visitStruct func(v reflect.Value)
+	visitStruct = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if t.Field(i).Anonymous && field.Kind() == reflect.Struct {
+				visitStruct(field)
+				continue
+			}
+			if t.Field(i).IsExported() {
+				visit(t.Field(i).Name, field)
+			}
+		}
+	}
+	if v := reflect.ValueOf(ptr); v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+		visitStruct(v.Elem())
+	}
+}
+
 // TODO: b/285631638 - Add this as a new mutator to the bp2build conversion mutators.
 // Currently, this only exists to prepare test coverage for the launch of this feature.
 func bp2buildDepsMutator(ctx BottomUpMutatorContext) {
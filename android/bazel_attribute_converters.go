@@ -0,0 +1,128 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"android/soong/bazel"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// Bp2buildPropertyValues holds the values of a property of a module for each
+// configuration it is set for, keyed by configuration axis and then by config, e.g.
+// bazel.ArchConfigurationAxis and "arm64" for the value of an arch.arm64 block. The value
+// outside of any arch, multilib or target block is keyed by bazel.NoConfigAxis and "".
+//
+// The values are the property fields of the property struct of the module, e.g. a
+// *string or a []string.
+type Bp2buildPropertyValues map[bazel.ConfigurationAxis]map[string]interface{}
+
+// Bp2buildAttributeConverter converts the values of a property of a module to additional
+// attributes of a target generated for the module, whose rule class is given. It returns a
+// pointer to a struct of attributes, like the ones passed to CreateBazelTargetModule, e.g.
+// with a bazel.StringListAttribute set with SetSelectValue for each of the values, or nil to
+// add no attributes to the target.
+type Bp2buildAttributeConverter func(ruleClass string, values Bp2buildPropertyValues) interface{}
+
+// bp2buildAttributeConverters holds the attribute converters of a context, keyed by module
+// type and then by property name.
+type bp2buildAttributeConverters map[string]map[string]Bp2buildAttributeConverter
+
+// register adds the given converter, and panics if one is already registered for the
+// property of the module type.
+func (c bp2buildAttributeConverters) register(moduleType, propName string, converter Bp2buildAttributeConverter) {
+	if c[moduleType] == nil {
+		c[moduleType] = map[string]Bp2buildAttributeConverter{}
+	}
+	if _, exists := c[moduleType][propName]; exists {
+		panic(fmt.Errorf("bp2build attribute converter already registered for property %q of module type %q", propName, moduleType))
+	}
+	c[moduleType][propName] = converter
+}
+
+// The attribute converters registered with the InitRegistrationContext.
+var initBp2buildAttributeConverters = bp2buildAttributeConverters{}
+
+// convertRegisteredAttributes adds the attributes the registered attribute converters
+// convert the properties of the current module to, to each of its targets. Properties
+// which are not set in any configuration are not converted.
+func convertRegisteredAttributes(ctx BottomUpMutatorContext) {
+	converters := ctx.Config().bp2buildAttributeConverters[ctx.ModuleType()]
+	if len(converters) == 0 {
+		return
+	}
+	m := ctx.Module().base()
+	for _, propName := range SortedKeys(converters) {
+		values := bp2buildPropertyValues(ctx, propName)
+		if len(values) == 0 {
+			continue
+		}
+		for i := range m.commonProperties.BazelConversionStatus.Bp2buildInfo {
+			info := &m.commonProperties.BazelConversionStatus.Bp2buildInfo[i]
+			if attrs := converters[propName](info.BazelRuleClass(), values); attrs != nil {
+				info.ConvertedAttrs = append(info.ConvertedAttrs, attrs)
+			}
+		}
+	}
+}
+
+// bp2buildPropertyValues returns the values of the given, possibly nested, property of the
+// current module for each configuration it is set for.
+func bp2buildPropertyValues(ctx BottomUpMutatorContext, propName string) Bp2buildPropertyValues {
+	values := Bp2buildPropertyValues{}
+	for _, props := range ctx.Module().GetProperties() {
+		if _, ok := propertyFieldValue(reflect.ValueOf(props), propName); !ok {
+			continue
+		}
+		for axis, configToProps := range ctx.Module().base().GetArchVariantProperties(ctx, props) {
+			for config, configProps := range configToProps {
+				if value, ok := propertyFieldValue(reflect.ValueOf(configProps), propName); ok && !value.IsZero() {
+					if values[axis] == nil {
+						values[axis] = map[string]interface{}{}
+					}
+					values[axis][config] = value.Interface()
+				}
+			}
+		}
+		break
+	}
+	return values
+}
+
+// propertyFieldValue returns the field of the given property struct for the given, possibly
+// nested, property name.
+func propertyFieldValue(props reflect.Value, propName string) (reflect.Value, bool) {
+	value := props
+	for _, name := range strings.Split(propName, ".") {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		value = value.FieldByName(proptools.FieldNameForProperty(name))
+		if !value.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return value, true
+}
@@ -15,6 +15,9 @@ package android
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"android/soong/android/allowlists"
@@ -426,6 +429,107 @@ func TestBp2buildAllowList(t *testing.T) {
 	}
 }
 
+func TestBp2buildAllowlistFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeAllowlistFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first := writeAllowlistFile("first.json", `{
+		"Default_config": {"vendor/foo": "Bp2BuildDefaultTrueRecursively", "a": "Bp2BuildDefaultTrue"},
+		"Keep_existing_build_file": {"vendor/foo/bazel": true},
+		"Module_always_convert": ["libbaz"]
+	}`)
+	second := writeAllowlistFile("second.json", `{
+		"Default_config": {"vendor/foo/bar": "Bp2BuildDefaultFalse", "a": "Bp2BuildDefaultFalseRecursively"},
+		"Module_type_always_convert": ["foo_module_type"],
+		"Module_do_not_convert": ["libqux"]
+	}`)
+
+	builtin := NewBp2BuildAllowlist().
+		SetDefaultConfig(allowlists.Bp2BuildConfig{"a": allowlists.Bp2BuildDefaultTrueRecursively}).
+		SetModuleDoNotConvertList([]string{"libbuiltin"})
+	allowlist, err := builtin.WithAllowlistFiles([]string{first, second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	AssertDeepEquals(t, "default config", allowlists.Bp2BuildConfig{
+		"a":              allowlists.Bp2BuildDefaultFalseRecursively,
+		"vendor/foo":     allowlists.Bp2BuildDefaultTrueRecursively,
+		"vendor/foo/bar": allowlists.Bp2BuildDefaultFalse,
+	}, allowlist.defaultConfig)
+	AssertBoolEquals(t, "keep existing build file", true, allowlist.ShouldKeepExistingBuildFileForDir("vendor/foo/bazel"))
+	AssertDeepEquals(t, "module always convert", map[string]bool{"libbaz": true}, allowlist.moduleAlwaysConvert)
+	AssertDeepEquals(t, "module type always convert", map[string]bool{"foo_module_type": true}, allowlist.moduleTypeAlwaysConvert)
+	AssertDeepEquals(t, "module do not convert", map[string]bool{"libbuiltin": true, "libqux": true}, allowlist.moduleDoNotConvert)
+
+	// The built-in allowlist is left untouched.
+	AssertDeepEquals(t, "built-in default config", allowlists.Bp2BuildConfig{
+		"a": allowlists.Bp2BuildDefaultTrueRecursively,
+	}, builtin.defaultConfig)
+	AssertDeepEquals(t, "built-in module do not convert", map[string]bool{"libbuiltin": true}, builtin.moduleDoNotConvert)
+
+	// A module listed by a file overrides the conflicting entries of the built-in allowlist and
+	// of earlier files.
+	optIn := writeAllowlistFile("opt_in.json", `{"Module_always_convert": ["libbuiltin", "libqux"]}`)
+	optOut := writeAllowlistFile("opt_out.json", `{"Module_do_not_convert": ["libbaz"]}`)
+	allowlist, err = builtin.WithAllowlistFiles([]string{first, second, optIn, optOut})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, "overridden module always convert", map[string]bool{"libbuiltin": true, "libqux": true}, allowlist.moduleAlwaysConvert)
+	AssertDeepEquals(t, "overridden module do not convert", map[string]bool{"libbaz": true}, allowlist.moduleDoNotConvert)
+	AssertDeepEquals(t, "built-in module do not convert after override", map[string]bool{"libbuiltin": true}, builtin.moduleDoNotConvert)
+
+	invalid := writeAllowlistFile("invalid.json", `{"Default_config": {"vendor/foo": "DefaultTrue"}}`)
+	_, err = builtin.WithAllowlistFiles([]string{invalid})
+	if err == nil || !strings.Contains(err.Error(), `invalid default config "DefaultTrue" for "vendor/foo"`) {
+		t.Errorf("expected an invalid default config error, got %v", err)
+	}
+
+	unknownField := writeAllowlistFile("unknown.json", `{"Module_always_convert_list": ["libbaz"]}`)
+	_, err = builtin.WithAllowlistFiles([]string{unknownField})
+	if err == nil || !strings.Contains(err.Error(), "cannot parse bp2build allowlist file") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+}
+
+func TestDroppedBp2buildProperties(t *testing.T) {
+	type embeddedProps struct {
+		Vintf_fragments []string
+	}
+	type props struct {
+		embeddedProps
+		Init_rc  []string
+		Required []string
+		Srcs     []string
+	}
+	properties := []interface{}{
+		&props{
+			embeddedProps: embeddedProps{Vintf_fragments: []string{"manifest.xml"}},
+			Init_rc:       []string{"foo.rc"},
+			Required:      []string{"bar"},
+			Srcs:          []string{"foo.cpp"},
+		},
+	}
+	got := droppedBp2buildProperties(properties, nil)
+	AssertDeepEquals(t, "dropped properties", []string{"init_rc", "required", "vintf_fragments"}, got)
+
+	type attrs struct {
+		Srcs     bazel.LabelListAttribute
+		Required bazel.LabelListAttribute
+	}
+	got = droppedBp2buildProperties(properties, []bp2buildInfo{
+		{Attrs: &attrs{}},
+	})
+	AssertDeepEquals(t, "dropped properties of targets with a required attribute",
+		[]string{"init_rc", "vintf_fragments"}, got)
+}
+
 func TestShouldKeepExistingBuildFileForDir(t *testing.T) {
 	allowlist := NewBp2BuildAllowlist()
 	// entry "a/b2/c2" is moot because of its parent "a/b2"
@@ -102,6 +102,11 @@ type CmdArgs struct {
 	BuildFromTextStub bool
 
 	EnsureAllowlistIntegrity bool
+
+	Bp2buildCheckOnly      bool
+	Bp2buildRunValidations bool
+
+	Bp2buildCoverageFile string
 }
 
 // Build modes that soong_build can run as.
@@ -300,6 +305,9 @@ type config struct {
 	Bp2buildPackageConfig          Bp2BuildConversionAllowlist
 	Bp2buildSoongConfigDefinitions soongconfig.Bp2BuildSoongConfigDefinitions
 
+	// The attribute converters registered with RegisterBp2buildAttributeConverter.
+	bp2buildAttributeConverters bp2buildAttributeConverters
+
 	// If MultitreeBuild is true then this is one inner tree of a multitree
 	// build directed by the multitree orchestrator.
 	MultitreeBuild bool
@@ -669,8 +677,11 @@ func NewConfig(cmdArgs CmdArgs, availableEnv map[string]string) (Config, error)
 	for _, module := range getForceEnabledModulesFromFlag(cmdArgs.BazelForceEnabledModules) {
 		config.bazelForceEnabledModules[module] = struct{}{}
 	}
+	config.Bp2buildPackageConfig, err = GetBp2BuildAllowList().WithAllowlistFiles(config.Bp2buildAllowlistFiles())
+	if err != nil {
+		return Config{}, err
+	}
 	config.BazelContext, err = NewBazelContext(config)
-	config.Bp2buildPackageConfig = GetBp2BuildAllowList()
 
 	// TODO(b/276958307): Replace the hardcoded list to a sdk_library local prop.
 	config.apiLibraries = map[string]struct{}{
@@ -878,6 +889,30 @@ func (c *config) GetenvWithDefault(key string, defaultValue string) string {
 	return ret
 }
 
+// Bp2buildAllowlistFiles returns the JSON files, listed comma-separated in BP2BUILD_ALLOWLIST_FILES,
+// whose entries are added to the built-in bp2build allowlists.
+func (c *config) Bp2buildAllowlistFiles() []string {
+	files := c.Getenv("BP2BUILD_ALLOWLIST_FILES")
+	if files == "" {
+		return nil
+	}
+	return strings.Split(files, ",")
+}
+
+// Bp2buildStubsForAnyApex returns true if bp2build should select the dynamic_deps of apexes that
+// are not listed in apex_available (//apex_available:anyapex) in the default condition of the
+// stub selections, instead of the implementation libraries.
+func (c *config) Bp2buildStubsForAnyApex() bool {
+	return c.IsEnvTrue("BP2BUILD_STUBS_FOR_ANY_APEX")
+}
+
+// Bp2buildStaticLibraryWrapLdflags returns true if bp2build should pass the --wrap ldflags of
+// static libraries to the linking of their dependents. Soong ignores the ldflags of static
+// libraries, so this is opt-in.
+func (c *config) Bp2buildStaticLibraryWrapLdflags() bool {
+	return c.IsEnvTrue("BP2BUILD_STATIC_LIBRARY_WRAP_LDFLAGS")
+}
+
 func (c *config) IsEnvTrue(key string) bool {
 	value := c.Getenv(key)
 	return value == "1" || value == "y" || value == "yes" || value == "on" || value == "true"
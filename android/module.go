@@ -572,6 +572,7 @@ type Module interface {
 	Bp2buildTargets() []bp2buildInfo
 	GetUnconvertedBp2buildDeps() []string
 	GetMissingBp2buildDeps() []string
+	GetBp2buildWarnings() []string
 	GetPartitionForBp2build() string
 
 	BuildParamsForTests() []BuildParams
@@ -1281,11 +1282,36 @@ func (attrs *CommonAttributes) getRequiredWithoutCycles(ctx *bottomUpMutatorCont
 	if proptools.Bool(attrs.SkipData) {
 		return []string{}
 	}
+	return requiredWithoutCycles(ctx.ModuleName(), props.Required)
+}
+
+// requiredWithoutCycles returns the unique modules of the given required
+// property, without the module itself.
+func requiredWithoutCycles(moduleName string, required []string) []string {
 	// The required property can contain the module itself. This causes a cycle
 	// when generated as the 'data' label list attribute in Bazel. Remove it if
 	// it exists. See b/247985196.
-	_, requiredWithoutCycles := RemoveFromList(ctx.ModuleName(), props.Required)
-	return FirstUniqueStrings(requiredWithoutCycles)
+	_, withoutCycles := RemoveFromList(moduleName, required)
+	return FirstUniqueStrings(withoutCycles)
+}
+
+// Bp2buildRequiredLabels returns the labels of the modules in the required
+// property of the module being converted, selected by configuration. It is used
+// by the converters which skip the data attribute, e.g. the cc library and
+// binary ones, to convert the required property to a dedicated attribute instead.
+func Bp2buildRequiredLabels(ctx Bp2buildMutatorContext) bazel.LabelListAttribute {
+	mod := ctx.Module().base()
+	required := bazel.MakeLabelListAttribute(
+		BazelLabelForModuleDeps(ctx, requiredWithoutCycles(ctx.ModuleName(), mod.commonProperties.Required)))
+	for axis, configToProps := range mod.GetArchVariantProperties(ctx, &commonProperties{}) {
+		for config, _props := range configToProps {
+			if archProps, ok := _props.(*commonProperties); ok {
+				required.SetSelectValue(axis, config,
+					BazelLabelForModuleDeps(ctx, requiredWithoutCycles(ctx.ModuleName(), archProps.Required)))
+			}
+		}
+	}
+	return required
 }
 
 func (attrs *CommonAttributes) fillCommonBp2BuildModuleAttrs(ctx *bottomUpMutatorContext,
@@ -1356,6 +1382,12 @@ func (attrs *CommonAttributes) fillCommonBp2BuildModuleAttrs(ctx *bottomUpMutato
 
 	attrs.Applicable_licenses = bazel.MakeLabelListAttribute(BazelLabelForModuleDeps(ctx, mod.commonProperties.Licenses))
 
+	if b, ok := ctx.Module().(Bazelable); ok && proptools.Bool(b.bazelProps().Bazel_module.Manual) {
+		if !InList(manualTag, attrs.Tags.Value) {
+			attrs.Tags.Append(bazel.MakeStringListAttribute([]string{manualTag}))
+		}
+	}
+
 	requiredWithoutCycles := attrs.getRequiredWithoutCycles(ctx, &mod.commonProperties)
 	required := depsToLabelList(requiredWithoutCycles)
 	archVariantProps := mod.GetArchVariantProperties(ctx, &commonProperties{})
@@ -1429,6 +1461,7 @@ func (attrs *CommonAttributes) fillCommonBp2BuildModuleAttrs(ctx *bottomUpMutato
 	moduleEnableConstraints.Append(platformEnabledAttribute)
 	moduleEnableConstraints.Append(productConfigEnabledAttribute)
 	addCompatibilityConstraintForCompileMultilib(ctx, &moduleEnableConstraints)
+	addCompatibilityConstraintForRecovery(ctx, &moduleEnableConstraints)
 
 	return constraintAttributes{Target_compatible_with: moduleEnableConstraints}
 }
@@ -1437,6 +1470,11 @@ var (
 	incompatible = bazel.LabelList{[]bazel.Label{{Label: "@platforms//:incompatible"}}, nil}
 )
 
+const (
+	// manualTag excludes a Bazel target from wildcard target patterns.
+	manualTag = "manual"
+)
+
 // If compile_mulitilib is set to
 // 1. 32: Add an incompatibility constraint for non-32 arches
 // 1. 64: Add an incompatibility constraint for non-64 arches
@@ -1447,13 +1485,14 @@ func addCompatibilityConstraintForCompileMultilib(ctx *bottomUpMutatorContext, e
 	switch multilib {
 	case "32":
 		// Add an incompatibility constraint for all known 64-bit arches
-		enabled.SetSelectValue(bazel.ArchConfigurationAxis, "arm64", incompatible)
-		enabled.SetSelectValue(bazel.ArchConfigurationAxis, "x86_64", incompatible)
-		enabled.SetSelectValue(bazel.ArchConfigurationAxis, "riscv64", incompatible)
+		for _, arch := range bazel.Archs64Bit {
+			enabled.SetSelectValue(bazel.ArchConfigurationAxis, arch, incompatible)
+		}
 	case "64":
 		// Add an incompatibility constraint for all known 32-bit arches
-		enabled.SetSelectValue(bazel.ArchConfigurationAxis, "arm", incompatible)
-		enabled.SetSelectValue(bazel.ArchConfigurationAxis, "x86", incompatible)
+		for _, arch := range bazel.Archs32Bit {
+			enabled.SetSelectValue(bazel.ArchConfigurationAxis, arch, incompatible)
+		}
 	case "both":
 		// Do nothing: "both" is trivially compatible with 32-bit and 64-bit
 		// The top level rule (e.g. apex/partition) will be responsible for building this module in both variants via an
@@ -1464,6 +1503,16 @@ func addCompatibilityConstraintForCompileMultilib(ctx *bottomUpMutatorContext, e
 
 }
 
+// If recovery is set, the module only has a recovery variant, so add an
+// incompatibility constraint for the other images.
+func addCompatibilityConstraintForRecovery(ctx *bottomUpMutatorContext, enabled *bazel.LabelListAttribute) {
+	if !proptools.Bool(ctx.Module().base().commonProperties.Recovery) {
+		return
+	}
+	enabled.SetSelectValue(bazel.ImageAxis, bazel.ConditionsDefaultConfigKey, incompatible)
+	enabled.SetSelectValue(bazel.ImageAxis, bazel.ImageRecovery, bazel.LabelList{Includes: []bazel.Label{}})
+}
+
 // Check product variables for `enabled: true` flag override.
 // Returns a list of the constraint_value targets who enable this override.
 func productVariableConfigEnableAttribute(ctx *bottomUpMutatorContext) bazel.LabelListAttribute {
@@ -1620,6 +1669,12 @@ type bp2buildInfo struct {
 	CommonAttrs     CommonAttributes
 	ConstraintAttrs constraintAttributes
 	Attrs           interface{}
+	// Optional targets are only generated if no other target of their package has
+	// their name, see CreateOptionalBazelTargetAlias.
+	Optional bool
+	// Attributes converted by the converters registered with
+	// RegisterBp2buildAttributeConverter.
+	ConvertedAttrs []interface{}
 }
 
 // TargetName returns the Bazel target name of a bp2build converted target.
@@ -1645,7 +1700,7 @@ func (b bp2buildInfo) BazelRuleLoadLocation() string {
 
 // BazelAttributes returns the Bazel attributes of a bp2build converted target.
 func (b bp2buildInfo) BazelAttributes() []interface{} {
-	return []interface{}{&b.CommonAttrs, &b.ConstraintAttrs, b.Attrs}
+	return append([]interface{}{&b.CommonAttrs, &b.ConstraintAttrs, b.Attrs}, b.ConvertedAttrs...)
 }
 
 func (m *ModuleBase) addBp2buildInfo(info bp2buildInfo) {
@@ -1689,6 +1744,13 @@ func (b *baseModuleContext) AddMissingBp2buildDep(dep string) {
 	*missingDeps = append(*missingDeps, dep)
 }
 
+// AddBp2buildWarning stores a message about a part of this module that bp2build dropped or
+// altered while converting it.
+func (b *baseModuleContext) AddBp2buildWarning(format string, args ...interface{}) {
+	warnings := &b.Module().base().commonProperties.BazelConversionStatus.Warnings
+	*warnings = append(*warnings, fmt.Sprintf(format, args...))
+}
+
 // GetUnconvertedBp2buildDeps returns the list of module names of this module's direct dependencies that
 // were not converted to Bazel.
 func (m *ModuleBase) GetUnconvertedBp2buildDeps() []string {
@@ -1700,6 +1762,12 @@ func (m *ModuleBase) GetMissingBp2buildDeps() []string {
 	return FirstUniqueStrings(m.commonProperties.BazelConversionStatus.MissingDeps)
 }
 
+// GetBp2buildWarnings returns the messages about parts of this module that bp2build dropped or
+// altered while converting it.
+func (m *ModuleBase) GetBp2buildWarnings() []string {
+	return FirstUniqueStrings(m.commonProperties.BazelConversionStatus.Warnings)
+}
+
 func (m *ModuleBase) AddJSONData(d *map[string]interface{}) {
 	(*d)["Android"] = map[string]interface{}{
 		// Properties set in Blueprint or in blueprint of a defaults modules
@@ -253,11 +253,20 @@ type Bp2buildMutatorContext interface {
 	// given reason.
 	MarkBp2buildUnconvertible(reasonType bp2build_metrics_proto.UnconvertedReasonType, detail string)
 
+	// AddBp2buildWarning registers a message about a part of the current module that was dropped
+	// or altered during conversion. Warnings are collected into the bp2build metrics report.
+	AddBp2buildWarning(format string, args ...interface{})
+
 	// CreateBazelTargetAliasInDir creates an alias definition in `dir` directory.
 	// This function can be used to create alias definitions in a directory that is different
 	// from the directory of the visited Soong module.
 	CreateBazelTargetAliasInDir(dir string, name string, actual bazel.Label)
 
+	// CreateOptionalBazelTargetAlias creates an alias definition in the directory of the
+	// visited Soong module, unless any other target generated in or existing in that directory
+	// has the same name, including an optional alias of another module.
+	CreateOptionalBazelTargetAlias(name string, actual bazel.Label)
+
 	// CreateBazelConfigSetting creates a config_setting in <dir>/BUILD.bazel
 	// build/bazel has several static config_setting(s) that are used in Bazel builds.
 	// This function can be used to createa additional config_setting(s) based on the build graph
@@ -747,6 +756,22 @@ func (t *bottomUpMutatorContext) CreateBazelTargetAliasInDir(
 	mod.base().addBp2buildInfo(info)
 }
 
+func (t *bottomUpMutatorContext) CreateOptionalBazelTargetAlias(name string, actual bazel.Label) {
+	mod := t.Module()
+	attrs := &bazelAliasAttributes{
+		Actual: bazel.MakeLabelAttribute(actual.Label),
+	}
+	info := bp2buildInfo{
+		Dir:             t.OtherModuleDir(mod),
+		BazelProps:      bazelAliasModuleProperties,
+		CommonAttrs:     CommonAttributes{Name: name},
+		ConstraintAttrs: constraintAttributes{},
+		Attrs:           attrs,
+		Optional:        true,
+	}
+	mod.base().addBp2buildInfo(info)
+}
+
 // Returns the directory in which the bazel target will be generated
 // If ca.Dir is not nil, use that
 // Otherwise default to the directory of the soong module
@@ -840,6 +865,9 @@ func ConvertApexAvailableToTags(apexAvailable []string) []string {
 		// instead of adding it with an empty list
 		return nil
 	}
+	// apex_available may be set by both the module and its defaults, so deduplicate and sort
+	// the tags to keep the generated BUILD files stable.
+	apexAvailable = SortedUniqueStrings(apexAvailable)
 	result := make([]string, 0, len(apexAvailable))
 	for _, a := range apexAvailable {
 		result = append(result, "apex_available="+a)
@@ -273,11 +273,12 @@ func TestConvertApexAvailableToTags(t *testing.T) {
 	input := []string{
 		"com.android.adbd",
 		"//apex_available:platform",
+		"com.android.adbd",
 	}
 	actual := ConvertApexAvailableToTags(input)
 	expected := []string{
-		"apex_available=com.android.adbd",
 		"apex_available=//apex_available:platform",
+		"apex_available=com.android.adbd",
 	}
 	if !reflect.DeepEqual(actual, expected) {
 		t.Errorf("Expected: %v, actual: %v", expected, actual)
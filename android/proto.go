@@ -352,59 +352,64 @@ var (
 	protoIncludeDirsBp2buildKey    = NewOnceKey("protoIncludeDirsBp2build")
 )
 
-func getProtoIncludeDirsBp2build(config Config) *sync.Map {
-	return config.Once(protoIncludeDirsBp2buildKey, func() interface{} {
-		return &sync.Map{}
-	}).(*sync.Map)
-}
-
-// key for dynamically creating proto_library per proto.include_dirs
-type protoIncludeDirKey struct {
-	dir            string
-	subpackgeInDir string
+// includeDirLibraries describes the library targets created for the source files of the
+// include_dirs property of a language, e.g. proto.include_dirs.
+type includeDirLibraries struct {
+	// property is the name of the include_dirs property, for error messages.
+	property string
+	// pattern matches the source files of an include dir, e.g. "**/*.proto".
+	pattern string
+	// suffix is appended to the name of the targets, derived from their include dir.
+	suffix string
+	// onceKey keys the targets already created, since several modules may list the
+	// same include dir.
+	onceKey OnceKey
+	// subpackages is whether the source files of an include dir may be in its
+	// subpackages, in which case a target is created in each of these.
+	subpackages bool
+	props       bazel.BazelTargetModuleProperties
+	// attrs returns the attributes of the target for the source files of the
+	// include dir in the given package.
+	attrs func(dir, pkg string, srcs bazel.LabelList) interface{}
 }
 
-// createProtoLibraryTargetsForIncludeDirs creates additional proto_library targets for .proto files in includeDirs
-// Since Bazel imposes a constratint that the proto_library must be in the same package as the .proto file, this function
-// might create the targets in a subdirectory of `includeDir`
-// Returns the labels of the proto_library targets
-func createProtoLibraryTargetsForIncludeDirs(ctx Bp2buildMutatorContext, includeDirs []string) bazel.LabelList {
+// createLibraryTargetsForIncludeDirs creates a library target for the source files of each
+// of includeDirs, unless another module created it already, and returns their labels.
+// Since Bazel imposes a constraint that a library must be in the same package as its source
+// files, the targets are created in the package of each source file, which is an error
+// unless lib supports subpackages.
+func createLibraryTargetsForIncludeDirs(ctx Bp2buildMutatorContext, lib includeDirLibraries, includeDirs []string) bazel.LabelList {
 	var ret bazel.LabelList
+	created := ctx.Config().Once(lib.onceKey, func() interface{} {
+		return &sync.Map{}
+	}).(*sync.Map)
 	for _, dir := range includeDirs {
 		if exists, _, _ := ctx.Config().fs.Exists(filepath.Join(dir, "Android.bp")); !exists {
-			ctx.ModuleErrorf("TODO: Add support for proto.include_dir: %v. This directory does not contain an Android.bp file", dir)
+			ctx.ModuleErrorf("TODO: Add support for %s: %v. This directory does not contain an Android.bp file", lib.property, dir)
+			return bazel.LabelList{}
 		}
-		dirMap := getProtoIncludeDirsBp2build(ctx.Config())
-		// Find all proto file targets in this dir
-		protoLabelsInDir := BazelLabelForSrcPatternExcludes(ctx, dir, "**/*.proto", []string{})
-		// Partition the labels by package and subpackage(s)
-		protoLabelelsPartitionedByPkg := partitionSrcsByPackage(dir, protoLabelsInDir)
-		for _, pkg := range SortedStringKeys(protoLabelelsPartitionedByPkg) {
-			label := strings.ReplaceAll(dir, "/", ".") + protoIncludeDirGeneratedSuffix
+		// Find all source file targets in this dir, and partition them by package and subpackage(s)
+		srcsByPkg := partitionSrcsByPackage(dir, BazelLabelForSrcPatternExcludes(ctx, dir, lib.pattern, []string{}))
+		pkgs := SortedStringKeys(srcsByPkg)
+		for _, pkg := range pkgs {
+			if pkg != dir && !lib.subpackages {
+				ctx.ModuleErrorf("TODO: Add support for %s: %v. Its files in %v are in a subpackage", lib.property, dir, pkg)
+				return bazel.LabelList{}
+			}
+		}
+		for _, pkg := range pkgs {
+			name := strings.ReplaceAll(dir, "/", ".") + lib.suffix
+			label := "//" + pkg + ":" + name
 			ret.Add(&bazel.Label{
-				Label: "//" + pkg + ":" + label,
+				Label: label,
 			})
-			key := protoIncludeDirKey{dir: dir, subpackgeInDir: pkg}
-			if _, exists := dirMap.LoadOrStore(key, true); exists {
-				// A proto_library has already been created for this package relative to this include dir
+			if _, exists := created.LoadOrStore(label, true); exists {
+				// A target has already been created for this package relative to this include dir
 				continue
 			}
-			srcs := protoLabelelsPartitionedByPkg[pkg]
-			rel, err := filepath.Rel(dir, pkg)
-			if err != nil {
-				ctx.ModuleErrorf("Could not create a proto_library in pkg %v due to %v\n", pkg, err)
-			}
-			// Create proto_library
-			attrs := ProtoAttrs{
-				Srcs:                bazel.MakeLabelListAttribute(srcs),
-				Strip_import_prefix: proptools.StringPtr(""),
-			}
-			if rel != "." {
-				attrs.Import_prefix = proptools.StringPtr(rel)
-			}
 
-			// If a specific directory is listed in proto.include_dirs of two separate modules (one host-specific and another device-specific),
-			// we do not want to create the proto_library with target_compatible_with of the first visited of these two modules
+			// If a specific directory is listed in the include_dirs of two separate modules (one host-specific and another device-specific),
+			// we do not want to create the target with target_compatible_with of the first visited of these two modules
 			// As a workarounds, delete `target_compatible_with`
 			alwaysEnabled := bazel.BoolAttribute{}
 			alwaysEnabled.Value = proptools.BoolPtr(true)
@@ -414,18 +419,47 @@ func createProtoLibraryTargetsForIncludeDirs(ctx Bp2buildMutatorContext, include
 			alwaysEnabled.SetSelectValue(bazel.OsConfigurationAxis, bazel.OsLinux, proptools.BoolPtr(true))
 
 			ctx.CreateBazelTargetModuleWithRestrictions(
-				bazel.BazelTargetModuleProperties{Rule_class: "proto_library"},
+				lib.props,
 				CommonAttributes{
-					Name: label,
+					Name: name,
 					Dir:  proptools.StringPtr(pkg),
-					// This proto_library is used to construct a ProtoInfo
-					// But it might not be buildable on its own
+					// This library is used to construct the info provider of the libraries of
+					// the modules listing the include dir, but it might not be buildable on its own
 					Tags: bazel.MakeStringListAttribute([]string{"manual"}),
 				},
-				&attrs,
+				lib.attrs(dir, pkg, srcsByPkg[pkg]),
 				alwaysEnabled,
 			)
 		}
 	}
 	return ret
 }
+
+// createProtoLibraryTargetsForIncludeDirs creates additional proto_library targets for .proto files in includeDirs
+// Since Bazel imposes a constratint that the proto_library must be in the same package as the .proto file, this function
+// might create the targets in a subdirectory of `includeDir`
+// Returns the labels of the proto_library targets
+func createProtoLibraryTargetsForIncludeDirs(ctx Bp2buildMutatorContext, includeDirs []string) bazel.LabelList {
+	return createLibraryTargetsForIncludeDirs(ctx, includeDirLibraries{
+		property:    "proto.include_dir",
+		pattern:     "**/*.proto",
+		suffix:      protoIncludeDirGeneratedSuffix,
+		onceKey:     protoIncludeDirsBp2buildKey,
+		subpackages: true,
+		props:       bazel.BazelTargetModuleProperties{Rule_class: "proto_library"},
+		attrs: func(dir, pkg string, srcs bazel.LabelList) interface{} {
+			attrs := &ProtoAttrs{
+				Srcs:                bazel.MakeLabelListAttribute(srcs),
+				Strip_import_prefix: proptools.StringPtr(""),
+			}
+			rel, err := filepath.Rel(dir, pkg)
+			if err != nil {
+				ctx.ModuleErrorf("Could not create a proto_library in pkg %v due to %v\n", pkg, err)
+			}
+			if rel != "." {
+				attrs.Import_prefix = proptools.StringPtr(rel)
+			}
+			return attrs
+		},
+	}, includeDirs)
+}
@@ -182,6 +182,7 @@ func registerModuleTypes(ctx *Context) {
 // files to semantically equivalent BUILD files.
 func (ctx *Context) RegisterForBazelConversion() {
 	registerModuleTypes(ctx)
+	ctx.config.bp2buildAttributeConverters = initBp2buildAttributeConverters
 	RegisterMutatorsForBazelConversion(ctx, bp2buildPreArchMutators)
 }
 
@@ -312,6 +313,16 @@ type RegistrationContext interface {
 	PreDepsMutators(f RegisterMutatorFunc)
 	PostDepsMutators(f RegisterMutatorFunc)
 	FinalDepsMutators(f RegisterMutatorFunc)
+
+	// RegisterBp2buildAttributeConverter registers a converter of the given property of the
+	// modules of the given type to attributes of the targets bp2build generates for them. It
+	// allows property structs which are not known to the converter of the module type, e.g.
+	// those added by out-of-tree module types or load hooks, to contribute attributes to the
+	// generated targets. Nested properties are named with dots, e.g. "target.vendor.foo".
+	//
+	// The converter is given the values of the property in each configuration, see
+	// Bp2buildPropertyValues.
+	RegisterBp2buildAttributeConverter(moduleType, propName string, converter Bp2buildAttributeConverter)
 }
 
 // Used to register build components from an init() method, e.g.
@@ -405,3 +416,7 @@ func (ctx *initRegistrationContext) PostDepsMutators(f RegisterMutatorFunc) {
 func (ctx *initRegistrationContext) FinalDepsMutators(f RegisterMutatorFunc) {
 	FinalDepsMutators(f)
 }
+
+func (ctx *initRegistrationContext) RegisterBp2buildAttributeConverter(moduleType, propName string, converter Bp2buildAttributeConverter) {
+	initBp2buildAttributeConverters.register(moduleType, propName, converter)
+}
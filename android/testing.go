@@ -219,6 +219,13 @@ func (ctx *TestContext) FinalDepsMutators(f RegisterMutatorFunc) {
 	ctx.finalDeps = append(ctx.finalDeps, f)
 }
 
+func (ctx *TestContext) RegisterBp2buildAttributeConverter(moduleType, propName string, converter Bp2buildAttributeConverter) {
+	if ctx.config.bp2buildAttributeConverters == nil {
+		ctx.config.bp2buildAttributeConverters = bp2buildAttributeConverters{}
+	}
+	ctx.config.bp2buildAttributeConverters.register(moduleType, propName, converter)
+}
+
 func (ctx *TestContext) RegisterBp2BuildConfig(config Bp2BuildConversionAllowlist) {
 	ctx.config.Bp2buildPackageConfig = config
 }
@@ -679,6 +679,9 @@ type SoongConfigProperty struct {
 	// If there is a target: field inside a soong config property struct, the os that it selects
 	// on will be represented here.
 	os string
+	// boolVariable is true for soong config bool variables, whose select keys refer to the
+	// config_settings bp2build generates for them.
+	boolVariable bool
 }
 
 func (p SoongConfigProperty) Name() string {
@@ -690,6 +693,9 @@ func (p SoongConfigProperty) AlwaysEmit() bool {
 }
 
 func (p SoongConfigProperty) ConfigurationAxis() bazel.ConfigurationAxis {
+	if p.boolVariable {
+		return bazel.SoongConfigBoolVariableConfigurationAxis(p.namespace + "__" + p.name + "__" + p.os)
+	}
 	return bazel.ProductVariableConfigurationAxis(false, p.namespace+"__"+p.name+"__"+p.os)
 }
 
@@ -733,9 +739,16 @@ func (p SoongConfigProperty) SelectKey() string {
 // property, like ["-DDEFINES"] for cflags.
 type ProductConfigProperties map[string]map[ProductConfigOrSoongConfigProperty]interface{}
 
+// ProductVariablePropertiesContext is the context needed to collect the product variable
+// properties of a module.
+type ProductVariablePropertiesContext interface {
+	ArchVariantContext
+	Config() Config
+}
+
 // ProductVariableProperties returns a ProductConfigProperties containing only the properties which
 // have been set for the given module.
-func ProductVariableProperties(ctx ArchVariantContext, module Module) (ProductConfigProperties, []error) {
+func ProductVariableProperties(ctx ProductVariablePropertiesContext, module Module) (ProductConfigProperties, []error) {
 	var errs []error
 	moduleBase := module.base()
 
@@ -766,11 +779,37 @@ func ProductVariableProperties(ctx ArchVariantContext, module Module) (ProductCo
 				}
 			}
 		}
+		productConfigProperties.markSoongConfigBoolVariables(ctx.Config().Bp2buildSoongConfigDefinitions.BoolVars)
 	}
 
 	return productConfigProperties, errs
 }
 
+// markSoongConfigBoolVariables marks the properties of the given soong config bool variables,
+// keyed by <namespace>__<variable>, as bool variables.
+func (p ProductConfigProperties) markSoongConfigBoolVariables(boolVars map[string]bool) {
+	if len(boolVars) == 0 {
+		return
+	}
+	// The names of the variables of the property structs are capitalized.
+	lowerBoolVars := make(map[string]bool, len(boolVars))
+	for key := range boolVars {
+		lowerBoolVars[strings.ToLower(key)] = true
+	}
+	for propertyName, values := range p {
+		marked := make(map[ProductConfigOrSoongConfigProperty]interface{}, len(values))
+		for key, value := range values {
+			if soongConfigProp, ok := key.(SoongConfigProperty); ok &&
+				lowerBoolVars[strings.ToLower(soongConfigProp.namespace+"__"+soongConfigProp.name)] {
+				soongConfigProp.boolVariable = true
+				key = soongConfigProp
+			}
+			marked[key] = value
+		}
+		p[propertyName] = marked
+	}
+}
+
 func (p *ProductConfigProperties) AddProductConfigProperty(
 	propertyName, productVariableName, arch string, propertyValue interface{}) {
 
@@ -3361,6 +3361,13 @@ func convertWithBp2build(a *apexBundle, ctx android.Bp2buildMutatorContext) (baz
 	convert64Libs(ctx, compileMultilib, a.properties.Multilib.Lib64.Native_shared_libs, nativeSharedLibs)
 	convertFirstLibs(ctx, compileMultilib, a.properties.Multilib.First.Native_shared_libs, nativeSharedLibs)
 
+	// Soong also installs the runtime_libs of the native libraries and binaries, with
+	// the bitness of the library or binary depending on them.
+	binaryArchs := &convertedNativeSharedLibs{}
+	convertFirstLibs(ctx, compileMultilib, a.properties.ApexNativeDependencies.Binaries, binaryArchs)
+	addApexRuntimeLibs(ctx, bazel.Archs32Bit, &nativeSharedLibs.Native_shared_libs_32, binaryArchs.Native_shared_libs_32)
+	addApexRuntimeLibs(ctx, bazel.Archs64Bit, &nativeSharedLibs.Native_shared_libs_64, binaryArchs.Native_shared_libs_64)
+
 	prebuilts := a.overridableProperties.Prebuilts
 	prebuiltsLabelList := android.BazelLabelForModuleDeps(ctx, prebuilts)
 	prebuiltsLabelListAttribute := bazel.MakeLabelListAttribute(prebuiltsLabelList)
@@ -3521,13 +3528,15 @@ func makeNoConfig32SharedLibsAttributes(libsLabelList bazel.LabelList, nativeSha
 }
 
 func make32SharedLibsAttributes(libsLabelList bazel.LabelList, nativeSharedLibs *convertedNativeSharedLibs) {
-	makeSharedLibsAttributes("x86", libsLabelList, &nativeSharedLibs.Native_shared_libs_32)
-	makeSharedLibsAttributes("arm", libsLabelList, &nativeSharedLibs.Native_shared_libs_32)
+	for _, arch := range bazel.Archs32Bit {
+		makeSharedLibsAttributes(arch, libsLabelList, &nativeSharedLibs.Native_shared_libs_32)
+	}
 }
 
 func make64SharedLibsAttributes(libsLabelList bazel.LabelList, nativeSharedLibs *convertedNativeSharedLibs) {
-	makeSharedLibsAttributes("x86_64", libsLabelList, &nativeSharedLibs.Native_shared_libs_64)
-	makeSharedLibsAttributes("arm64", libsLabelList, &nativeSharedLibs.Native_shared_libs_64)
+	for _, arch := range bazel.Archs64Bit {
+		makeSharedLibsAttributes(arch, libsLabelList, &nativeSharedLibs.Native_shared_libs_64)
+	}
 }
 
 func makeSharedLibsAttributes(config string, libsLabelList bazel.LabelList,
@@ -3537,6 +3546,34 @@ func makeSharedLibsAttributes(config string, libsLabelList bazel.LabelList,
 	labelListAttr.Append(list)
 }
 
+// addApexRuntimeLibs appends to libs, for each of the given archs, the runtime_libs of
+// the libraries and binaries installed for that arch that libs doesn't already list for it.
+func addApexRuntimeLibs(ctx android.Bp2buildMutatorContext, archs []string,
+	libs *bazel.LabelListAttribute, binaries bazel.LabelListAttribute) {
+	runtimeLibs := bazel.LabelListAttribute{}
+	for _, arch := range archs {
+		modules := append(apexModulesForArch(*libs, arch), apexModulesForArch(binaries, arch)...)
+		if archRuntimeLibs := cc.ApexRuntimeLibs(ctx, modules, arch); len(archRuntimeLibs) > 0 {
+			runtimeLibs.SetSelectValue(bazel.ArchConfigurationAxis, arch, android.BazelLabelForModuleDeps(ctx, archRuntimeLibs))
+		}
+	}
+	libs.Append(runtimeLibs)
+}
+
+// apexModulesForArch returns the names of the modules attr lists for the given arch.
+func apexModulesForArch(attr bazel.LabelListAttribute, arch string) []string {
+	var modules []string
+	for _, labels := range []bazel.LabelList{
+		attr.SelectValue(bazel.NoConfigAxis, ""),
+		attr.SelectValue(bazel.ArchConfigurationAxis, arch),
+	} {
+		for _, label := range labels.Includes {
+			modules = append(modules, label.OriginalModuleName)
+		}
+	}
+	return modules
+}
+
 func invalidCompileMultilib(ctx android.Bp2buildMutatorContext, value string) {
 	ctx.PropertyErrorf("compile_multilib", "Invalid value: %s", value)
 }
@@ -69,6 +69,11 @@ const (
 
 	productVariableBazelPackage = "//build/bazel/product_config/config_settings"
 
+	// SoongConfigBoolSettingsDir is the package of soong_injection holding the
+	// config_settings bp2build generates for the select() keys of Soong config
+	// bool variables.
+	SoongConfigBoolSettingsDir = "soong_config_settings"
+
 	AndroidAndInApex = "android-in_apex"
 	AndroidPlatform  = "system"
 	Unbundled_app    = "unbundled_app"
@@ -77,6 +82,14 @@ const (
 	NonApex = "non_apex"
 
 	ErrorproneDisabled = "errorprone_disabled"
+
+	// Image names of the vendor, product and recovery variants of a module.
+	ImageVendor   = "vendor"
+	ImageProduct  = "product"
+	ImageRecovery = "recovery"
+
+	// The config of the variants of a module translated with native bridge, e.g. arm on x86.
+	NativeBridge = "native_bridge"
 	// TODO: b/294868620 - Remove when completing the bug
 	SanitizersEnabled = "sanitizers_enabled"
 )
@@ -148,6 +161,15 @@ func createPlatformArchMap() map[string]string {
 }
 
 var (
+	// Archs32Bit and Archs64Bit are the architectures with a Bazel config_setting, split by
+	// bitness. Converters that need to enumerate architectures should use these lists rather
+	// than hardcoding architectures, so that new ones (e.g. riscv64) are not silently dropped.
+	Archs32Bit = []string{archArm, archX86}
+	Archs64Bit = []string{archArm64, archRiscv64, archX86_64}
+
+	// HostOses are the host operating systems with a Bazel config_setting.
+	HostOses = []string{OsDarwin, OsLinux, osLinuxMusl, osLinuxBionic, OsWindows}
+
 	// These are the list of OSes and architectures with a Bazel config_setting
 	// and constraint value equivalent. These exist in arch.go, but the android
 	// package depends on the bazel package, so a cyclic dependency prevents
@@ -223,6 +245,18 @@ var (
 		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey,
 	}
 
+	imageMap = map[string]string{
+		ImageVendor:                "@soong_injection//image:vendor",
+		ImageProduct:               "@soong_injection//image:product",
+		ImageRecovery:              "@soong_injection//image:recovery",
+		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey,
+	}
+
+	nativeBridgeMap = map[string]string{
+		NativeBridge:               "@soong_injection//native_bridge:native_bridge",
+		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey,
+	}
+
 	errorProneMap = map[string]string{
 		ErrorproneDisabled:         "//build/bazel/rules/java/errorprone:errorprone_globally_disabled",
 		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey,
@@ -249,6 +283,8 @@ const (
 	errorProneDisabled
 	// TODO: b/294868620 - Remove when completing the bug
 	sanitizersEnabled
+	image
+	nativeBridge
 )
 
 func osArchString(os string, arch string) string {
@@ -267,6 +303,8 @@ func (ct configurationType) String() string {
 		errorProneDisabled: "errorprone_disabled",
 		// TODO: b/294868620 - Remove when completing the bug
 		sanitizersEnabled: "sanitizers_enabled",
+		image:             "image",
+		nativeBridge:      "native_bridge",
 	}[ct]
 }
 
@@ -306,6 +344,14 @@ func (ct configurationType) validateConfig(config string) {
 		if _, ok := sanitizersEnabledMap[config]; !ok {
 			panic(fmt.Errorf("Unknown sanitizers_enabled config: %s", config))
 		}
+	case image:
+		if _, ok := imageMap[config]; !ok {
+			panic(fmt.Errorf("Unknown image config: %s", config))
+		}
+	case nativeBridge:
+		if _, ok := nativeBridgeMap[config]; !ok {
+			panic(fmt.Errorf("Unknown native_bridge config: %s", config))
+		}
 	default:
 		panic(fmt.Errorf("Unrecognized ConfigurationType %d", ct))
 	}
@@ -327,6 +373,9 @@ func (ca ConfigurationAxis) SelectKey(config string) string {
 		if config == ConditionsDefaultConfigKey {
 			return ConditionsDefaultSelectKey
 		}
+		if ca.soongConfigBool {
+			return fmt.Sprintf("@%s//%s:%s", SoongInjectionDirName, SoongConfigBoolSettingsDir, config)
+		}
 		return fmt.Sprintf("%s:%s", productVariableBazelPackage, config)
 	case osAndInApex:
 		if ret, exists := osAndInApexMap[config]; exists {
@@ -340,11 +389,79 @@ func (ca ConfigurationAxis) SelectKey(config string) string {
 	// TODO: b/294868620 - Remove when completing the bug
 	case sanitizersEnabled:
 		return sanitizersEnabledMap[config]
+	case image:
+		return imageMap[config]
+	case nativeBridge:
+		return nativeBridgeMap[config]
 	default:
 		panic(fmt.Errorf("Unrecognized ConfigurationType %d", ca.configurationType))
 	}
 }
 
+// Name returns the name of the axis. For product variable axes, it includes the product
+// variable (or soong config variable) the axis selects on.
+func (ca ConfigurationAxis) Name() string {
+	if ca.subType != "" {
+		return ca.configurationType.String() + ":" + ca.subType
+	}
+	return ca.configurationType.String()
+}
+
+// SoongPropertyPath returns the path of the Android.bp property struct from which the values of
+// the given config of the axis are converted, e.g. "arch.arm64" or "target.android". It returns
+// an empty string for the default condition, and for axes whose values are computed by the
+// converters rather than read from a property struct.
+func (ca ConfigurationAxis) SoongPropertyPath(config string) string {
+	if config == ConditionsDefaultConfigKey {
+		return ""
+	}
+	switch ca.configurationType {
+	case arch:
+		return "arch." + config
+	case os, osArch, image:
+		return "target." + config
+	case nativeBridge:
+		return "target.native_bridge"
+	case productVariables:
+		// The subtype is either <product variable>__<arch> or
+		// <namespace>__<soong config variable>__<os>.
+		parts := strings.Split(ca.subType, "__")
+		if len(parts) == 3 {
+			return "soong_config_variables." + parts[1]
+		}
+		return "product_variables." + parts[0]
+	case osAndInApex:
+		if _, ok := platformOsMap[config]; ok {
+			return "target." + config
+		}
+	}
+	return ""
+}
+
+// PlatformConfigs returns the configs of an arch, os or os_arch axis, excluding the default
+// condition. It returns nil for other axes, whose configs aren't known ahead of time.
+func (ca ConfigurationAxis) PlatformConfigs() []string {
+	var configMap map[string]string
+	switch ca.configurationType {
+	case arch:
+		return append(append([]string{}, Archs32Bit...), Archs64Bit...)
+	case os:
+		configMap = platformOsMap
+	case osArch:
+		configMap = platformOsArchMap
+	default:
+		return nil
+	}
+	configs := make([]string, 0, len(configMap))
+	for config := range configMap {
+		if config != ConditionsDefaultConfigKey {
+			configs = append(configs, config)
+		}
+	}
+	sort.Strings(configs)
+	return configs
+}
+
 var (
 	// Indicating there is no configuration axis
 	NoConfigAxis = ConfigurationAxis{configurationType: noConfig}
@@ -363,6 +480,12 @@ var (
 
 	// TODO: b/294868620 - Remove when completing the bug
 	SanitizersEnabledAxis = ConfigurationAxis{configurationType: sanitizersEnabled}
+
+	// An axis for the vendor, product and recovery image variants of a module
+	ImageAxis = ConfigurationAxis{configurationType: image}
+
+	// An axis for the variants of a module translated with native bridge
+	NativeBridgeAxis = ConfigurationAxis{configurationType: nativeBridge}
 )
 
 // ProductVariableConfigurationAxis returns an axis for the given product variable
@@ -374,6 +497,17 @@ func ProductVariableConfigurationAxis(archVariant bool, variable string) Configu
 	}
 }
 
+// SoongConfigBoolVariableConfigurationAxis returns an axis for the Soong config bool
+// variable of the given name, whose select() keys refer to the config_settings bp2build
+// generates in SoongConfigBoolSettingsDir.
+func SoongConfigBoolVariableConfigurationAxis(variable string) ConfigurationAxis {
+	return ConfigurationAxis{
+		configurationType: productVariables,
+		subType:           variable,
+		soongConfigBool:   true,
+	}
+}
+
 // ConfigurationAxis is an independent axis for configuration, there should be no overlap between
 // elements within an axis.
 type ConfigurationAxis struct {
@@ -383,6 +517,8 @@ type ConfigurationAxis struct {
 	subType string
 
 	archVariant bool
+	// soongConfigBool is true for the axes of Soong config bool variables.
+	soongConfigBool bool
 }
 
 func (ca *ConfigurationAxis) less(other ConfigurationAxis) bool {
@@ -426,7 +426,7 @@ func (la *LabelAttribute) SetSelectValue(axis ConfigurationAxis, config string,
 	switch axis.configurationType {
 	case noConfig:
 		la.Value = &value
-	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled, image, nativeBridge:
 		if la.ConfigurableValues == nil {
 			la.ConfigurableValues = make(configurableLabels)
 		}
@@ -442,7 +442,7 @@ func (la *LabelAttribute) SelectValue(axis ConfigurationAxis, config string) *La
 	switch axis.configurationType {
 	case noConfig:
 		return la.Value
-	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled, image, nativeBridge:
 		return la.ConfigurableValues[axis][config]
 	default:
 		panic(fmt.Errorf("Unrecognized ConfigurationAxis %s", axis))
@@ -512,7 +512,7 @@ func (ba *BoolAttribute) SetSelectValue(axis ConfigurationAxis, config string, v
 	switch axis.configurationType {
 	case noConfig:
 		ba.Value = value
-	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled, image, nativeBridge:
 		if ba.ConfigurableValues == nil {
 			ba.ConfigurableValues = make(configurableBools)
 		}
@@ -659,7 +659,7 @@ func (ba BoolAttribute) SelectValue(axis ConfigurationAxis, config string) *bool
 	switch axis.configurationType {
 	case noConfig:
 		return ba.Value
-	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, sanitizersEnabled, image, nativeBridge:
 		if v, ok := ba.ConfigurableValues[axis][config]; ok {
 			return &v
 		} else {
@@ -794,7 +794,7 @@ func (lla *LabelListAttribute) SetSelectValue(axis ConfigurationAxis, config str
 	switch axis.configurationType {
 	case noConfig:
 		lla.Value = list
-	case arch, os, osArch, productVariables, osAndInApex, inApex, errorProneDisabled, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, inApex, errorProneDisabled, sanitizersEnabled, image, nativeBridge:
 		if lla.ConfigurableValues == nil {
 			lla.ConfigurableValues = make(configurableLabelLists)
 		}
@@ -810,7 +810,7 @@ func (lla *LabelListAttribute) SelectValue(axis ConfigurationAxis, config string
 	switch axis.configurationType {
 	case noConfig:
 		return lla.Value
-	case arch, os, osArch, productVariables, osAndInApex, inApex, errorProneDisabled, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, inApex, errorProneDisabled, sanitizersEnabled, image, nativeBridge:
 		return lla.ConfigurableValues[axis][config]
 	default:
 		panic(fmt.Errorf("Unrecognized ConfigurationAxis %s", axis))
@@ -923,13 +923,20 @@ func (lla *LabelListAttribute) ResolveExcludes() {
 			if config == OsAndroid || len(labels.Excludes) == 0 {
 				continue
 			}
+			includes := inApexLabels.Includes
+			// Keep the includes of OSes that select their own libraries rather than the default.
+			if existing, ok := lla.ConfigurableValues[OsAndInApexAxis][config]; ok {
+				includes = existing.Includes
+			}
 			lla.ConfigurableValues[OsAndInApexAxis][config] = LabelList{
-				Includes: inApexLabels.Includes,
+				Includes: includes,
 				Excludes: labels.Excludes,
 			}
 		}
 	}
 
+	lla.resolveOsArchExcludesOfOsValues()
+
 	for axis, configToLabels := range lla.ConfigurableValues {
 		baseLabels := lla.Value.deepCopy()
 		for config, val := range configToLabels {
@@ -964,6 +971,44 @@ func (lla *LabelListAttribute) ResolveExcludes() {
 	}
 }
 
+// resolveOsArchExcludesOfOsValues handles labels that are included for an OS but
+// excluded for some of its OS & arch combinations (e.g. target.android.srcs and
+// target.android_x86.exclude_srcs). Excludes are only resolved against the base
+// value, so these labels are moved from the OS axis to every OS & arch
+// combination of that OS which doesn't exclude them.
+func (lla *LabelListAttribute) resolveOsArchExcludesOfOsValues() {
+	osArchToLabels, ok := lla.ConfigurableValues[OsArchConfigurationAxis]
+	if !ok {
+		return
+	}
+	for os, osLabels := range lla.ConfigurableValues[OsConfigurationAxis] {
+		var osArchConfigs []string
+		var osArchExcludes LabelList
+		for osArch := range platformOsArchMap {
+			if strings.HasPrefix(osArch, os+"_") {
+				osArchConfigs = append(osArchConfigs, osArch)
+				osArchExcludes.Append(LabelList{Includes: osArchToLabels[osArch].Excludes})
+			}
+		}
+		if len(osArchExcludes.Includes) == 0 {
+			continue
+		}
+		remaining := SubtractBazelLabelList(osLabels, osArchExcludes)
+		moved := SubtractBazelLabelList(osLabels, remaining)
+		if len(moved.Includes) == 0 {
+			continue
+		}
+		lla.ConfigurableValues[OsConfigurationAxis][os] = remaining
+		for _, osArch := range osArchConfigs {
+			osArchLabels := osArchToLabels[osArch]
+			osArchLabels.Append(LabelList{Includes: SubtractBazelLabels(moved.Includes, osArchLabels.Excludes)})
+			if len(osArchLabels.Includes) > 0 || len(osArchLabels.Excludes) > 0 {
+				lla.SetSelectValue(OsArchConfigurationAxis, osArch, osArchLabels)
+			}
+		}
+	}
+}
+
 // Partition splits a LabelListAttribute into two LabelListAttributes depending
 // on the return value of the predicate.
 // This function preserves the Includes and Excludes, but it does not provide
@@ -1168,7 +1213,7 @@ func (sa *StringAttribute) SetSelectValue(axis ConfigurationAxis, config string,
 	switch axis.configurationType {
 	case noConfig:
 		sa.Value = str
-	case arch, os, osArch, productVariables, sanitizersEnabled:
+	case arch, os, osArch, productVariables, sanitizersEnabled, image, nativeBridge:
 		if sa.ConfigurableValues == nil {
 			sa.ConfigurableValues = make(configurableStrings)
 		}
@@ -1184,7 +1229,7 @@ func (sa *StringAttribute) SelectValue(axis ConfigurationAxis, config string) *s
 	switch axis.configurationType {
 	case noConfig:
 		return sa.Value
-	case arch, os, osArch, productVariables, sanitizersEnabled:
+	case arch, os, osArch, productVariables, sanitizersEnabled, image, nativeBridge:
 		if v, ok := sa.ConfigurableValues[axis][config]; ok {
 			return v
 		} else {
@@ -1374,7 +1419,7 @@ func (sla *StringListAttribute) SetSelectValue(axis ConfigurationAxis, config st
 	switch axis.configurationType {
 	case noConfig:
 		sla.Value = list
-	case arch, os, osArch, productVariables, osAndInApex, errorProneDisabled, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, errorProneDisabled, sanitizersEnabled, image, nativeBridge:
 		if sla.ConfigurableValues == nil {
 			sla.ConfigurableValues = make(configurableStringLists)
 		}
@@ -1390,7 +1435,7 @@ func (sla *StringListAttribute) SelectValue(axis ConfigurationAxis, config strin
 	switch axis.configurationType {
 	case noConfig:
 		return sla.Value
-	case arch, os, osArch, productVariables, osAndInApex, errorProneDisabled, sanitizersEnabled:
+	case arch, os, osArch, productVariables, osAndInApex, errorProneDisabled, sanitizersEnabled, image, nativeBridge:
 		return sla.ConfigurableValues[axis][config]
 	default:
 		panic(fmt.Errorf("Unrecognized ConfigurationAxis %s", axis))
@@ -834,3 +834,33 @@ func TestDeduplicateAxesFromBase(t *testing.T) {
 		}
 	}
 }
+
+func TestImageAndNativeBridgeSelectValues(t *testing.T) {
+	for _, axisAndConfig := range []struct {
+		axis   ConfigurationAxis
+		config string
+	}{
+		{ImageAxis, ImageVendor},
+		{NativeBridgeAxis, NativeBridge},
+	} {
+		axis, config := axisAndConfig.axis, axisAndConfig.config
+
+		label := LabelAttribute{}
+		label.SetSelectValue(axis, config, Label{Label: ":foo"})
+		if got := label.SelectValue(axis, config); got == nil || got.Label != ":foo" {
+			t.Errorf("Expected label %q for %s %s, got %v", ":foo", axis, config, got)
+		}
+
+		boolAttr := BoolAttribute{}
+		boolAttr.SetSelectValue(axis, config, proptools.BoolPtr(true))
+		if got := boolAttr.SelectValue(axis, config); got == nil || !*got {
+			t.Errorf("Expected bool true for %s %s, got %v", axis, config, got)
+		}
+
+		str := StringAttribute{}
+		str.SetSelectValue(axis, config, proptools.StringPtr("foo"))
+		if got := str.SelectValue(axis, config); got == nil || *got != "foo" {
+			t.Errorf("Expected string %q for %s %s, got %v", "foo", axis, config, got)
+		}
+	}
+}
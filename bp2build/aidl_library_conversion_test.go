@@ -117,3 +117,32 @@ func TestAidlLibraryWithDeps(t *testing.T) {
 		})
 	})
 }
+
+func TestAidlLibraryWithVersionedDeps(t *testing.T) {
+	runAidlLibraryTestCase(t, Bp2buildTestCase{
+		Description: "aidl_library with deps on versioned aidl_interface backends",
+		Blueprint: `
+	aidl_library {
+		name: "bar-V2-ndk",
+	}
+	aidl_library {
+		name: "baz-V1",
+	}
+	aidl_library {
+		name: "foo",
+		srcs: ["aidl/Foo.aidl"],
+		deps: ["bar-V2-ndk", "baz-V1"],
+	}`,
+		StubbedBuildDefinitions: []string{"bar-V2-ndk", "baz-V1"},
+		ExpectedBazelTargets: []string{
+			MakeBazelTargetNoRestrictions("aidl_library", "foo", AttrNameToString{
+				"srcs": `["aidl/Foo.aidl"]`,
+				"deps": `[
+        ":bar-V2",
+        ":baz-V1",
+    ]`,
+				"tags": `["apex_available=//apex_available:anyapex"]`,
+			}),
+		},
+	})
+}
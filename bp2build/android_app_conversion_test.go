@@ -516,3 +516,140 @@ filegroup {
 		}})
 
 }
+
+func TestOverrideAndroidApp(t *testing.T) {
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		registerAndroidAppModuleTypes(ctx)
+		ctx.RegisterModuleType("android_app", java.AndroidAppFactory)
+	}, Bp2buildTestCase{
+		Description:                "override_android_app",
+		ModuleTypeUnderTest:        "override_android_app",
+		ModuleTypeUnderTestFactory: java.OverrideAndroidAppModuleFactory,
+		Filesystem:                 map[string]string{},
+		StubbedBuildDefinitions:    []string{"foocert"},
+		Blueprint: simpleModule("filegroup", "foocert") + `
+android_app {
+	name: "TestApp",
+	package_name: "com.android.test",
+	certificate: "platform",
+	sdk_version: "current",
+	optimize: {
+		enabled: false,
+	},
+}
+
+override_android_app {
+	name: "OverrideApp",
+	base: "TestApp",
+	package_name: "com.google.android.test",
+	certificate: ":foocert",
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("android_binary", "TestApp", AttrNameToString{
+				"certificate_name": `"platform"`,
+				"custom_package":   `"com.android.test"`,
+				"manifest":         `"AndroidManifest.xml"`,
+				"resource_files":   `[]`,
+				"sdk_version":      `"current"`,
+				"optimize":         `False`,
+			}),
+			MakeBazelTarget("android_binary", "OverrideApp", AttrNameToString{
+				"certificate":    `":foocert"`,
+				"custom_package": `"com.google.android.test"`,
+				"manifest":       `"AndroidManifest.xml"`,
+				"resource_files": `[]`,
+				"sdk_version":    `"current"`,
+				"optimize":       `False`,
+			}),
+		}})
+}
+
+func TestOverrideAndroidAppUnsupportedProperties(t *testing.T) {
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		registerAndroidAppModuleTypes(ctx)
+		ctx.RegisterModuleType("android_app", java.AndroidAppFactory)
+	}, Bp2buildTestCase{
+		Description:                "override_android_app with properties android_binary can't express",
+		ModuleTypeUnderTest:        "override_android_app",
+		ModuleTypeUnderTestFactory: java.OverrideAndroidAppModuleFactory,
+		Filesystem: map[string]string{
+			"lineage.bin": "",
+		},
+		Blueprint: `
+android_app {
+	name: "TestApp",
+	sdk_version: "current",
+	optimize: {
+		enabled: false,
+	},
+	bazel_module: { bp2build_available: false },
+}
+
+override_android_app {
+	name: "OverrideApp",
+	base: "TestApp",
+	lineage: "lineage.bin",
+	logging_parent: "com.android.parent",
+}
+`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
+func TestOverrideAndroidAppOfAppWithHelperTargets(t *testing.T) {
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		registerAndroidAppModuleTypes(ctx)
+		ctx.RegisterModuleType("android_app", java.AndroidAppFactory)
+	}, Bp2buildTestCase{
+		Description:                "override_android_app of an android_app generating helper targets",
+		ModuleTypeUnderTest:        "override_android_app",
+		ModuleTypeUnderTestFactory: java.OverrideAndroidAppModuleFactory,
+		Blueprint: `
+android_app {
+	name: "TestApp",
+	sdk_version: "current",
+	optimize: {
+		enabled: true,
+		shrink: true,
+		optimize: true,
+		obfuscate: false,
+	},
+	bazel_module: { bp2build_available: false },
+}
+
+override_android_app {
+	name: "OverrideApp",
+	base: "TestApp",
+	package_name: "com.google.android.test",
+}
+`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
+func TestOverrideAndroidAppInDifferentPackage(t *testing.T) {
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		registerAndroidAppModuleTypes(ctx)
+		ctx.RegisterModuleType("android_app", java.AndroidAppFactory)
+	}, Bp2buildTestCase{
+		Description:                "override_android_app in a different package than its base android_app",
+		ModuleTypeUnderTest:        "override_android_app",
+		ModuleTypeUnderTestFactory: java.OverrideAndroidAppModuleFactory,
+		Filesystem: map[string]string{
+			"base/Android.bp": `
+android_app {
+	name: "TestApp",
+	sdk_version: "current",
+	bazel_module: { bp2build_available: false },
+}`,
+		},
+		Blueprint: `
+override_android_app {
+	name: "OverrideApp",
+	base: "TestApp",
+}
+`,
+		ExpectedBazelTargets: []string{},
+	})
+}
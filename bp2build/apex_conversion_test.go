@@ -173,6 +173,10 @@ apex {
             ":native_shared_lib_1",
             ":native_shared_lib_2",
         ],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [
+            ":native_shared_lib_1",
+            ":native_shared_lib_2",
+        ],
         "//build/bazel_common_rules/platforms/arch:x86_64": [
             ":native_shared_lib_1",
             ":native_shared_lib_2",
@@ -304,6 +308,12 @@ filegroup {
             ":native_shared_lib_for_lib64",
             ":native_shared_lib_for_first",
         ],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [
+            ":unnested_native_shared_lib",
+            ":native_shared_lib_for_both",
+            ":native_shared_lib_for_lib64",
+            ":native_shared_lib_for_first",
+        ],
         "//build/bazel_common_rules/platforms/arch:x86_64": [
             ":unnested_native_shared_lib",
             ":native_shared_lib_for_both",
@@ -343,6 +353,12 @@ func TestApexBundleCompileMultilibFirstAndDefaultValue(t *testing.T) {
             ":native_shared_lib_for_lib64",
             ":native_shared_lib_for_first",
         ],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [
+            ":unnested_native_shared_lib",
+            ":native_shared_lib_for_both",
+            ":native_shared_lib_for_lib64",
+            ":native_shared_lib_for_first",
+        ],
         "//build/bazel_common_rules/platforms/arch:x86_64": [
             ":unnested_native_shared_lib",
             ":native_shared_lib_for_both",
@@ -439,6 +455,12 @@ filegroup {
             ":native_shared_lib_for_lib64",
             ":native_shared_lib_for_first",
         ],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [
+            ":unnested_native_shared_lib",
+            ":native_shared_lib_for_both",
+            ":native_shared_lib_for_lib64",
+            ":native_shared_lib_for_first",
+        ],
         "//build/bazel_common_rules/platforms/arch:x86_64": [
             ":unnested_native_shared_lib",
             ":native_shared_lib_for_both",
@@ -686,6 +708,10 @@ override_apex {
             ":native_shared_lib_1",
             ":native_shared_lib_2",
         ],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [
+            ":native_shared_lib_1",
+            ":native_shared_lib_2",
+        ],
         "//build/bazel_common_rules/platforms/arch:x86_64": [
             ":native_shared_lib_1",
             ":native_shared_lib_2",
@@ -802,6 +828,7 @@ override_apex {
     })`,
 				"native_shared_libs_64": `select({
         "//build/bazel_common_rules/platforms/arch:arm64": [":native_shared_lib_1"],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [":native_shared_lib_1"],
         "//build/bazel_common_rules/platforms/arch:x86_64": [":native_shared_lib_1"],
         "//conditions:default": [],
     })`,
@@ -1245,8 +1272,10 @@ func TestApexWithStubLib(t *testing.T) {
 		Description:                "apex - static variant of stub lib should not have apex_available tag",
 		ModuleTypeUnderTest:        "apex",
 		ModuleTypeUnderTestFactory: apex.BundleFactory,
-		Filesystem:                 map[string]string{},
-		StubbedBuildDefinitions:    []string{"myapex-file_contexts"},
+		Filesystem: map[string]string{
+			"foo.map.txt": "",
+		},
+		StubbedBuildDefinitions: []string{"myapex-file_contexts"},
 		Blueprint: `
 cc_library{
 	name: "foo",
@@ -1304,6 +1333,7 @@ apex {
     })`,
 				"native_shared_libs_64": `select({
         "//build/bazel_common_rules/platforms/arch:arm64": [":foo"],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [":foo"],
         "//build/bazel_common_rules/platforms/arch:x86_64": [":foo"],
         "//conditions:default": [],
     })`,
@@ -1312,6 +1342,164 @@ apex {
 	})
 }
 
+func TestApexWithRuntimeLibs(t *testing.T) {
+	runApexTestCase(t, Bp2buildTestCase{
+		Description:                "apex - runtime_libs of native libs and binaries are included in the apex",
+		ModuleTypeUnderTest:        "apex",
+		ModuleTypeUnderTestFactory: apex.BundleFactory,
+		Filesystem: map[string]string{
+			"stubs.map.txt": "",
+		},
+		StubbedBuildDefinitions: []string{"myapex-file_contexts", "foo", "bar", "baz", "stubs", "bin", "binlib"},
+		Blueprint: `
+cc_library {
+	name: "foo",
+	runtime_libs: ["bar", "stubs"],
+}
+
+cc_library {
+	name: "bar",
+	runtime_libs: ["baz", "foo"],
+}
+
+cc_library {
+	name: "baz",
+}
+
+cc_library {
+	name: "stubs",
+	stubs: { symbol_file: "stubs.map.txt", versions: ["29"] },
+}
+
+cc_binary {
+	name: "bin",
+	runtime_libs: ["binlib", "bar"],
+}
+
+cc_library {
+	name: "binlib",
+}
+
+apex {
+	name: "myapex",
+	manifest: "myapex_manifest.json",
+	file_contexts: ":myapex-file_contexts",
+	binaries: ["bin"],
+	native_shared_libs: ["foo"],
+}
+` + simpleModule("filegroup", "myapex-file_contexts"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("apex", "myapex", AttrNameToString{
+				"file_contexts": `":myapex-file_contexts"`,
+				"manifest":      `"myapex_manifest.json"`,
+				"binaries":      `[":bin"]`,
+				"native_shared_libs_32": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": [
+            ":foo",
+            ":bar",
+            ":binlib",
+            ":baz",
+        ],
+        "//build/bazel_common_rules/platforms/arch:x86": [
+            ":foo",
+            ":bar",
+            ":binlib",
+            ":baz",
+        ],
+        "//conditions:default": [],
+    })`,
+				"native_shared_libs_64": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [
+            ":foo",
+            ":bar",
+            ":binlib",
+            ":baz",
+        ],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [
+            ":foo",
+            ":bar",
+            ":binlib",
+            ":baz",
+        ],
+        "//build/bazel_common_rules/platforms/arch:x86_64": [
+            ":foo",
+            ":bar",
+            ":binlib",
+            ":baz",
+        ],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestApexWithArchAndBitnessSpecificRuntimeLibs(t *testing.T) {
+	runApexTestCase(t, Bp2buildTestCase{
+		Description:                "apex - runtime_libs are read per arch and added per bitness",
+		ModuleTypeUnderTest:        "apex",
+		ModuleTypeUnderTestFactory: apex.BundleFactory,
+		StubbedBuildDefinitions:    []string{"myapex-file_contexts", "foo", "bar", "baz"},
+		Blueprint: `
+cc_library {
+	name: "foo",
+	runtime_libs: ["bar"],
+	arch: {
+		x86: {
+			runtime_libs: ["baz"],
+		},
+	},
+}
+
+cc_library {
+	name: "bar",
+}
+
+cc_library {
+	name: "baz",
+}
+
+apex {
+	name: "myapex",
+	manifest: "myapex_manifest.json",
+	file_contexts: ":myapex-file_contexts",
+	multilib: {
+		lib32: {
+			native_shared_libs: ["foo"],
+		},
+		lib64: {
+			native_shared_libs: ["bar"],
+		},
+	},
+}
+` + simpleModule("filegroup", "myapex-file_contexts"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("apex", "myapex", AttrNameToString{
+				"file_contexts": `":myapex-file_contexts"`,
+				"manifest":      `"myapex_manifest.json"`,
+				"native_shared_libs_32": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": [
+            ":foo",
+            ":bar",
+        ],
+        "//build/bazel_common_rules/platforms/arch:x86": [
+            ":foo",
+            ":bar",
+            ":baz",
+        ],
+        "//conditions:default": [],
+    })`,
+				"native_shared_libs_64": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":bar"],
+        "//build/bazel_common_rules/platforms/arch:riscv64": [":bar"],
+        "//build/bazel_common_rules/platforms/arch:x86_64": [":bar"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
 func TestApexCertificateIsSrc(t *testing.T) {
 	runApexTestCase(t, Bp2buildTestCase{
 		Description:                "apex - certificate is src",
@@ -0,0 +1,228 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"android/soong/android"
+	"android/soong/bazel"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// attributeMetadataFileName is the name of the per-package JSON file describing
+// where the select() branches of the generated attributes come from.
+const attributeMetadataFileName = "bp2build_attribute_metadata.json"
+
+// selectBranchMetadata describes a branch of a select() of a generated attribute.
+type selectBranchMetadata struct {
+	// Axis is the name of the configuration axis, e.g. "arch" or
+	// "product_variables:eng__".
+	Axis string `json:"axis"`
+	// Config is the config of the axis the branch is for, e.g. "arm64".
+	Config string `json:"config"`
+	// SelectKey is the key of the branch in the select(), e.g.
+	// "//build/bazel_common_rules/platforms/arch:arm64".
+	SelectKey string `json:"select_key"`
+	// SoongProperties are the Android.bp properties the value of the branch
+	// was converted from, e.g. "arch.arm64.cflags". They are empty for values
+	// computed by the converter.
+	SoongProperties []string `json:"soong_properties,omitempty"`
+}
+
+// targetAttributeMetadata describes the configurable attributes of a generated target.
+type targetAttributeMetadata struct {
+	SoongModuleName string                            `json:"soong_module_name,omitempty"`
+	SoongModuleType string                            `json:"soong_module_type,omitempty"`
+	Attributes      map[string][]selectBranchMetadata `json:"attributes"`
+}
+
+// soongPropertiesOfAttribute maps the attributes which are not named after the
+// Android.bp property they are converted from to those properties. A nil entry
+// marks an attribute computed by the converters from several properties.
+var soongPropertiesOfAttribute = map[string][]string{
+	"absolute_includes":                 {"include_dirs"},
+	"additional_linker_inputs":          {"version_script", "dynamic_list", "linker_scripts"},
+	"copts":                             {"cflags"},
+	"deps":                              {"static_libs", "header_libs"},
+	"dynamic_deps":                      {"shared_libs"},
+	"export_absolute_includes":          {"export_include_dirs"},
+	"export_includes":                   {"export_include_dirs"},
+	"export_system_includes":            {"export_system_include_dirs"},
+	"features":                          nil,
+	"implementation_deps":               {"static_libs", "header_libs"},
+	"implementation_dynamic_deps":       {"shared_libs"},
+	"implementation_whole_archive_deps": {"whole_static_libs"},
+	"linkopts":                          {"ldflags"},
+	"local_includes":                    {"local_include_dirs"},
+	"runtime_deps":                      {"runtime_libs"},
+	"srcs_as":                           {"srcs"},
+	"srcs_c":                            {"srcs"},
+	"system_dynamic_deps":               {"system_shared_libs"},
+	"tags":                              nil,
+	"target_compatible_with":            nil,
+	"whole_archive_deps":                {"whole_static_libs"},
+}
+
+// soongPropertiesForBranch returns the full names of the Android.bp properties
+// the given branch of the given attribute was converted from.
+func soongPropertiesForBranch(attribute string, axis bazel.ConfigurationAxis, config string) []string {
+	path := axis.SoongPropertyPath(config)
+	if path == "" {
+		return nil
+	}
+	properties, ok := soongPropertiesOfAttribute[attribute]
+	if !ok {
+		properties = []string{attribute}
+	}
+	var ret []string
+	for _, property := range properties {
+		ret = append(ret, path+"."+property)
+	}
+	return ret
+}
+
+// attributeMetadataFiles returns, for each package with configurable attributes, a
+// JSON file mapping each target to the select() branches of its attributes: which
+// configuration axis and config each branch is for, and which Android.bp properties
+// the branch was converted from.
+func attributeMetadataFiles(buildToTargets map[string]BazelTargets) ([]BazelFile, error) {
+	var files []BazelFile
+	for _, dir := range android.SortedKeys(buildToTargets) {
+		metadata := map[string]targetAttributeMetadata{}
+		for _, target := range buildToTargets[dir] {
+			if len(target.selectBranches) == 0 {
+				continue
+			}
+			metadata[target.name] = targetAttributeMetadata{
+				SoongModuleName: target.soongModuleName,
+				SoongModuleType: target.soongModuleType,
+				Attributes:      target.selectBranches,
+			}
+		}
+		if len(metadata) == 0 {
+			continue
+		}
+		contents, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, newFile(dir, attributeMetadataFileName, string(contents)+"\n"))
+	}
+	return files, nil
+}
+
+// attributeSelectBranches returns the printed select() branches of the
+// configurable attributes of the given attribute structs, keyed by attribute
+// name. Branches which are dropped or merged into the plain value when the
+// attributes are rendered are not reported.
+func attributeSelectBranches(attrs []interface{}) (map[string][]selectBranchMetadata, error) {
+	branches := map[string][]selectBranchMetadata{}
+	for _, attr := range attrs {
+		attrValue := reflect.ValueOf(attr)
+		if !isStructPtr(attrValue.Type()) {
+			continue
+		}
+		if err := collectSelectBranches(attrValue.Elem(), branches); err != nil {
+			return nil, err
+		}
+	}
+	return branches, nil
+}
+
+// collectSelectBranches adds the printed select() branches of the configurable
+// attributes of the given attribute struct to branches, keyed by attribute name.
+func collectSelectBranches(structValue reflect.Value, branches map[string][]selectBranchMetadata) error {
+	structType := structValue.Type()
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structType.Field(i)
+		if shouldSkipStructField(field) {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Struct {
+			continue
+		}
+		attr, ok := fieldValue.Interface().(bazel.Attribute)
+		if !ok {
+			// Attributes of embedded structs are flattened into the containing struct.
+			if field.Anonymous {
+				if err := collectSelectBranches(fieldValue, branches); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		resolved, err := resolveAttribute(attr)
+		if err != nil {
+			return err
+		}
+		name := proptools.PropertyNameForField(field.Name)
+		var fieldBranches []selectBranchMetadata
+		for _, selectMap := range resolved.selects {
+			selectKeys := printedSelectKeys(selectMap, resolved.emitZeroValues)
+			if len(selectKeys) == 0 {
+				// The select is printed as its default value.
+				continue
+			}
+			axis, configs := axisOfSelect(resolved.attribute, selectKeys)
+			for _, selectKey := range selectKeys {
+				fieldBranches = append(fieldBranches, selectBranchMetadata{
+					Axis:            axis.Name(),
+					Config:          configs[selectKey],
+					SelectKey:       selectKey,
+					SoongProperties: soongPropertiesForBranch(name, axis, configs[selectKey]),
+				})
+			}
+		}
+		if len(fieldBranches) > 0 {
+			branches[name] = fieldBranches
+		}
+	}
+	return nil
+}
+
+// axisOfSelect returns the first configuration axis of the attribute with configs
+// for all the given select keys, and the config of each of these keys.
+func axisOfSelect(attribute interface{}, selectKeys []string) (bazel.ConfigurationAxis, map[string]string) {
+	attrValue := reflect.ValueOf(attribute)
+	configurableValues := attrValue.Elem().FieldByName("ConfigurableValues")
+	axes := attrValue.MethodByName("SortedConfigurationAxes").Call(nil)[0].Interface().([]bazel.ConfigurationAxis)
+	for _, axis := range axes {
+		configs := map[string]string{}
+		for _, config := range configurableValues.MapIndex(reflect.ValueOf(axis)).MapKeys() {
+			configs[axis.SelectKey(config.String())] = config.String()
+		}
+		found := true
+		for _, selectKey := range selectKeys {
+			if _, ok := configs[selectKey]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return axis, configs
+		}
+	}
+	return bazel.NoConfigAxis, map[string]string{}
+}
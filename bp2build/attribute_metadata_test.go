@@ -0,0 +1,110 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/bazel"
+)
+
+func TestAttributeMetadataFiles(t *testing.T) {
+	copts := bazel.MakeStringListAttribute([]string{"-Wall"})
+	copts.SetSelectValue(bazel.ArchConfigurationAxis, "arm64", []string{"-DARM64"})
+	copts.SetSelectValue(bazel.OsConfigurationAxis, "android", []string{"-DANDROID"})
+	// The select of the os axis has the same value in all its branches, so it is
+	// collapsed into the plain value and not reported.
+	linkopts := bazel.StringListAttribute{}
+	linkopts.SetSelectValue(bazel.OsConfigurationAxis, "android", []string{"-lfoo"})
+	linkopts.SetSelectValue(bazel.OsConfigurationAxis, "linux_glibc", []string{"-lfoo"})
+	linkopts.SetSelectValue(bazel.OsConfigurationAxis, bazel.ConditionsDefaultConfigKey, []string{"-lfoo"})
+	linkopts.SetSelectValue(bazel.ArchConfigurationAxis, "arm", []string{"-Wl,--fix-cortex-a8"})
+	attrs := struct {
+		Copts    bazel.StringListAttribute
+		Linkopts bazel.StringListAttribute
+		Srcs     bazel.LabelListAttribute
+	}{
+		Copts:    copts,
+		Linkopts: linkopts,
+		Srcs:     bazel.MakeLabelListAttribute(bazel.MakeLabelList([]bazel.Label{{Label: "a.cpp"}})),
+	}
+	target, err := generateBazelTarget(nil, bTarget{
+		targetName:     "foo",
+		targetPackage:  "pkg",
+		bazelRuleClass: "cc_library_static",
+		bazelAttributes: []interface{}{
+			&attrs,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target.soongModuleName = "foo"
+	target.soongModuleType = "cc_library_static"
+
+	files, err := attributeMetadataFiles(map[string]BazelTargets{
+		"pkg":   {target},
+		"other": {{name: "bar", ruleClass: "filegroup"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected one attribute metadata file, got %d: %v", len(files), files)
+	}
+	if files[0].Dir != "pkg" || files[0].Basename != attributeMetadataFileName {
+		t.Errorf("Unexpected attribute metadata file %s/%s", files[0].Dir, files[0].Basename)
+	}
+	expected := `{
+  "foo": {
+    "soong_module_name": "foo",
+    "soong_module_type": "cc_library_static",
+    "attributes": {
+      "copts": [
+        {
+          "axis": "arch",
+          "config": "arm64",
+          "select_key": "//build/bazel_common_rules/platforms/arch:arm64",
+          "soong_properties": [
+            "arch.arm64.cflags"
+          ]
+        },
+        {
+          "axis": "os",
+          "config": "android",
+          "select_key": "//build/bazel_common_rules/platforms/os:android",
+          "soong_properties": [
+            "target.android.cflags"
+          ]
+        }
+      ],
+      "linkopts": [
+        {
+          "axis": "arch",
+          "config": "arm",
+          "select_key": "//build/bazel_common_rules/platforms/arch:arm",
+          "soong_properties": [
+            "arch.arm.ldflags"
+          ]
+        }
+      ]
+    }
+  }
+}
+`
+	if files[0].Contents != expected {
+		t.Errorf("Expected attribute metadata:\n%s\ngot:\n%s", expected, files[0].Contents)
+	}
+}
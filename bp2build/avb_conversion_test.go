@@ -0,0 +1,101 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/android"
+	"android/soong/filesystem"
+)
+
+func runAvbTestCase(t *testing.T, tc Bp2buildTestCase) {
+	t.Helper()
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("avb_add_hash_footer", filesystem.AvbAddHashFooterFactory)
+		ctx.RegisterModuleType("vbmeta", filesystem.VbmetaFactory)
+	}, tc)
+}
+
+func TestAvbAddHashFooterAndVbmeta(t *testing.T) {
+	runAvbTestCase(t, Bp2buildTestCase{
+		Description: "avb_add_hash_footer and vbmeta - conversion test",
+		Blueprint: `
+avb_add_hash_footer {
+	name: "boot_signed",
+	src: "boot.img",
+	private_key: "testkey.pem",
+	salt: "0123",
+	partition_size: 1024,
+	props: [
+		{
+			name: "com.android.build.boot.fingerprint",
+			value: "fingerprint",
+		},
+	],
+	include_descriptors_from_images: ["init_boot.img"],
+}
+
+vbmeta {
+	name: "vbmeta",
+	private_key: "testkey.pem",
+	algorithm: "SHA256_RSA2048",
+	partitions: ["boot_signed"],
+	chained_partitions: [
+		{
+			name: "vbmeta_system",
+			public_key: "system.avbpubkey",
+		},
+	],
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("avb_add_hash_footer", "boot_signed", AttrNameToString{
+				"src":                             `"boot.img"`,
+				"private_key":                     `"testkey.pem"`,
+				"salt":                            `"0123"`,
+				"partition_size":                  `1024`,
+				"include_descriptors_from_images": `["init_boot.img"]`,
+				"props": `{
+        "com.android.build.boot.fingerprint": "fingerprint",
+    }`,
+			}),
+			MakeBazelTarget("vbmeta", "vbmeta", AttrNameToString{
+				"private_key":            `"testkey.pem"`,
+				"algorithm":              `"SHA256_RSA2048"`,
+				"partitions":             `[":boot_signed"]`,
+				"chained_partitions":     `["vbmeta_system:1"]`,
+				"chained_partition_keys": `["system.avbpubkey"]`,
+			}),
+		},
+	})
+}
+
+func TestVbmetaChainedPartitionWithPrivateKeyNotConverted(t *testing.T) {
+	runAvbTestCase(t, Bp2buildTestCase{
+		Description: "vbmeta - chained partition without a public key is not converted",
+		Blueprint: `
+vbmeta {
+	name: "vbmeta",
+	private_key: "testkey.pem",
+	chained_partitions: [
+		{
+			name: "vbmeta_system",
+			private_key: "system.pem",
+		},
+	],
+}`,
+		ExpectedBazelTargets: []string{},
+	})
+}
@@ -82,16 +82,32 @@ func Codegen(ctx *CodegenContext) *CodegenMetrics {
 		os.Exit(1)
 	}
 	var bp2buildFiles []BazelFile
+	allTargets := make(map[string]BazelTargets)
 	productConfig, err := createProductConfigFiles(ctx, res.moduleNameToPartition, res.metrics.convertedModulePathMap)
 	ctx.Context().EventHandler.Do("CreateBazelFile", func() {
-		allTargets := make(map[string]BazelTargets)
 		for k, v := range res.buildFileToTargets {
 			allTargets[k] = append(allTargets[k], v...)
 		}
 		for k, v := range productConfig.bp2buildTargets {
 			allTargets[k] = append(allTargets[k], v...)
 		}
-		bp2buildFiles = CreateBazelFiles(nil, allTargets, ctx.mode)
+		// The defaults attributes are exported first, as they must not
+		// reference the shared selects of the packages of their consumers.
+		if ctx.exportDefaultsAttributes {
+			bp2buildFiles = append(bp2buildFiles, exportDefaultsAttributes(allTargets)...)
+		}
+		if ctx.factorSharedSelects {
+			bp2buildFiles = append(bp2buildFiles, factorSharedSelects(allTargets)...)
+		}
+		bp2buildFiles = append(bp2buildFiles, CreateBazelFiles(nil, allTargets, ctx.mode)...)
+		if ctx.exportAttributeMetadata {
+			metadataFiles, err := attributeMetadataFiles(allTargets)
+			if err != nil {
+				fmt.Printf("ERROR: exporting attribute metadata: %s\n", err)
+				os.Exit(1)
+			}
+			bp2buildFiles = append(bp2buildFiles, metadataFiles...)
+		}
 	})
 	bp2buildFiles = append(bp2buildFiles, productConfig.bp2buildFiles...)
 	injectionFiles, err := createSoongInjectionDirFiles(ctx, res.metrics)
@@ -100,6 +116,87 @@ func Codegen(ctx *CodegenContext) *CodegenMetrics {
 		os.Exit(1)
 	}
 	injectionFiles = append(injectionFiles, productConfig.injectionFiles...)
+	// The manifest is written last, once all the targets have been generated.
+	if ctx.exportConversionManifest {
+		manifestFile, err := conversionManifestFile(allTargets)
+		if err != nil {
+			fmt.Printf("ERROR: exporting conversion manifest: %s\n", err)
+			os.Exit(1)
+		}
+		injectionFiles = append(injectionFiles, manifestFile)
+	}
+	if ctx.exportCcConversionReport {
+		ccReportFile, err := ccConversionReportFile(res.metrics)
+		if err != nil {
+			fmt.Printf("ERROR: exporting cc conversion report: %s\n", err)
+			os.Exit(1)
+		}
+		injectionFiles = append(injectionFiles, ccReportFile)
+	}
+	soongConfigSettingsFiles, err := soongConfigBoolSettingsFiles(ctx.Config().Bp2buildSoongConfigDefinitions, allTargets)
+	if err != nil {
+		fmt.Printf("ERROR: generating soong config variable settings: %s\n", err)
+		os.Exit(1)
+	}
+	injectionFiles = append(injectionFiles, soongConfigSettingsFiles...)
+	injectionFiles = append(injectionFiles, variantSettingsFiles()...)
+
+	if ctx.runValidations {
+		var errs []error
+		errs = append(errs, validateStarlarkSyntax(bp2buildFiles)...)
+		errs = append(errs, validateStarlarkSyntax(injectionFiles)...)
+		if len(errs) > 0 {
+			errMsgs := make([]string, len(errs))
+			for i, err := range errs {
+				errMsgs[i] = err.Error()
+			}
+			fmt.Printf("ERROR: %d generated file(s) have Starlark syntax errors:\n  %s\n", len(errs), strings.Join(errMsgs, "\n  "))
+			os.Exit(1)
+		}
+		keepExistingBuildFile := ctx.Config().Bp2buildPackageConfig.ShouldKeepExistingBuildFileForDir
+		fileExists := func(path string) bool {
+			_, err := os.Stat(shared.JoinPath(ctx.topDir, path))
+			return err == nil
+		}
+		if errs := validateLabelReferences(allTargets, keepExistingBuildFile, ctx.Config().HasBazelBuildTargetInSource, fileExists); len(errs) > 0 {
+			errMsgs := make([]string, len(errs))
+			for i, err := range errs {
+				errMsgs[i] = err.Error()
+			}
+			fmt.Printf("ERROR: %d generated label reference(s) do not resolve:\n  %s\n", len(errs), strings.Join(errMsgs, "\n  "))
+			os.Exit(1)
+		}
+	}
+
+	if ctx.checkOnly {
+		// Other steps of the build write to soong_injection too, so only the
+		// files bp2build generates there are checked.
+		var stale []string
+		for _, out := range []struct {
+			dir     android.OutputPath
+			files   []BazelFile
+			ownsDir bool
+		}{
+			{bp2buildDir, bp2buildFiles, true},
+			{android.PathForOutput(ctx, bazel.SoongInjectionDirName), injectionFiles, false},
+		} {
+			dirAbs := shared.JoinPath(ctx.topDir, out.dir.String())
+			dirStale, err := staleBazelFiles(dirAbs, out.files, out.ownsDir)
+			if err != nil {
+				fmt.Printf("ERROR reading %s: %s\n", dirAbs, err)
+				os.Exit(1)
+			}
+			for _, line := range dirStale {
+				stale = append(stale, filepath.Join(out.dir.Rel(), line))
+			}
+		}
+		if len(stale) > 0 {
+			fmt.Printf("ERROR: %d bp2build file(s) are stale, rerun bp2build to update them:\n  %s\n",
+				len(stale), strings.Join(stale, "\n  "))
+			os.Exit(1)
+		}
+		return &res.metrics
+	}
 
 	writeFiles(ctx, bp2buildDir, bp2buildFiles)
 	// Delete files under the bp2build root which weren't just written. An
@@ -391,7 +391,11 @@ func platformMappingSingleProduct(
 						value = "false"
 					}
 				}
-				result.WriteString(fmt.Sprintf("    --//build/bazel/product_config/soong_config_variables:%s=%s\n", strings.ToLower(key), value))
+				if hasBool {
+					result.WriteString(fmt.Sprintf("    --%s=%s\n", soongConfigFlagLabel(strings.ToLower(key)), value))
+				} else {
+					result.WriteString(fmt.Sprintf("    --//build/bazel/product_config/soong_config_variables:%s=%s\n", strings.ToLower(key), value))
+				}
 			}
 		}
 	}
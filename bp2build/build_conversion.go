@@ -22,8 +22,10 @@ for queryview/bp2build
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"android/soong/android"
 	"android/soong/bazel"
@@ -57,6 +59,39 @@ type BazelTarget struct {
 	content     string
 	ruleClass   string
 	loads       []BazelLoad
+	// attributes holds the rendered attribute values of generated targets, so
+	// that their content can be regenerated by post-processing passes.
+	attributes map[string]string
+	// selectBranches holds the printed select() branches of the configurable
+	// attributes of the target, keyed by attribute name.
+	selectBranches map[string][]selectBranchMetadata
+	// labels holds the labels referenced by the attributes of the target.
+	labels []string
+	// soongModuleName, soongModuleDir and soongModuleType identify the Soong
+	// module the target was converted from, if any.
+	soongModuleName string
+	soongModuleDir  string
+	soongModuleType string
+	// defaults are the defaults modules applied directly to the Soong module the
+	// target was converted from.
+	defaults []defaultsModule
+	// defaultsProperties holds the names of the properties set by each of the
+	// defaults modules, see setPropertyNames.
+	defaultsProperties map[defaultsModule]map[string]bool
+	// macro is the name of the macro instantiating the target instead of its
+	// rule class, if any, see exportDefaultsAttributes.
+	macro string
+	// optional is true for targets which are only generated if no other target
+	// of their package has their name, see addOptionalTargets.
+	optional bool
+}
+
+// callee returns the name of the rule or macro instantiating the target.
+func (t BazelTarget) callee() string {
+	if t.macro != "" {
+		return t.macro
+	}
+	return t.ruleClass
 }
 
 // Label is the fully qualified Bazel label constructed from the BazelTarget's
@@ -78,6 +113,46 @@ func (t BazelTarget) PackageName() string {
 	return t.packageName
 }
 
+// Name returns the name of the Bazel target, without its package.
+func (t BazelTarget) Name() string {
+	return t.name
+}
+
+// RuleClass returns the rule class of the Bazel target, e.g. "cc_library_static".
+func (t BazelTarget) RuleClass() string {
+	return t.ruleClass
+}
+
+// Content returns the Starlark representation of the Bazel target.
+func (t BazelTarget) Content() string {
+	return t.content
+}
+
+// AttributeNames returns the sorted names of the rendered attributes of the
+// Bazel target.
+func (t BazelTarget) AttributeNames() []string {
+	return android.SortedKeys(t.attributes)
+}
+
+// Attribute returns the rendered Starlark value of the given attribute of the
+// Bazel target, and whether the target sets the attribute.
+func (t BazelTarget) Attribute(name string) (string, bool) {
+	value, ok := t.attributes[name]
+	return value, ok
+}
+
+// SoongModuleName returns the name of the Soong module the Bazel target was
+// converted from, or "" for targets not converted from a Soong module.
+func (t BazelTarget) SoongModuleName() string {
+	return t.soongModuleName
+}
+
+// SoongModuleType returns the type of the Soong module the Bazel target was
+// converted from, or "" for targets not converted from a Soong module.
+func (t BazelTarget) SoongModuleType() string {
+	return t.soongModuleType
+}
+
 // BazelTargets is a typedef for a slice of BazelTarget objects.
 type BazelTargets []BazelTarget
 
@@ -91,9 +166,14 @@ func (targets BazelTargets) packageRule() *BazelTarget {
 }
 
 // sort a list of BazelTargets in-place, by name, and by generated/handcrafted types.
+// Targets of a package may come from several Blueprint files (e.g. files included
+// with `build = [...]`), so ties are broken by rule class to keep the order stable.
 func (targets BazelTargets) sort() {
-	sort.Slice(targets, func(i, j int) bool {
-		return targets[i].name < targets[j].name
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].name != targets[j].name {
+			return targets[i].name < targets[j].name
+		}
+		return targets[i].ruleClass < targets[j].ruleClass
 	})
 }
 
@@ -191,6 +271,40 @@ type CodegenContext struct {
 	additionalDeps     []string
 	unconvertedDepMode unconvertedDepsMode
 	topDir             string
+	// factorSharedSelects enables hoisting select() values shared by several
+	// targets of a package into a .bzl file in that package.
+	factorSharedSelects bool
+	// exportDefaultsAttributes enables exporting the attributes shared by the
+	// targets using a defaults module into a .bzl file in its package.
+	exportDefaultsAttributes bool
+	// checkOnly makes Codegen compare the generated files against the ones on
+	// disk and fail when they differ, instead of writing them.
+	checkOnly bool
+	// runValidations makes Codegen parse the generated Starlark files and fail
+	// on syntax errors or on label references which do not resolve.
+	runValidations bool
+	// exportAttributeMetadata enables writing a JSON file in each package
+	// describing where the select() branches of the generated attributes come from.
+	exportAttributeMetadata bool
+	// exportConversionManifest enables writing a JSON file mapping each converted
+	// module to the targets generated for it, for mixed builds.
+	exportConversionManifest bool
+	// exportCcConversionReport enables writing a JSON file listing the converted
+	// cc modules, and the unconverted ones along with the reason they were not.
+	exportCcConversionReport bool
+}
+
+// SetCheckOnly sets whether Codegen only verifies that the bp2build files on
+// disk are up to date rather than (re)writing them.
+func (ctx *CodegenContext) SetCheckOnly(checkOnly bool) {
+	ctx.checkOnly = checkOnly
+}
+
+// SetRunValidations sets whether Codegen checks the syntax of the generated
+// BUILD and .bzl files, and that the labels they reference resolve, before
+// writing them.
+func (ctx *CodegenContext) SetRunValidations(runValidations bool) {
+	ctx.runValidations = runValidations
 }
 
 func (ctx *CodegenContext) Mode() CodegenMode {
@@ -260,11 +374,16 @@ func NewCodegenContext(config android.Config, context *android.Context, mode Cod
 		unconvertedDeps = errorModulesUnconvertedDeps
 	}
 	return &CodegenContext{
-		context:            context,
-		config:             config,
-		mode:               mode,
-		unconvertedDepMode: unconvertedDeps,
-		topDir:             topDir,
+		context:                  context,
+		config:                   config,
+		mode:                     mode,
+		unconvertedDepMode:       unconvertedDeps,
+		topDir:                   topDir,
+		factorSharedSelects:      config.IsEnvTrue("BP2BUILD_FACTOR_SHARED_SELECTS"),
+		exportDefaultsAttributes: config.IsEnvTrue("BP2BUILD_EXPORT_DEFAULTS_ATTRIBUTES"),
+		exportAttributeMetadata:  config.IsEnvTrue("BP2BUILD_EXPORT_ATTRIBUTE_METADATA"),
+		exportConversionManifest: config.IsEnvTrue("BP2BUILD_EXPORT_CONVERSION_MANIFEST"),
+		exportCcConversionReport: config.IsEnvTrue("BP2BUILD_EXPORT_CC_CONVERSION_REPORT"),
 	}
 }
 
@@ -278,13 +397,16 @@ func propsToAttributes(props map[string]string) string {
 	return attributes
 }
 
-type conversionResults struct {
+// ConversionResults holds the Bazel targets generated by GenerateBazelTargets.
+type ConversionResults struct {
 	buildFileToTargets    map[string]BazelTargets
 	moduleNameToPartition map[string]string
 	metrics               CodegenMetrics
 }
 
-func (r conversionResults) BuildDirToTargets() map[string]BazelTargets {
+// BuildDirToTargets returns the generated Bazel targets, keyed by the directory
+// of the BUILD file they are written to.
+func (r ConversionResults) BuildDirToTargets() map[string]BazelTargets {
 	return r.buildFileToTargets
 }
 
@@ -699,7 +821,10 @@ func generateBazelTargetsGoBinary(ctx *android.Context, g *bootstrap.GoBinary, g
 	return retTargets, retErrs
 }
 
-func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (conversionResults, []error) {
+// GenerateBazelTargets converts the modules of the given context to Bazel
+// targets. The results can be consumed through ConversionResults and the
+// read-only accessors of BazelTarget.
+func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (ConversionResults, []error) {
 	ctx.Context().BeginEvent("GenerateBazelTargets")
 	defer ctx.Context().EndEvent("GenerateBazelTargets")
 	buildFileToTargets := make(map[string]BazelTargets)
@@ -709,6 +834,10 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 
 	dirs := make(map[string]bool)
 	moduleNameToPartition := make(map[string]string)
+	// The Blueprint file each generated target comes from, keyed by target label.
+	targetToBlueprintFile := make(map[string]string)
+	// The targets added to their package only if their name is free, see addOptionalTargets.
+	var optionalTargets []BazelTarget
 
 	var errs []error
 
@@ -719,13 +848,21 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 	ndkHeaders := meta.ndkHeaders
 
 	bpCtx := ctx.Context()
+	var modules []blueprint.Module
 	bpCtx.VisitAllModules(func(m blueprint.Module) {
+		modules = append(modules, m)
+	})
+	// Generating the targets of a module only depends on that module, so it is
+	// done concurrently, while the results are gathered in the order the modules
+	// were visited in so that the output is deterministic.
+	generated := generateModulesTargets(ctx, modules, nameToGoLibMap)
+
+	for i, m := range modules {
 		dir := bpCtx.ModuleDir(m)
 		moduleType := bpCtx.ModuleType(m)
 		dirs[dir] = true
 
-		var targets []BazelTarget
-		var targetErrs []error
+		targets, targetErrs := generated[i].targets, generated[i].errs
 
 		switch ctx.Mode() {
 		case Bp2Build:
@@ -741,14 +878,13 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 					// Log the module isn't to be converted by bp2build.
 					// TODO: b/291598248 - Log handcrafted modules differently than other unconverted modules.
 					metrics.AddUnconvertedModule(m, moduleType, dir, *reason)
-					return
+					continue
 				}
 				if len(aModule.Bp2buildTargets()) == 0 {
 					panic(fmt.Errorf("illegal bp2build invariant: module '%s' was neither converted nor marked unconvertible", aModule.Name()))
 				}
 
 				// Handle modules converted to generated targets.
-				targets, targetErrs = generateBazelTargets(bpCtx, aModule)
 				errs = append(errs, targetErrs...)
 				for _, t := range targets {
 					// A module can potentially generate more than 1 Bazel
@@ -761,6 +897,10 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 
 				// Log the module.
 				metrics.AddConvertedModule(aModule, moduleType, dir)
+				for _, warning := range aModule.GetBp2buildWarnings() {
+					msg := fmt.Sprintf("%s %s:%s: %s", moduleType, bpCtx.ModuleDir(m), m.Name(), warning)
+					metrics.moduleWithWarningsMsgs = append(metrics.moduleWithWarningsMsgs, msg)
+				}
 
 				// Handle modules with unconverted deps. By default, emit a warning.
 				if unconvertedDeps := aModule.GetUnconvertedBp2buildDeps(); len(unconvertedDeps) > 0 {
@@ -771,7 +911,7 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 						metrics.moduleWithUnconvertedDepsMsgs = append(metrics.moduleWithUnconvertedDepsMsgs, msg)
 					case errorModulesUnconvertedDeps:
 						errs = append(errs, fmt.Errorf(msg))
-						return
+						continue
 					}
 				}
 				if unconvertedDeps := aModule.GetMissingBp2buildDeps(); len(unconvertedDeps) > 0 {
@@ -782,16 +922,14 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 						metrics.moduleWithMissingDepsMsgs = append(metrics.moduleWithMissingDepsMsgs, msg)
 					case errorModulesUnconvertedDeps:
 						errs = append(errs, fmt.Errorf(msg))
-						return
+						continue
 					}
 				}
 			} else if glib, ok := m.(*bootstrap.GoPackage); ok {
-				targets, targetErrs = generateBazelTargetsGoPackage(bpCtx, glib, nameToGoLibMap)
 				errs = append(errs, targetErrs...)
 				metrics.IncrementRuleClassCount("bootstrap_go_package")
 				metrics.AddConvertedModule(glib, "bootstrap_go_package", dir)
 			} else if gbin, ok := m.(*bootstrap.GoBinary); ok {
-				targets, targetErrs = generateBazelTargetsGoBinary(bpCtx, gbin, nameToGoLibMap)
 				errs = append(errs, targetErrs...)
 				metrics.IncrementRuleClassCount("blueprint_go_binary")
 				metrics.AddConvertedModule(gbin, "blueprint_go_binary", dir)
@@ -799,30 +937,39 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 				metrics.AddUnconvertedModule(m, moduleType, dir, android.UnconvertedReason{
 					ReasonType: int(bp2build_metrics_proto.UnconvertedReasonType_TYPE_UNSUPPORTED),
 				})
-				return
+				continue
 			}
 		case QueryView:
 			// Blocklist certain module types from being generated.
 			if canonicalizeModuleType(bpCtx.ModuleType(m)) == "package" {
 				// package module name contain slashes, and thus cannot
 				// be mapped cleanly to a bazel label.
-				return
-			}
-			t, err := generateSoongModuleTarget(bpCtx, m)
-			if err != nil {
-				errs = append(errs, err)
+				continue
 			}
-			targets = append(targets, t)
+			errs = append(errs, targetErrs...)
 		default:
 			errs = append(errs, fmt.Errorf("Unknown code-generation mode: %s", ctx.Mode()))
-			return
+			continue
 		}
 
+		bpFile := bpCtx.BlueprintFile(m)
 		for _, target := range targets {
+			if target.optional {
+				optionalTargets = append(optionalTargets, target)
+				continue
+			}
+			// A package can be made of several Blueprint files, whose targets are merged into a
+			// single BUILD file. Report targets with conflicting names rather than emitting both.
+			if otherBpFile, exists := targetToBlueprintFile[target.Label()]; exists && otherBpFile != bpFile {
+				errs = append(errs, fmt.Errorf("%s: module %q generates target %s, which is already generated from %s",
+					bpFile, m.Name(), target.Label(), otherBpFile))
+				continue
+			}
+			targetToBlueprintFile[target.Label()] = bpFile
 			targetDir := target.PackageName()
 			buildFileToTargets[targetDir] = append(buildFileToTargets[targetDir], target)
 		}
-	})
+	}
 
 	// Create an ndk_sysroot target that has a dependency edge on every target corresponding to Soong's ndk_headers
 	// This root target will provide headers to sdk variants of jni libraries
@@ -856,7 +1003,7 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 	}
 
 	if len(errs) > 0 {
-		return conversionResults{}, errs
+		return ConversionResults{}, errs
 	}
 
 	if generateFilegroups {
@@ -880,22 +1027,135 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 		}
 	}
 
-	return conversionResults{
+	addOptionalTargets(ctx.Config(), buildFileToTargets, optionalTargets)
+
+	return ConversionResults{
 		buildFileToTargets:    buildFileToTargets,
 		moduleNameToPartition: moduleNameToPartition,
 		metrics:               metrics,
 	}, errs
 }
 
+// addOptionalTargets adds the given optional targets to their package, except for those
+// whose name is the name of another target of the package, either generated or existing in
+// the source tree, or the name of another optional target. This is done once all the other
+// targets are known, so that the result doesn't depend on the order of the modules.
+func addOptionalTargets(config android.Config, buildFileToTargets map[string]BazelTargets, optionalTargets []BazelTarget) {
+	claims := make(map[string]int, len(optionalTargets))
+	for _, t := range optionalTargets {
+		claims[t.Label()]++
+	}
+	for _, t := range optionalTargets {
+		dir := t.PackageName()
+		if claims[t.Label()] > 1 || config.HasBazelBuildTargetInSource(dir, t.name) {
+			continue
+		}
+		taken := false
+		for _, other := range buildFileToTargets[dir] {
+			if other.name == t.name {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			buildFileToTargets[dir] = append(buildFileToTargets[dir], t)
+		}
+	}
+}
+
+// moduleTargets holds the targets generated for a module, and the errors
+// generating them.
+type moduleTargets struct {
+	targets []BazelTarget
+	errs    []error
+}
+
+// generateModulesTargets generates the targets of the given modules with a pool of
+// workers. The results are returned in the order of the modules.
+func generateModulesTargets(ctx *CodegenContext, modules []blueprint.Module, nameToGoLibMap nameToGoLibraryModule) []moduleTargets {
+	results := make([]moduleTargets, len(modules))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(modules) {
+		workers = len(modules)
+	}
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i].targets, results[i].errs = generateModuleTargets(ctx, modules[i], nameToGoLibMap)
+			}
+		}()
+	}
+	for i := range modules {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
+
+// generateModuleTargets generates the targets of the given module for the mode of
+// the given context. It returns no targets for modules which are not converted.
+func generateModuleTargets(ctx *CodegenContext, m blueprint.Module, nameToGoLibMap nameToGoLibraryModule) ([]BazelTarget, []error) {
+	bpCtx := ctx.Context()
+	switch ctx.Mode() {
+	case Bp2Build:
+		if aModule, ok := m.(android.Module); ok {
+			if aModule.GetUnconvertedReason() != nil {
+				return nil, nil
+			}
+			return generateBazelTargets(bpCtx, aModule)
+		} else if glib, ok := m.(*bootstrap.GoPackage); ok {
+			return generateBazelTargetsGoPackage(bpCtx, glib, nameToGoLibMap)
+		} else if gbin, ok := m.(*bootstrap.GoBinary); ok {
+			return generateBazelTargetsGoBinary(bpCtx, gbin, nameToGoLibMap)
+		}
+	case QueryView:
+		if canonicalizeModuleType(bpCtx.ModuleType(m)) == "package" {
+			return nil, nil
+		}
+		t, err := generateSoongModuleTarget(bpCtx, m)
+		if err != nil {
+			return []BazelTarget{t}, []error{err}
+		}
+		return []BazelTarget{t}, nil
+	}
+	return nil, nil
+}
+
 func generateBazelTargets(ctx bpToBuildContext, m android.Module) ([]BazelTarget, []error) {
 	var targets []BazelTarget
 	var errs []error
-	for _, m := range m.Bp2buildTargets() {
-		target, err := generateBazelTarget(ctx, m)
+	moduleType := ctx.ModuleType(m)
+	var defaults []defaultsModule
+	var defaultsProperties map[defaultsModule]map[string]bool
+	ctx.VisitDirectDeps(m, func(dep blueprint.Module) {
+		if _, ok := dep.(android.Defaults); ok {
+			d := defaultsModule{name: ctx.ModuleName(dep), dir: ctx.ModuleDir(dep)}
+			defaults = append(defaults, d)
+			if defaultsProperties == nil {
+				defaultsProperties = make(map[defaultsModule]map[string]bool)
+			}
+			if depModule, ok := dep.(android.Module); ok {
+				defaultsProperties[d] = setPropertyNames(depModule.GetProperties())
+			}
+		}
+	})
+	for _, t := range m.Bp2buildTargets() {
+		target, err := generateBazelTarget(ctx, t)
 		if err != nil {
 			errs = append(errs, err)
 			return targets, errs
 		}
+		target.soongModuleName = ctx.ModuleName(m)
+		target.soongModuleDir = ctx.ModuleDir(m)
+		target.soongModuleType = moduleType
+		target.defaults = defaults
+		target.defaultsProperties = defaultsProperties
+		target.optional = t.Optional
 		targets = append(targets, target)
 	}
 	return targets, errs
@@ -923,16 +1183,20 @@ func generateBazelTarget(ctx bpToBuildContext, m bp2buildModule) (BazelTarget, e
 	// name is handled in a special manner
 	delete(props.Attrs, "name")
 
+	selectBranches, err := attributeSelectBranches(attrs)
+	if err != nil {
+		return BazelTarget{}, err
+	}
+	for name := range selectBranches {
+		if _, ok := props.Attrs[name]; !ok {
+			delete(selectBranches, name)
+		}
+	}
+
 	// Return the Bazel target with rule class and attributes, ready to be
 	// code-generated.
-	attributes := propsToAttributes(props.Attrs)
-	var content string
 	targetName := m.TargetName()
-	if targetName != "" {
-		content = fmt.Sprintf(ruleTargetTemplate, ruleClass, targetName, attributes)
-	} else {
-		content = fmt.Sprintf(unnamedRuleTargetTemplate, ruleClass, attributes)
-	}
+	content := ruleTargetContent(ruleClass, targetName, props.Attrs)
 	var loads []BazelLoad
 	if bzlLoadLocation != "" {
 		loads = append(loads, BazelLoad{
@@ -941,14 +1205,27 @@ func generateBazelTarget(ctx bpToBuildContext, m bp2buildModule) (BazelTarget, e
 		})
 	}
 	return BazelTarget{
-		name:        targetName,
-		packageName: m.TargetPackage(),
-		ruleClass:   ruleClass,
-		loads:       loads,
-		content:     content,
+		name:           targetName,
+		packageName:    m.TargetPackage(),
+		ruleClass:      ruleClass,
+		loads:          loads,
+		content:        content,
+		attributes:     props.Attrs,
+		selectBranches: selectBranches,
+		labels:         attributeLabels(attrs),
 	}, nil
 }
 
+// ruleTargetContent renders a rule target with the given rule class, name and
+// attributes. An empty name renders an unnamed rule target.
+func ruleTargetContent(ruleClass, targetName string, attrs map[string]string) string {
+	attributes := propsToAttributes(attrs)
+	if targetName != "" {
+		return fmt.Sprintf(ruleTargetTemplate, ruleClass, targetName, attributes)
+	}
+	return fmt.Sprintf(unnamedRuleTargetTemplate, ruleClass, attributes)
+}
+
 // Convert a module and its deps and props into a Bazel macro/rule
 // representation in the BUILD file.
 func generateSoongModuleTarget(ctx bpToBuildContext, m blueprint.Module) (BazelTarget, error) {
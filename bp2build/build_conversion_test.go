@@ -951,6 +951,48 @@ load("//build/bazel/rules:rules.bzl", "my_library")`,
 	}
 }
 
+func TestBazelTargetAccessors(t *testing.T) {
+	bp := `custom {
+    name: "foo",
+    host_supported: true,
+    string_literal_prop: "PROP",
+    string_list_prop: ["a", "b"],
+    bazel_module: { bp2build_available: true },
+}`
+	config := android.TestConfig(buildDir, nil, bp, nil)
+	ctx := android.NewTestContext(config)
+	ctx.RegisterModuleType("custom", customModuleFactoryHostAndDevice)
+	ctx.RegisterForBazelConversion()
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	android.FailIfErrored(t, errs)
+	_, errs = ctx.ResolveDependencies(config)
+	android.FailIfErrored(t, errs)
+
+	codegenCtx := NewCodegenContext(config, ctx.Context, Bp2Build, "")
+	res, errs := GenerateBazelTargets(codegenCtx, false)
+	android.FailIfErrored(t, errs)
+
+	targets := res.BuildDirToTargets()["."]
+	if len(targets) != 1 {
+		t.Fatalf("Expected 1 bazel target, got %d", len(targets))
+	}
+	target := targets[0]
+	android.AssertStringEquals(t, "Name", "foo", target.Name())
+	android.AssertStringEquals(t, "RuleClass", "custom", target.RuleClass())
+	android.AssertStringEquals(t, "Label", "//:foo", target.Label())
+	android.AssertStringEquals(t, "SoongModuleName", "foo", target.SoongModuleName())
+	android.AssertStringEquals(t, "SoongModuleType", "custom", target.SoongModuleType())
+	android.AssertDeepEquals(t, "AttributeNames", []string{"string_list_prop", "string_literal_prop"}, target.AttributeNames())
+	value, ok := target.Attribute("string_literal_prop")
+	android.AssertBoolEquals(t, "has string_literal_prop", true, ok)
+	android.AssertStringEquals(t, "string_literal_prop", `"PROP"`, value)
+	if _, ok := target.Attribute("string_ptr_prop"); ok {
+		t.Errorf("Expected string_ptr_prop not to be set")
+	}
+	android.AssertStringDoesContain(t, "Content", target.Content(), `string_literal_prop = "PROP"`)
+}
+
 func TestModuleTypeBp2Build(t *testing.T) {
 	testCases := []Bp2buildTestCase{
 		{
@@ -1904,6 +1946,162 @@ func TestPrettyPrintSelectMapEqualValues(t *testing.T) {
 	android.AssertStringEquals(t, "Print the common value if all keys in an axis have the same value", `[":libfoo.impl"]`, actual)
 }
 
+// Selects whose branches all equal //conditions:default are merged into the plain value
+func TestPrettyPrintCollapsesSelectsWithEqualBranches(t *testing.T) {
+	sla := bazel.StringListAttribute{
+		Value: []string{"a"},
+	}
+	sla.SetSelectValue(bazel.ArchConfigurationAxis, "arm", []string{"b"})
+	sla.SetSelectValue(bazel.ArchConfigurationAxis, "x86", []string{"b"})
+	sla.SetSelectValue(bazel.ArchConfigurationAxis, bazel.ConditionsDefaultConfigKey, []string{"b"})
+	sla.SetSelectValue(bazel.OsConfigurationAxis, "android", []string{"c"})
+	actual, _ := prettyPrintAttribute(sla, 0)
+	android.AssertStringEquals(t, "Merge a select with equal branches into the list", `[
+    "a",
+    "b",
+] + select({
+    "//build/bazel_common_rules/platforms/os:android": ["c"],
+    "//conditions:default": [],
+})`, actual)
+
+	prependedSla := bazel.StringListAttribute{
+		Value:   []string{"a"},
+		Prepend: true,
+	}
+	prependedSla.SetSelectValue(bazel.OsConfigurationAxis, "android", []string{"b"})
+	prependedSla.SetSelectValue(bazel.OsConfigurationAxis, bazel.ConditionsDefaultConfigKey, []string{"b"})
+	actual, _ = prettyPrintAttribute(prependedSla, 0)
+	android.AssertStringEquals(t, "Merge a prepended select with equal branches into the list", `[
+    "b",
+    "a",
+]`, actual)
+
+	orderedSla := bazel.StringListAttribute{
+		Value: []string{"a"},
+	}
+	orderedSla.SetSelectValue(bazel.ArchConfigurationAxis, "arm", []string{"b"})
+	orderedSla.SetSelectValue(bazel.OsConfigurationAxis, "android", []string{"c"})
+	orderedSla.SetSelectValue(bazel.OsConfigurationAxis, bazel.ConditionsDefaultConfigKey, []string{"c"})
+	actual, _ = prettyPrintAttribute(orderedSla, 0)
+	android.AssertStringEquals(t, "Keep a select with equal branches after a select that is kept", `["a"] + select({
+    "//build/bazel_common_rules/platforms/arch:arm": ["b"],
+    "//conditions:default": [],
+}) + select({
+    "//build/bazel_common_rules/platforms/os:android": ["c"],
+    "//conditions:default": ["c"],
+})`, actual)
+
+	value := "foo"
+	sa := bazel.StringAttribute{
+		Value: &value,
+	}
+	sa.SetSelectValue(bazel.ArchConfigurationAxis, "arm", &value)
+	sa.SetSelectValue(bazel.ArchConfigurationAxis, "x86", &value)
+	actual, _ = prettyPrintAttribute(sa, 0)
+	android.AssertStringEquals(t, "Print the common value of a string select with equal branches", `"foo"`, actual)
+}
+
+func TestRegisteredAttributeConverters(t *testing.T) {
+	type int64Attrs struct {
+		Int64_attr *int64
+	}
+	type nestedAttrs struct {
+		Nested_attr string
+	}
+	registerCustomModule := func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("custom", customModuleFactoryHostAndDevice)
+		ctx.RegisterBp2buildAttributeConverter("custom", "int64_ptr_prop", func(ruleClass string, values android.Bp2buildPropertyValues) interface{} {
+			return &int64Attrs{Int64_attr: values[bazel.NoConfigAxis][""].(*int64)}
+		})
+		ctx.RegisterBp2buildAttributeConverter("custom", "nested_props.nested_prop", func(ruleClass string, values android.Bp2buildPropertyValues) interface{} {
+			if ruleClass != "custom" {
+				return nil
+			}
+			return &nestedAttrs{Nested_attr: ruleClass + ":" + *values[bazel.NoConfigAxis][""].(*string)}
+		})
+	}
+	RunBp2BuildTestCase(t, registerCustomModule, Bp2buildTestCase{
+		Description: "registered attribute converters add attributes to the generated targets",
+		Blueprint: `custom {
+    name: "foo",
+    int64_ptr_prop: 42,
+    nested_props: {
+        nested_prop: "bar",
+    },
+}
+
+custom {
+    name: "baz",
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("custom", "baz", AttrNameToString{}),
+			MakeBazelTarget("custom", "foo", AttrNameToString{
+				"int64_attr":  "42",
+				"nested_attr": `"custom:bar"`,
+			}),
+		},
+	})
+}
+
+func TestRegisteredAttributeConverterArchVariant(t *testing.T) {
+	type stringAttrs struct {
+		Converted_string_attr bazel.StringAttribute
+	}
+	registerCustomModule := func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("custom", customModuleFactoryHostAndDevice)
+		ctx.RegisterBp2buildAttributeConverter("custom", "string_literal_prop", func(ruleClass string, values android.Bp2buildPropertyValues) interface{} {
+			attrs := &stringAttrs{}
+			for axis, configToValue := range values {
+				for config, value := range configToValue {
+					attrs.Converted_string_attr.SetSelectValue(axis, config, value.(*string))
+				}
+			}
+			return attrs
+		})
+	}
+	RunBp2BuildTestCase(t, registerCustomModule, Bp2buildTestCase{
+		Description: "registered attribute converters convert arch variant properties",
+		Blueprint: `custom {
+    name: "foo",
+    arch: {
+        arm64: { string_literal_prop: "ARM64" },
+    },
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("custom", "foo", AttrNameToString{
+				"converted_string_attr": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": "ARM64",
+        "//conditions:default": None,
+    })`,
+				"string_literal_prop": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": "ARM64",
+        "//conditions:default": None,
+    })`,
+			}),
+		},
+	})
+}
+
+func TestRegisteredAttributeConverterDuplicateAttribute(t *testing.T) {
+	type stringListAttrs struct {
+		String_list_prop []string
+	}
+	registerCustomModule := func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("custom", customModuleFactoryHostAndDevice)
+		ctx.RegisterBp2buildAttributeConverter("custom", "string_list_prop", func(ruleClass string, values android.Bp2buildPropertyValues) interface{} {
+			return &stringListAttrs{String_list_prop: values[bazel.NoConfigAxis][""].([]string)}
+		})
+	}
+	RunBp2BuildTestCase(t, registerCustomModule, Bp2buildTestCase{
+		Description: "attributes converted by registered converters must not duplicate attributes of the target",
+		Blueprint: `custom {
+    name: "foo",
+    string_list_prop: ["a"],
+}`,
+		ExpectedErr: fmt.Errorf(`string_list_prop (["a"]) is present in properties`),
+	})
+}
+
 func TestAlreadyPresentBuildTarget(t *testing.T) {
 	bp := `
 	custom {
@@ -2045,6 +2243,32 @@ func TestCreateBazelTargetInDifferentDir(t *testing.T) {
 
 }
 
+func TestConflictingTargetsFromDifferentBlueprintFiles(t *testing.T) {
+	t.Parallel()
+	bp := `
+	custom {
+		name: "foo_config_setting",
+		dir: "subdir",
+	}
+	`
+	registerCustomModule := func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("custom", customModuleFactoryHostAndDevice)
+	}
+	RunBp2BuildTestCase(t, registerCustomModule, Bp2buildTestCase{
+		Description: "targets with the same label generated from different Blueprint files are reported",
+		Blueprint:   bp,
+		Filesystem: map[string]string{
+			"subdir/Android.bp": `
+custom {
+	name: "foo",
+	test_config_setting: true,
+}`,
+		},
+		Dir:         "subdir",
+		ExpectedErr: fmt.Errorf("generates target //subdir:foo_config_setting, which is already generated from"),
+	})
+}
+
 func TestBp2buildDepsMutator_missingTransitiveDep(t *testing.T) {
 	bp := `
 	custom {
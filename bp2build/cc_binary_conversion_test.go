@@ -205,6 +205,49 @@ func TestCcBinaryWithLinkStatic(t *testing.T) {
 	})
 }
 
+func TestCcBinaryWithArchSpecificLinkStatic(t *testing.T) {
+	RunBp2BuildTestCase(t, registerCcBinaryModuleTypes, Bp2buildTestCase{
+		Description:                "cc_binary arch specific link static",
+		ModuleTypeUnderTest:        "cc_binary",
+		ModuleTypeUnderTestFactory: cc.BinaryFactory,
+		Blueprint: `
+cc_binary {
+    name: "foo",
+    arch: {
+        arm64: {
+            static_executable: true,
+        },
+    },
+    include_build_directory: false,
+}
+`,
+		ExpectedErr: fmt.Errorf("bp2build cannot migrate a module with arch/target-specific static_executable values"),
+	})
+}
+
+func TestCcBinaryWithDarwinAndWindowsLinkStatic(t *testing.T) {
+	runCcBinaryTests(t, ccBinaryBp2buildTestCase{
+		description: "link static on darwin and windows, which ignore it",
+		blueprint: `
+{rule_name} {
+    name: "foo",
+    target: {
+        darwin: {
+            static_executable: true,
+        },
+        windows: {
+            static_executable: true,
+        },
+    },
+    include_build_directory: false,
+}
+`,
+		targets: []testBazelTarget{
+			{"cc_binary", "foo", AttrNameToString{}},
+		},
+	})
+}
+
 func TestCcBinaryVersionScriptAndDynamicList(t *testing.T) {
 	runCcBinaryTests(t, ccBinaryBp2buildTestCase{
 		description: `version script and dynamic list`,
@@ -233,6 +276,48 @@ func TestCcBinaryVersionScriptAndDynamicList(t *testing.T) {
 	})
 }
 
+func TestCcBinaryStaticNocrtWithLinkerScripts(t *testing.T) {
+	runCcBinaryTests(t, ccBinaryBp2buildTestCase{
+		description: "static executable without crt objects linked with linker scripts",
+		blueprint: `
+{rule_name} {
+    name: "foo",
+    srcs: ["start.S"],
+    static_executable: true,
+    nocrt: true,
+    linker_scripts: ["common.ld"],
+    arch: {
+        arm64: {
+            linker_scripts: ["arm64.ld"],
+        },
+        x86_64: {
+            linker_scripts: ["x86_64.ld"],
+        },
+    },
+    include_build_directory: false,
+}
+`,
+		targets: []testBazelTarget{
+			{"cc_binary", "foo", AttrNameToString{
+				"additional_linker_inputs": `["common.ld"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["arm64.ld"],
+        "//build/bazel_common_rules/platforms/arch:x86_64": ["x86_64.ld"],
+        "//conditions:default": [],
+    })`,
+				"features": `["-link_crt"]`,
+				"linkopts": `["-Wl,--script,$(location common.ld)"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-Wl,--script,$(location arm64.ld)"],
+        "//build/bazel_common_rules/platforms/arch:x86_64": ["-Wl,--script,$(location x86_64.ld)"],
+        "//conditions:default": [],
+    })`,
+				"linkshared": `False`,
+				"srcs_as":    `["start.S"]`,
+			},
+			},
+		},
+	})
+}
+
 func TestCcBinaryLdflagsSplitBySpaceExceptSoongAdded(t *testing.T) {
 	runCcBinaryTests(t, ccBinaryBp2buildTestCase{
 		description: "ldflags are split by spaces except for the ones added by soong (version script and dynamic list)",
@@ -704,8 +789,9 @@ func TestCcArchVariantBinarySuffix(t *testing.T) {
 {rule_name} {
     name: "foo",
     arch: {
-        arm64: { suffix: "-64" },
-        arm:   { suffix: "-32" },
+        arm64:   { suffix: "-64" },
+        arm:     { suffix: "-32" },
+        riscv64: { suffix: "-rv64" },
 		},
 }
 `,
@@ -715,6 +801,7 @@ func TestCcArchVariantBinarySuffix(t *testing.T) {
 				"suffix": `select({
         "//build/bazel_common_rules/platforms/arch:arm": "-32",
         "//build/bazel_common_rules/platforms/arch:arm64": "-64",
+        "//build/bazel_common_rules/platforms/arch:riscv64": "-rv64",
         "//conditions:default": None,
     })`,
 			}},
@@ -837,6 +924,104 @@ func TestCcBinaryWithMiscUndefinedProperty(t *testing.T) {
 	})
 }
 
+func TestCcBinaryWithMemtagProperties(t *testing.T) {
+	runCcBinaryTestCase(t, ccBinaryBp2buildTestCase{
+		description: "cc_binary with memtag_heap and memtag_stack properties specified",
+		blueprint: `
+{rule_name} {
+	name: "foo",
+	sanitize: {
+		memtag_heap: true,
+		memtag_stack: true,
+		diag: {
+			memtag_heap: true,
+		},
+	},
+}`,
+		targets: []testBazelTarget{
+			{"cc_binary", "foo", AttrNameToString{
+				"local_includes": `["."]`,
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": [
+            "memtag_heap",
+            "memtag_stack",
+            "diag_memtag_heap",
+        ],
+        "//conditions:default": [],
+    })`,
+			}},
+		},
+	})
+}
+
+func TestCcBinaryWithMemtagHeapAndArchSpecificMemtagStack(t *testing.T) {
+	runCcBinaryTestCase(t, ccBinaryBp2buildTestCase{
+		description: "cc_binary with memtag_heap and an android_arm64 specific memtag_stack",
+		blueprint: `
+{rule_name} {
+	name: "foo",
+	sanitize: {
+		memtag_heap: true,
+		diag: {
+			memtag_heap: true,
+		},
+	},
+	target: {
+		android_arm64: {
+			sanitize: {
+				memtag_stack: true,
+				diag: {
+					memtag_heap: false,
+				},
+			},
+		},
+	},
+}`,
+		targets: []testBazelTarget{
+			{"cc_binary", "foo", AttrNameToString{
+				"local_includes": `["."]`,
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": [
+            "memtag_heap",
+            "memtag_stack",
+            "-diag_memtag_heap",
+        ],
+        "//conditions:default": [],
+    })`,
+			}},
+		},
+	})
+}
+
+func TestCcBinaryWithMemtagStackOnly(t *testing.T) {
+	runCcBinaryTestCase(t, ccBinaryBp2buildTestCase{
+		description: "cc_binary with only memtag_stack property specified",
+		blueprint: `
+{rule_name} {
+	name: "foo",
+	sanitize: {
+		memtag_stack: false,
+	},
+	target: {
+		android_arm64: {
+			sanitize: {
+				memtag_stack: true,
+			},
+		},
+	},
+}`,
+		targets: []testBazelTarget{
+			{"cc_binary", "foo", AttrNameToString{
+				"local_includes": `["."]`,
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": ["memtag_stack"],
+        "//conditions:default": [],
+    })`,
+			}},
+		},
+	})
+}
+
 func TestCcBinaryWithUBSanPropertiesArchSpecific(t *testing.T) {
 	runCcBinaryTestCase(t, ccBinaryBp2buildTestCase{
 		description: "cc_binary has correct feature select when UBSan props are specified in arch specific blocks",
@@ -1307,3 +1492,37 @@ cc_binary {
 		},
 	})
 }
+
+func TestCcBinaryArchVariantRequired(t *testing.T) {
+	runCcBinaryTestCase(t, ccBinaryBp2buildTestCase{
+		description:             "with arch variant required",
+		stubbedBuildDefinitions: []string{"bar", "baz"},
+		blueprint: `
+{rule_name} {
+    name: "foo",
+    required: ["bar", "foo"],
+    arch: {
+        arm64: {
+            required: ["baz"],
+        },
+    },
+    include_build_directory: false,
+}
+cc_library_static {
+    name: "bar",
+}
+cc_library_static {
+    name: "baz",
+}
+`,
+		targets: []testBazelTarget{
+			{"cc_binary", "foo", AttrNameToString{
+				"required": `[":bar"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":baz"],
+        "//conditions:default": [],
+    })`,
+			},
+			},
+		},
+	})
+}
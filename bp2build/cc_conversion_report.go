@@ -0,0 +1,79 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"encoding/json"
+	"sort"
+
+	"android/soong/android"
+)
+
+// ccConversionReportFileName is the name of the file, in the metrics package of
+// the soong_injection directory, reporting the progress of the conversion of cc
+// modules.
+const ccConversionReportFileName = "cc_conversion_report.json"
+
+// unconvertedModule describes a module which was not converted by bp2build.
+type unconvertedModule struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Reason is the kind of reason the module was not converted for, e.g.
+	// "PROPERTY_UNSUPPORTED".
+	Reason string `json:"reason"`
+	// Detail describes what blocked the conversion, e.g. the unsupported property.
+	Detail string `json:"detail,omitempty"`
+}
+
+// ccConversionReport lists the cc modules converted by bp2build, and the cc modules
+// which were not converted along with the reason they were not.
+type ccConversionReport struct {
+	Converted   []moduleInfo        `json:"converted"`
+	Unconverted []unconvertedModule `json:"unconverted"`
+}
+
+// ccConversionReportFile returns a JSON file reporting which cc modules were
+// converted by bp2build, and which were not and why.
+func ccConversionReportFile(metrics CodegenMetrics) (BazelFile, error) {
+	report := ccConversionReport{
+		Converted:   []moduleInfo{},
+		Unconverted: []unconvertedModule{},
+	}
+	for _, module := range metrics.convertedModuleWithType {
+		if metrics.ccModules[module.Name] {
+			report.Converted = append(report.Converted, module)
+		}
+	}
+	sort.Slice(report.Converted, func(i, j int) bool {
+		return report.Converted[i].Name < report.Converted[j].Name
+	})
+	for _, name := range android.SortedKeys(metrics.serialized.UnconvertedModules) {
+		if !metrics.ccModules[name] {
+			continue
+		}
+		reason := metrics.serialized.UnconvertedModules[name]
+		report.Unconverted = append(report.Unconverted, unconvertedModule{
+			Name:   name,
+			Type:   metrics.unconvertedModuleTypes[name],
+			Reason: reason.Type.String(),
+			Detail: reason.Detail,
+		})
+	}
+	contents, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return BazelFile{}, err
+	}
+	return newFile("metrics", ccConversionReportFileName, string(contents)+"\n"), nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/ui/metrics/bp2build_metrics_proto"
+)
+
+func TestCcConversionReportFile(t *testing.T) {
+	metrics := CreateCodegenMetrics()
+	metrics.convertedModuleWithType = []moduleInfo{
+		{Name: "libfoo", Type: "cc_library"},
+		{Name: "bar_java", Type: "java_library"},
+		{Name: "libbar", Type: "cc_library_static"},
+		{Name: "gen", Type: "cc_genrule"},
+		{Name: "libndk", Type: "ndk_library"},
+	}
+	// cc_genrule modules are genrule modules, and ndk_library modules cc modules.
+	for _, name := range []string{"libfoo", "libbar", "libndk", "libbaz", "libhandmade"} {
+		metrics.ccModules[name] = true
+	}
+	for name, module := range map[string]struct {
+		moduleType string
+		reason     bp2build_metrics_proto.UnconvertedReasonType
+		detail     string
+	}{
+		"libbaz":      {"cc_library_shared", bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "sanitize.scs"},
+		"libhandmade": {"cc_library", bp2build_metrics_proto.UnconvertedReasonType_DEFINED_IN_BUILD_FILE, ""},
+		"baz_java":    {"java_library", bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "javacflags"},
+	} {
+		metrics.serialized.UnconvertedModules[name] = &bp2build_metrics_proto.UnconvertedReason{
+			Type:   module.reason,
+			Detail: module.detail,
+		}
+		metrics.unconvertedModuleTypes[name] = module.moduleType
+	}
+
+	file, err := ccConversionReportFile(metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Dir != "metrics" || file.Basename != ccConversionReportFileName {
+		t.Errorf("Unexpected cc conversion report file %s/%s", file.Dir, file.Basename)
+	}
+	expected := `{
+  "converted": [
+    {
+      "name": "libbar",
+      "type": "cc_library_static"
+    },
+    {
+      "name": "libfoo",
+      "type": "cc_library"
+    },
+    {
+      "name": "libndk",
+      "type": "ndk_library"
+    }
+  ],
+  "unconverted": [
+    {
+      "name": "libbaz",
+      "type": "cc_library_shared",
+      "reason": "PROPERTY_UNSUPPORTED",
+      "detail": "sanitize.scs"
+    },
+    {
+      "name": "libhandmade",
+      "type": "cc_library",
+      "reason": "DEFINED_IN_BUILD_FILE"
+    }
+  ]
+}
+`
+	if file.Contents != expected {
+		t.Errorf("Expected cc conversion report:\n%s\ngot:\n%s", expected, file.Contents)
+	}
+}
@@ -1410,6 +1410,41 @@ cc_library {
 	})
 }
 
+func TestCCLibraryNoCrtReenabledInArchVariant(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library - nocrt re-enabled in an arch variant",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Filesystem: map[string]string{
+			"impl.cpp": "",
+		},
+		Blueprint: soongCcLibraryPreamble + `
+cc_library {
+    name: "foo-lib",
+    srcs: ["impl.cpp"],
+    nocrt: true,
+    no_libcrt: true,
+    arch: {
+        x86: {
+            nocrt: false,
+        },
+    },
+    include_build_directory: false,
+}
+`,
+		ExpectedBazelTargets: makeCcLibraryTargets("foo-lib", AttrNameToString{
+			"features": `["-use_libcrt"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["-link_crt"],
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-link_crt"],
+        "//build/bazel_common_rules/platforms/arch:riscv64": ["-link_crt"],
+        "//build/bazel_common_rules/platforms/arch:x86_64": ["-link_crt"],
+        "//conditions:default": [],
+    })`,
+			"srcs": `["impl.cpp"]`,
+		}),
+	})
+}
+
 func TestCCLibraryNoLibCrtTrue(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		ModuleTypeUnderTest:        "cc_library",
@@ -2229,6 +2264,56 @@ func TestCcLibraryProtoSimple(t *testing.T) {
 	})
 }
 
+func TestCcLibraryProtoCcDeps(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library passes its exported and implementation deps to the cc_lite_proto_library",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		StubbedBuildDefinitions:    []string{"bar", "baz", "qux"},
+		Blueprint: soongCcProtoPreamble + `cc_library {
+	name: "foo",
+	srcs: ["foo.proto"],
+	static_libs: ["bar"],
+	shared_libs: ["baz"],
+	whole_static_libs: ["qux"],
+	export_static_lib_headers: ["bar"],
+	export_shared_lib_headers: ["baz"],
+	include_build_directory: false,
+}` +
+			simpleModule("cc_library_static", "bar") +
+			simpleModule("cc_library", "baz") +
+			simpleModule("cc_library_static", "qux"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("proto_library", "foo_proto", AttrNameToString{
+				"srcs": `["foo.proto"]`,
+			}), MakeBazelTarget("cc_lite_proto_library", "foo_cc_proto_lite", AttrNameToString{
+				"deps": `[":foo_proto"]`,
+				"cc_deps": `[
+        ":qux",
+        ":bar",
+        ":baz",
+    ]`,
+			}), MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"deps": `[
+        ":bar",
+        ":libprotobuf-cpp-lite",
+    ]`,
+				"dynamic_deps":                      `[":baz"]`,
+				"implementation_whole_archive_deps": `[":foo_cc_proto_lite"]`,
+				"whole_archive_deps":                `[":qux"]`,
+			}), MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"deps": `[":bar"]`,
+				"dynamic_deps": `[
+        ":baz",
+        ":libprotobuf-cpp-lite",
+    ]`,
+				"implementation_whole_archive_deps": `[":foo_cc_proto_lite"]`,
+				"whole_archive_deps":                `[":qux"]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryProtoNoCanonicalPathFromRoot(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		ModuleTypeUnderTest:        "cc_library",
@@ -2466,6 +2551,26 @@ cc_library {
 	})
 }
 
+func TestCcLibraryStaticWholeStaticLibsProtoFilegroup(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library_static whole_static_libs on a filegroup of .proto files",
+		ModuleTypeUnderTest:        "cc_library_static",
+		ModuleTypeUnderTestFactory: cc.LibraryStaticFactory,
+		Blueprint: soongCcLibraryPreamble + `
+filegroup {
+	name: "a_fg_proto",
+	srcs: ["a_fg.proto"],
+}
+
+cc_library_static {
+	name: "a",
+	whole_static_libs: ["a_fg_proto"],
+	include_build_directory: false,
+}`,
+		ExpectedErr: fmt.Errorf(`"a_fg_proto" is a filegroup of .proto files, which is not a library; list it in srcs instead`),
+	})
+}
+
 func TestCcLibraryConvertedProtoFilegroupsNoProtoFiles(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		ModuleTypeUnderTest:        "cc_library",
@@ -2840,6 +2945,7 @@ func TestCcLibraryStubs(t *testing.T) {
 		ModuleTypeUnderTestFactory: cc.LibraryFactory,
 		Dir:                        "foo/bar",
 		Filesystem: map[string]string{
+			"foo/bar/a.map.txt": "",
 			"foo/bar/Android.bp": `
 cc_library {
     name: "a",
@@ -2855,6 +2961,42 @@ cc_library {
 	)
 }
 
+func TestCcLibraryStubsVersionsSortedByApiLevel(t *testing.T) {
+	expectedBazelTargets := makeCcLibraryTargets("a", AttrNameToString{
+		"stubs_symbol_file": `"a.map.txt"`,
+	})
+	expectedBazelTargets = append(expectedBazelTargets, makeCcStubSuiteTargets("a", AttrNameToString{
+		"api_surface":          `"module-libapi"`,
+		"soname":               `"a.so"`,
+		"source_library_label": `"//foo/bar:a"`,
+		"stubs_symbol_file":    `"a.map.txt"`,
+		"stubs_versions": `[
+        "29",
+        "30",
+        "current",
+    ]`,
+	}))
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library stubs versions with codenames are sorted by API level",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Dir:                        "foo/bar",
+		Filesystem: map[string]string{
+			"foo/bar/a.map.txt": "",
+			"foo/bar/Android.bp": `
+cc_library {
+    name: "a",
+    stubs: { symbol_file: "a.map.txt", versions: ["current", "R", "29"] },
+    bazel_module: { bp2build_available: true },
+    include_build_directory: false,
+}
+`,
+		},
+		Blueprint:            soongCcLibraryPreamble,
+		ExpectedBazelTargets: expectedBazelTargets,
+	},
+	)
+}
 func TestCcLibraryStubsAcrossConfigsDuplicatesRemoved(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "stub target generation of the same lib across configs should not result in duplicates",
@@ -2891,6 +3033,137 @@ cc_library {
 	})
 }
 
+func TestCcLibraryStubsForAnyApex(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "stub selection of dynamic_deps is generated for any apex when requested",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Filesystem: map[string]string{
+			"bar.map.txt": "",
+		},
+		StubbedBuildDefinitions: []string{"barlib"},
+		ExtraFixturePreparer: android.FixtureMergeEnv(map[string]string{
+			"BP2BUILD_STUBS_FOR_ANY_APEX": "true",
+		}),
+		Blueprint: `
+cc_library {
+	name: "barlib",
+	stubs: { symbol_file: "bar.map.txt", versions: ["28", "29", "current"] },
+}
+cc_library {
+	name: "foolib",
+	shared_libs: ["barlib"],
+	bazel_module: { bp2build_available: true },
+	apex_available: ["foo"],
+}`,
+		ExpectedBazelTargets: makeCcLibraryTargets("foolib", AttrNameToString{
+			"implementation_dynamic_deps": `select({
+        "//build/bazel/rules/apex:foo": ["@api_surfaces//module-libapi/current:barlib"],
+        "//build/bazel/rules/apex:system": [":barlib"],
+        "//conditions:default": ["@api_surfaces//module-libapi/current:barlib"],
+    })`,
+			"local_includes": `["."]`,
+			"tags":           `["apex_available=foo"]`,
+		}),
+	})
+}
+
+func TestCcLibraryStubsForAnyApexHostSupported(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "stub selection of dynamic_deps for any apex keeps the implementation for host",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Filesystem: map[string]string{
+			"bar.map.txt": "",
+		},
+		StubbedBuildDefinitions: []string{"barlib"},
+		ExtraFixturePreparer: android.FixtureMergeEnv(map[string]string{
+			"BP2BUILD_STUBS_FOR_ANY_APEX": "true",
+		}),
+		Blueprint: `
+cc_library {
+	name: "barlib",
+	host_supported: true,
+	stubs: { symbol_file: "bar.map.txt", versions: ["28", "29", "current"] },
+}
+cc_library {
+	name: "foolib",
+	host_supported: true,
+	shared_libs: ["barlib"],
+	bazel_module: { bp2build_available: true },
+	apex_available: ["foo"],
+}`,
+		ExpectedBazelTargets: makeCcLibraryTargets("foolib", AttrNameToString{
+			"implementation_dynamic_deps": `select({
+        "//build/bazel/rules/apex:foo": ["@api_surfaces//module-libapi/current:barlib"],
+        "//build/bazel/rules/apex:system": [":barlib"],
+        "//build/bazel_common_rules/platforms/os:darwin": [":barlib"],
+        "//build/bazel_common_rules/platforms/os:linux_bionic": [":barlib"],
+        "//build/bazel_common_rules/platforms/os:linux_glibc": [":barlib"],
+        "//build/bazel_common_rules/platforms/os:linux_musl": [":barlib"],
+        "//build/bazel_common_rules/platforms/os:windows": [":barlib"],
+        "//conditions:default": ["@api_surfaces//module-libapi/current:barlib"],
+    })`,
+			"local_includes": `["."]`,
+			"tags":           `["apex_available=foo"]`,
+		}),
+	})
+}
+
+func TestCcLibraryStaticAndSharedApexAvailable(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with apex_available in the static and shared props",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	apex_available: ["com.android.common"],
+	static: {
+		apex_available: [
+			"com.android.static",
+			"com.android.common",
+		],
+	},
+	shared: {
+		apex_available: ["com.android.shared"],
+	},
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"tags": `[
+        "apex_available=com.android.common",
+        "apex_available=com.android.static",
+    ]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"tags": `[
+        "apex_available=com.android.common",
+        "apex_available=com.android.shared",
+    ]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryMinSdkVersionApexInherit(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with min_sdk_version apex_inherit is not converted",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	apex_available: ["com.android.foo"],
+	min_sdk_version: "apex_inherit",
+	sdk_version: "current",
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
 func TestCcLibraryExcludesLibsHost(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		ModuleTypeUnderTest:        "cc_library",
@@ -2964,6 +3237,44 @@ func TestCcLibraryEscapeLdflags(t *testing.T) {
 	})
 }
 
+func TestCcLibraryOverrides(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library overrides are only set on the shared library",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: soongCcLibraryPreamble + `cc_library {
+	name: "foo",
+	overrides: ["libbar"],
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"overrides": `["libbar"]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryLdflagsWithLocationReferences(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library ldflags with $(location) references, which Soong does not expand",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		StubbedBuildDefinitions:    []string{"linker_script"},
+		Blueprint: `
+filegroup {
+	name: "linker_script",
+}
+cc_library {
+	name: "foo",
+	ldflags: ["-Wl,--script,$(location :linker_script)"],
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
 func TestCcLibraryConvertLex(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		ModuleTypeUnderTest:        "cc_library",
@@ -3038,6 +3349,45 @@ cc_library {
 	})
 }
 
+func TestCCLibraryRuntimeDepsExcludedForOs(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		StubbedBuildDefinitions: []string{"bar", "baz"},
+		Blueprint: `cc_library_shared {
+	name: "bar",
+}
+
+cc_library_shared {
+	name: "baz",
+}
+
+cc_library {
+  name: "foo",
+  runtime_libs: ["bar", "baz"],
+  target: {
+    android: {
+      exclude_runtime_libs: ["bar"],
+    },
+  },
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"runtime_deps": `[":baz"] + select({
+        "//build/bazel_common_rules/platforms/os:android": [],
+        "//conditions:default": [":bar"],
+    })`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"runtime_deps": `[":baz"] + select({
+        "//build/bazel_common_rules/platforms/os:android": [],
+        "//conditions:default": [":bar"],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryWithInstructionSet(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		ModuleTypeUnderTest:        "cc_library",
@@ -3061,7 +3411,63 @@ func TestCcLibraryWithInstructionSet(t *testing.T) {
 	})
 }
 
-func TestCcLibraryEmptySuffix(t *testing.T) {
+func TestCcLibraryWithThumbInstructionSet(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with the default thumb instruction set",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `cc_library {
+    name: "foo",
+    arch: {
+      arm: {
+        instruction_set: "thumb",
+      }
+    }
+}
+`,
+		ExpectedBazelTargets: makeCcLibraryTargets("foo", AttrNameToString{
+			"local_includes": `["."]`,
+		}),
+	})
+}
+
+func TestCcLibraryWithInstructionSetForOtherArch(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with an instruction set for riscv64",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `cc_library {
+    name: "foo",
+    arch: {
+      riscv64: {
+        instruction_set: "arm",
+      }
+    }
+}
+`,
+		ExpectedErr: fmt.Errorf(`"arm" is only supported for arm, not riscv64`),
+	})
+}
+
+func TestCcLibraryWithUnknownInstructionSet(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with an unknown instruction set",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `cc_library {
+    name: "foo",
+    arch: {
+      arm: {
+        instruction_set: "thumb2",
+      }
+    }
+}
+`,
+		ExpectedErr: fmt.Errorf(`"thumb2" is not a supported instruction set, expected "arm" or "thumb"`),
+	})
+}
+
+func TestCcLibraryEmptySuffix(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library with empty suffix",
 		ModuleTypeUnderTest:        "cc_library",
@@ -3146,6 +3552,122 @@ func TestCcLibraryArchVariantSuffix(t *testing.T) {
 	})
 }
 
+func TestCcLibraryMultilibSuffixStemAndLdflags(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with multilib suffix, stem and ldflags",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Filesystem: map[string]string{
+			"foo.c": "",
+		},
+		Blueprint: `cc_library {
+    name: "foo",
+    multilib: {
+        lib32: {
+            suffix: "-32",
+            ldflags: ["-Wl,--lib32"],
+        },
+        lib64: {
+            stem: "foo64",
+        },
+    },
+    srcs: ["foo.c"],
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"srcs_c": `["foo.c"]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"linkopts": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["-Wl,--lib32"],
+        "//build/bazel_common_rules/platforms/arch:x86": ["-Wl,--lib32"],
+        "//conditions:default": [],
+    })`,
+				"srcs_c": `["foo.c"]`,
+				"stem": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": "foo64",
+        "//build/bazel_common_rules/platforms/arch:riscv64": "foo64",
+        "//build/bazel_common_rules/platforms/arch:x86_64": "foo64",
+        "//conditions:default": None,
+    })`,
+				"suffix": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": "-32",
+        "//build/bazel_common_rules/platforms/arch:x86": "-32",
+        "//conditions:default": None,
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryWrapLdflags(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library exports --wrap ldflags to the dependents of its static library",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		ExtraFixturePreparer: android.FixtureMergeEnv(map[string]string{
+			"BP2BUILD_STATIC_LIBRARY_WRAP_LDFLAGS": "true",
+		}),
+		Filesystem: map[string]string{
+			"foo.c": "",
+		},
+		Blueprint: `cc_library {
+    name: "foo",
+    srcs: ["foo.c"],
+    ldflags: [
+        "-Wl,--wrap=malloc",
+        "-Wl,--exclude-libs=bar.a",
+    ],
+    arch: {
+        arm64: {
+            ldflags: ["-Wl,--wrap,free"],
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"linkopts": `["-Wl,--wrap=malloc"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-Wl,--wrap,free"],
+        "//conditions:default": [],
+    })`,
+				"srcs_c": `["foo.c"]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"linkopts": `[
+        "-Wl,--wrap=malloc",
+        "-Wl,--exclude-libs=bar.a",
+    ] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-Wl,--wrap,free"],
+        "//conditions:default": [],
+    })`,
+				"srcs_c": `["foo.c"]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryDist(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with dist",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `cc_library {
+    name: "foo",
+    dist: {
+        targets: ["dist_files"],
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: append(makeCcLibraryTargets("foo", AttrNameToString{}),
+			MakeBazelTarget("copy_to_dist_dir", "foo_dist_0", AttrNameToString{
+				"data": `[":foo"]`,
+				"flat": `True`,
+			})),
+	})
+}
+
 func TestCcLibraryWithAidlLibrary(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library with aidl_library",
@@ -3237,6 +3759,61 @@ cc_library {
 	})
 }
 
+func TestCcLibraryWithAidlIncludeDirs(t *testing.T) {
+	tc := Bp2buildTestCase{
+		Description:                "cc_library with aidl.include_dirs",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	srcs: ["B.aidl"],
+	aidl: {
+		include_dirs: ["bar"],
+	},
+}`,
+		Filesystem: map[string]string{
+			"bar/Android.bp":    "",
+			"bar/IBar.aidl":     "",
+			"bar/baz/IBaz.aidl": "",
+		},
+	}
+
+	// Root dir
+	tc.ExpectedBazelTargets = []string{
+		MakeBazelTarget("aidl_library", "foo_aidl_library", AttrNameToString{
+			"srcs": `["B.aidl"]`,
+			"deps": `["//bar:bar.include_dir_bp2build_generated_aidl"]`,
+		}),
+		MakeBazelTarget("cc_aidl_library", "foo_cc_aidl_library", AttrNameToString{
+			"local_includes": `["."]`,
+			"deps":           `[":foo_aidl_library"]`,
+		}),
+		MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+			"implementation_whole_archive_deps": `[":foo_cc_aidl_library"]`,
+			"local_includes":                    `["."]`,
+		}),
+		MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+			"implementation_whole_archive_deps": `[":foo_cc_aidl_library"]`,
+			"local_includes":                    `["."]`,
+		}),
+	}
+	runCcLibraryTestCase(t, tc)
+
+	// bar dir
+	tc.Dir = "bar"
+	tc.ExpectedBazelTargets = []string{
+		MakeBazelTargetNoRestrictions("aidl_library", "bar.include_dir_bp2build_generated_aidl", AttrNameToString{
+			"hdrs": `[
+        "IBar.aidl",
+        "baz/IBaz.aidl",
+    ]`,
+			"tags": `["manual"]`,
+		}),
+	}
+	runCcLibraryTestCase(t, tc)
+}
+
 func TestCcLibraryWithNonAdjacentAidlFilegroup(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library with non aidl filegroup",
@@ -3350,6 +3927,51 @@ func TestCcLibraryWithExportAidlHeaders(t *testing.T) {
 	}
 }
 
+func TestCcLibraryWithExportAidlHeadersFromLibsAndSrcs(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with aidl.libs, aidl srcs and aidl.export_aidl_headers set",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+aidl_library {
+	name: "foo_aidl_library",
+	srcs: ["Foo.aidl"],
+}
+cc_library {
+	name: "foo",
+	srcs: ["Bar.aidl"],
+	aidl: {
+		libs: ["foo_aidl_library"],
+		export_aidl_headers: true,
+	},
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTargetNoRestrictions("aidl_library", "foo_aidl_library", AttrNameToString{
+				"srcs": `["Foo.aidl"]`,
+				"tags": `["apex_available=//apex_available:anyapex"]`,
+			}),
+			MakeBazelTarget("aidl_library", "foo_srcs_aidl_library", AttrNameToString{
+				"srcs": `["Bar.aidl"]`,
+			}),
+			MakeBazelTarget("cc_aidl_library", "foo_cc_aidl_library", AttrNameToString{
+				"local_includes": `["."]`,
+				"deps": `[
+        ":foo_aidl_library",
+        ":foo_srcs_aidl_library",
+    ]`,
+			}),
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"whole_archive_deps": `[":foo_cc_aidl_library"]`,
+				"local_includes":     `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"whole_archive_deps": `[":foo_cc_aidl_library"]`,
+				"local_includes":     `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryWithTargetApex(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library with target.apex",
@@ -3592,6 +4214,52 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticWithAidlAndExportedWholeStaticLibs(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library_static with aidl srcs passes its whole_static_libs to the cc_aidl_library",
+		ModuleTypeUnderTest:        "cc_library_static",
+		ModuleTypeUnderTestFactory: cc.LibraryStaticFactory,
+		StubbedBuildDefinitions:    []string{"bar-static", "baz-static"},
+		Blueprint: `
+cc_library_static {
+	name: "foo",
+	srcs: [
+		"Foo.aidl",
+	],
+	whole_static_libs: [
+		"bar-static",
+		"baz-static",
+	],
+	export_static_lib_headers: [
+		"baz-static",
+	],
+}` +
+			simpleModule("cc_library_static", "bar-static") +
+			simpleModule("cc_library_static", "baz-static"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("aidl_library", "foo_aidl_library", AttrNameToString{
+				"srcs": `["Foo.aidl"]`,
+			}),
+			MakeBazelTarget("cc_aidl_library", "foo_cc_aidl_library", AttrNameToString{
+				"local_includes": `["."]`,
+				"deps":           `[":foo_aidl_library"]`,
+				"implementation_deps": `[
+        ":bar-static",
+        ":baz-static",
+    ]`,
+			}),
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"implementation_whole_archive_deps": `[":foo_cc_aidl_library"]`,
+				"whole_archive_deps": `[
+        ":bar-static",
+        ":baz-static",
+    ]`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryWithTidy(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library uses tidy properties",
@@ -3645,6 +4313,99 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryWithArchAndTargetVariantTidy(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library uses arch and target specific tidy properties",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library_static {
+	name: "foo",
+	srcs: ["foo.cpp"],
+	tidy: true,
+	tidy_flags: ["-flag"],
+	arch: {
+		arm64: {
+			tidy_flags: ["-arm64-flag"],
+			tidy_checks_as_errors: ["arm64-check"],
+		},
+	},
+	target: {
+		darwin: {
+			tidy: false,
+		},
+	},
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"srcs": `["foo.cpp"]`,
+				"tidy": `select({
+        "//build/bazel_common_rules/platforms/os:darwin": "never",
+        "//conditions:default": "local",
+    })`,
+				"tidy_flags": `["-flag"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-arm64-flag"],
+        "//conditions:default": [],
+    })`,
+				"tidy_checks_as_errors": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["arm64-check"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryTidyDisabledSrcsGlobsAndLabels(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library tidy_disabled_srcs with globs and filegroup references",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		StubbedBuildDefinitions:    []string{"tidy_disabled_fg"},
+		Filesystem: map[string]string{
+			"disabled/a.cpp": "",
+			"disabled/b.cpp": "",
+		},
+		Blueprint: `
+filegroup {
+	name: "tidy_disabled_fg",
+	srcs: ["gen.cpp"],
+}
+cc_library {
+	name: "foo",
+	srcs: ["foo.cpp"],
+	tidy: true,
+	tidy_disabled_srcs: ["disabled/*.cpp", ":tidy_disabled_fg"],
+	static: {
+		tidy_timeout_srcs: ["slow.cpp"],
+	},
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"srcs": `["foo.cpp"]`,
+				"tidy": `"local"`,
+				"tidy_disabled_srcs": `[
+        "disabled/a.cpp",
+        "disabled/b.cpp",
+        ":tidy_disabled_fg",
+    ]`,
+				"tidy_timeout_srcs": `["slow.cpp"]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"srcs": `["foo.cpp"]`,
+				"tidy": `"local"`,
+				"tidy_disabled_srcs": `[
+        "disabled/a.cpp",
+        "disabled/b.cpp",
+        ":tidy_disabled_fg",
+    ]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryWithAfdoEnabled(t *testing.T) {
 	bp := `
 cc_library {
@@ -3653,7 +4414,6 @@ cc_library {
 	include_build_directory: false,
 }`
 
-	// TODO(b/260714900): Add test case for arch-specific afdo profile
 	testCases := []struct {
 		description          string
 		filesystem           map[string]string
@@ -3685,6 +4445,39 @@ cc_library {
 				}),
 			},
 		},
+		{
+			description: "cc_library with afdo enabled and arch-specific profile only",
+			filesystem: map[string]string{
+				"toolchain/pgo-profiles/sampling/Android.bp":     "",
+				"toolchain/pgo-profiles/sampling/foo-arm64.afdo": "",
+			},
+			expectedBazelTargets: []string{
+				MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{}),
+				MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+					"fdo_profile": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": "//toolchain/pgo-profiles/sampling:foo-arm64",
+        "//conditions:default": None,
+    })`,
+				}),
+			},
+		},
+		{
+			description: "cc_library with afdo enabled and arch-specific profile overriding the profile of all archs",
+			filesystem: map[string]string{
+				"toolchain/pgo-profiles/sampling/Android.bp":   "",
+				"toolchain/pgo-profiles/sampling/foo.afdo":     "",
+				"toolchain/pgo-profiles/sampling/foo-arm.afdo": "",
+			},
+			expectedBazelTargets: []string{
+				MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{}),
+				MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+					"fdo_profile": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": "//toolchain/pgo-profiles/sampling:foo-arm",
+        "//conditions:default": "//toolchain/pgo-profiles/sampling:foo",
+    })`,
+				}),
+			},
+		},
 		{
 			description: "cc_library with afdo enabled but profile filename doesn't match with module name",
 			filesystem: map[string]string{
@@ -3806,9 +4599,40 @@ cc_library {
 `,
 		ExpectedBazelTargets: makeCcLibraryTargets("a", AttrNameToString{
 			"tags": `[
+        "apex_available=//apex_available:platform",
+        "apex_available=com.android.bar",
         "apex_available=com.android.foo",
+    ]`,
+			"srcs":           `["a.cpp"]`,
+			"local_includes": `["."]`,
+		}),
+	},
+	)
+}
+
+func TestCcLibraryApexAvailableFromDefaults(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library apex_available from defaults converted to deduplicated tags",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: soongCcLibraryPreamble + `
+cc_defaults {
+    name: "a_defaults",
+    apex_available: ["com.android.foo", "//apex_available:platform"],
+}
+
+cc_library {
+    name: "a",
+    defaults: ["a_defaults"],
+    srcs: ["a.cpp"],
+    apex_available: ["com.android.foo", "com.android.bar"],
+}
+`,
+		ExpectedBazelTargets: makeCcLibraryTargets("a", AttrNameToString{
+			"tags": `[
         "apex_available=//apex_available:platform",
         "apex_available=com.android.bar",
+        "apex_available=com.android.foo",
     ]`,
 			"srcs":           `["a.cpp"]`,
 			"local_includes": `["."]`,
@@ -4010,6 +4834,87 @@ cc_library {
 	})
 }
 
+func TestCcLibraryWithAllUndefinedProperty(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library has ubsan_undefined feature and ubsan runtime dep when all_undefined is set",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		StubbedBuildDefinitions:    []string{"libclang_rt.ubsan_standalone"},
+		Blueprint: `
+cc_library {
+		name: "libclang_rt.ubsan_standalone",
+}
+
+cc_library {
+		name: "foo",
+		sanitize: {
+				all_undefined: true,
+		},
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"features":       `["ubsan_undefined"]`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"features": `["ubsan_undefined"]`,
+				"implementation_dynamic_deps": `select({
+        "//build/bazel_common_rules/platforms/os:android": [":libclang_rt.ubsan_standalone"],
+        "//build/bazel_common_rules/platforms/os:linux_musl": [":libclang_rt.ubsan_standalone"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryWithArchSpecificAllUndefinedProperty(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library has arch-specific ubsan runtime dep when all_undefined is set for one arch",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		StubbedBuildDefinitions:    []string{"libclang_rt.ubsan_standalone"},
+		Blueprint: `
+cc_library {
+		name: "libclang_rt.ubsan_standalone",
+}
+
+cc_library {
+		name: "foo",
+		arch: {
+				arm64: {
+						sanitize: {
+								all_undefined: true,
+						},
+				},
+		},
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"features": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["ubsan_undefined"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"features": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["ubsan_undefined"],
+        "//conditions:default": [],
+    })`,
+				"implementation_dynamic_deps": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":libclang_rt.ubsan_standalone"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryWithSanitizerBlocklist(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library has correct feature when sanitize.blocklist is provided",
@@ -4313,6 +5218,32 @@ cc_library {
 	})
 }
 
+func TestCcLibraryWholeProgramVtablesWithoutThinLto(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library does not enable whole_program_vtables when thin LTO is disabled",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	lto: {
+		never: true,
+	},
+	whole_program_vtables: true,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"features":       `["-android_thin_lto"]`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"features":       `["-android_thin_lto"]`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryHiddenVisibilityConvertedToFeature(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library changes hidden visibility flag to feature",
@@ -4682,6 +5613,122 @@ cc_library {
 	})
 }
 
+func TestCcLibraryWithCfiCrossDsoArchSpecific(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library has correct features when cfi_cross_dso is disabled for specific arches",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	sanitize: {
+		cfi: true,
+	},
+	arch: {
+		arm64: {
+			sanitize: {
+				config: {
+					cfi_cross_dso: false,
+				},
+			},
+		},
+	},
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"features": `["android_cfi"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-android_cfi_cross_dso"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"features": `["android_cfi"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-android_cfi_cross_dso"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryWithCfiAndHiddenVisibility(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library does not use default visibility for cfi when visibility is hidden",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	cflags: ["-fvisibility=hidden"],
+	sanitize: {
+		cfi: true,
+	},
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"features": `[
+        "android_cfi",
+        "-android_cfi_visibility_default",
+        "visibility_hidden",
+    ]`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"features": `[
+        "android_cfi",
+        "-android_cfi_visibility_default",
+        "visibility_hidden",
+    ]`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryWithArchCfiAndHiddenVisibility(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library does not use default visibility for cfi when visibility is hidden in another axis",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "foo",
+	cflags: ["-fvisibility=hidden"],
+	arch: {
+		arm64: {
+			sanitize: {
+				cfi: true,
+			},
+		},
+	},
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"features": `[
+        "-android_cfi_visibility_default",
+        "visibility_hidden",
+    ] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["android_cfi"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"features": `[
+        "-android_cfi_visibility_default",
+        "visibility_hidden",
+    ] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["android_cfi"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryExplicitlyDisablesCfiWhenFalse(t *testing.T) {
 	runCcLibraryTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library disables cfi when explciitly set to false in the bp",
@@ -4729,12 +5776,23 @@ cc_library_shared {
 		},
 	},
 }
+cc_library_shared {
+	name: "foo_with_module_name_stem",
+	stem: "foo_with_stem_simple",
+}
 `,
 		ExpectedBazelTargets: []string{
 			MakeBazelTarget("cc_library_shared", "foo_with_stem_simple", AttrNameToString{
 				"stem":           `"foo"`,
 				"local_includes": `["."]`,
 			}),
+			MakeBazelTargetNoRestrictions("alias", "foo", AttrNameToString{
+				"actual": `":foo_with_stem_simple"`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo_with_module_name_stem", AttrNameToString{
+				"stem":           `"foo_with_stem_simple"`,
+				"local_includes": `["."]`,
+			}),
 			MakeBazelTarget("cc_library_shared", "foo_with_arch_variant_stem", AttrNameToString{
 				"stem": `select({
         "//build/bazel_common_rules/platforms/arch:arm": "foo-arm",
@@ -4747,6 +5805,54 @@ cc_library_shared {
 	})
 }
 
+func TestCcLibraryWithStemOfGeneratedTarget(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with stem of another target of the package or of another stem",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: soongCcLibraryPreamble + `
+cc_library {
+	name: "foo",
+	stem: "foo_bp2build_cc_library_static",
+}
+cc_library {
+	name: "liba",
+	stem: "libx",
+}
+cc_library {
+	name: "libb",
+	stem: "libx",
+	shared_libs: ["liba"],
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"stem":           `"foo_bp2build_cc_library_static"`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_static", "liba_bp2build_cc_library_static", AttrNameToString{
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "liba", AttrNameToString{
+				"stem":           `"libx"`,
+				"local_includes": `["."]`,
+			}),
+			MakeBazelTarget("cc_library_static", "libb_bp2build_cc_library_static", AttrNameToString{
+				"implementation_dynamic_deps": `[":liba"]`,
+				"local_includes":              `["."]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "libb", AttrNameToString{
+				"implementation_dynamic_deps": `[":liba"]`,
+				"stem":                        `"libx"`,
+				"local_includes":              `["."]`,
+			}),
+		},
+	})
+}
+
 // Bazel enforces that proto_library and the .proto file are in the same bazel package
 func TestGenerateProtoLibraryInSamePackage(t *testing.T) {
 	tc := Bp2buildTestCase{
@@ -5195,6 +6301,94 @@ ndk_library {
 	runCcLibraryTestCase(t, tc)
 }
 
+func TestNdkLibraryConversionFirstVersion(t *testing.T) {
+	for _, firstVersion := range []string{"minimum", "9"} {
+		runCcLibraryTestCase(t, Bp2buildTestCase{
+			Description:                "ndk_library conversion with first_version " + firstVersion,
+			ModuleTypeUnderTest:        "cc_library",
+			ModuleTypeUnderTestFactory: cc.LibraryFactory,
+			Blueprint: `
+cc_library {
+	name: "libfoo",
+}
+ndk_library {
+	name: "libfoo",
+	first_version: "` + firstVersion + `",
+	symbol_file: "libfoo.map.txt",
+}
+`,
+			StubbedBuildDefinitions: []string{"libfoo"},
+			ExpectedBazelTargets: []string{
+				MakeBazelTarget("cc_stub_suite", "libfoo.ndk_stub_libs", AttrNameToString{
+					"api_surface":          `"publicapi"`,
+					"included_in_ndk":      `True`,
+					"soname":               `"libfoo.so"`,
+					"source_library_label": `"//:libfoo"`,
+					"symbol_file":          `"libfoo.map.txt"`,
+					"versions": `[
+        "21",
+        "22",
+        "23",
+        "24",
+        "25",
+        "26",
+        "27",
+        "28",
+        "29",
+        "30",
+        "S",
+        "Tiramisu",
+        "current",
+    ]`,
+				}),
+			},
+		})
+	}
+}
+
+func TestNdkLibraryConversionWithHeadersAndApexAvailable(t *testing.T) {
+	tc := Bp2buildTestCase{
+		Description:                "ndk_library conversion with export_header_libs and apex_available",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Blueprint: `
+cc_library {
+	name: "libfoo",
+	apex_available: ["com.android.foo"],
+}
+cc_library_headers {
+	name: "libfoo_headers",
+}
+ndk_library {
+	name: "libfoo",
+	first_version: "29",
+	symbol_file: "libfoo.map.txt",
+	export_header_libs: ["libfoo_headers"],
+}
+`,
+		StubbedBuildDefinitions: []string{"libfoo", "libfoo_headers"},
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_stub_suite", "libfoo.ndk_stub_libs", AttrNameToString{
+				"api_surface":          `"publicapi"`,
+				"deps":                 `[":libfoo_headers"]`,
+				"included_in_ndk":      `True`,
+				"soname":               `"libfoo.so"`,
+				"source_library_label": `"//:libfoo"`,
+				"symbol_file":          `"libfoo.map.txt"`,
+				"tags":                 `["apex_available=com.android.foo"]`,
+				"versions": `[
+        "29",
+        "30",
+        "S",
+        "Tiramisu",
+        "current",
+    ]`,
+			}),
+		},
+	}
+	runCcLibraryTestCase(t, tc)
+}
+
 func TestNdkHeadersConversion(t *testing.T) {
 	tc := Bp2buildTestCase{
 		Description:                "ndk_headers conversion",
@@ -5253,12 +6447,11 @@ versioned_ndk_headers {
 	runCcLibraryTestCase(t, tc)
 }
 
-// Regression test for b/303307456.
-// TODO: b/202299295 - Remove this test when cc rules have proper support
-// for the `required` property
+// Regression test for b/303307456: the required property is not converted to
+// data, but to a dedicated attribute of the library targets.
 func TestCcModules_requiredProperty(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
-		Description: "cc modules do not use the required property",
+		Description: "cc modules convert the required property",
 		Filesystem: map[string]string{
 			"foo.c": "",
 			"bar.c": "",
@@ -5289,16 +6482,20 @@ cc_library_static {
 }`,
 		ExpectedBazelTargets: []string{
 			MakeBazelTarget("cc_library_static", "foo_both_bp2build_cc_library_static", AttrNameToString{
-				"srcs_c": `["foo.c"]`,
+				"required": `[":bar"]`,
+				"srcs_c":   `["foo.c"]`,
 			}),
 			MakeBazelTarget("cc_library_shared", "foo_both", AttrNameToString{
-				"srcs_c": `["foo.c"]`,
+				"required": `[":bar"]`,
+				"srcs_c":   `["foo.c"]`,
 			}),
 			MakeBazelTarget("cc_library_shared", "foo_shared", AttrNameToString{
-				"srcs_c": `["foo.c"]`,
+				"required": `[":bar"]`,
+				"srcs_c":   `["foo.c"]`,
 			}),
 			MakeBazelTarget("cc_library_static", "foo_static", AttrNameToString{
-				"srcs_c": `["foo.c"]`,
+				"required": `[":bar"]`,
+				"srcs_c":   `["foo.c"]`,
 			}),
 			MakeBazelTarget("cc_library_static", "bar", AttrNameToString{
 				"srcs_c": `["bar.c"]`,
@@ -5307,11 +6504,61 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryArchVariantRequired(t *testing.T) {
+	runCcLibraryTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library with arch variant required",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		StubbedBuildDefinitions:    []string{"bar", "baz"},
+		Filesystem: map[string]string{
+			"foo.c": "",
+		},
+		Blueprint: `
+cc_library {
+    name: "foo",
+    srcs: ["foo.c"],
+    include_build_directory: false,
+    required: ["bar", "foo"],
+    arch: {
+        arm64: {
+            required: ["baz"],
+        },
+    },
+}
+cc_library {
+    name: "bar",
+}
+cc_library {
+    name: "baz",
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_bp2build_cc_library_static", AttrNameToString{
+				"required": `[":bar"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":baz"],
+        "//conditions:default": [],
+    })`,
+				"srcs_c": `["foo.c"]`,
+			}),
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"required": `[":bar"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":baz"],
+        "//conditions:default": [],
+    })`,
+				"srcs_c": `["foo.c"]`,
+			}),
+		},
+	})
+}
+
 func TestPropertiesIfStubLibraryIsInNdk(t *testing.T) {
 	tc := Bp2buildTestCase{
 		Description:                "If an equivalent ndk_library exists, set included_in_ndk=true for module-libapi stubs",
 		ModuleTypeUnderTest:        "cc_library",
 		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Filesystem: map[string]string{
+			"libfoo.map.txt": "",
+			"libbar.map.txt": "",
+		},
 		Blueprint: `
 // libfoo is an ndk library and contributes to module-libapi
 cc_library {
@@ -5364,3 +6611,41 @@ cc_library {
 	}
 	runCcLibraryTestCase(t, tc)
 }
+
+func TestCcLibrarySameNameModulesInSiblingDirectories(t *testing.T) {
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		registerCcLibraryModuleTypes(ctx)
+		ctx.RegisterModuleType("soong_namespace", func() android.Module { return android.NamespaceFactory() })
+	}, Bp2buildTestCase{
+		Description:                "cc_library depending on modules with the same name in sibling directories",
+		ModuleTypeUnderTest:        "cc_library",
+		ModuleTypeUnderTestFactory: cc.LibraryFactory,
+		Filesystem: map[string]string{
+			"a/Android.bp": `
+soong_namespace {}
+
+cc_library {
+	name: "foo",
+	include_build_directory: false,
+}`,
+			"b/Android.bp": `
+soong_namespace {}
+
+cc_library {
+	name: "foo",
+	include_build_directory: false,
+}`,
+		},
+		Blueprint: soongCcLibraryPreamble + `
+cc_library {
+	name: "bar",
+	shared_libs: ["//a:foo"],
+	static_libs: ["//b:foo"],
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: makeCcLibraryTargets("bar", AttrNameToString{
+			"implementation_deps":         `["//b:foo_bp2build_cc_library_static"]`,
+			"implementation_dynamic_deps": `["//a:foo"]`,
+		}),
+	})
+}
@@ -421,6 +421,36 @@ cc_prebuilt_library_headers {
 	})
 }
 
+func TestPrebuiltCcLibraryHeadersArchExportSystemIncludes(t *testing.T) {
+	runCcLibraryHeadersTestCase(t, Bp2buildTestCase{
+		Description: "cc_prebuilt_library_headers with arch-specific export_system_include_dirs",
+		Blueprint: soongCcLibraryHeadersPreamble + `
+cc_prebuilt_library_headers {
+    name: "foo_headers",
+    export_system_include_dirs: ["include"],
+    arch: {
+        arm: {
+            export_system_include_dirs: ["sysroot/arm/include"],
+        },
+        arm64: {
+            export_system_include_dirs: ["sysroot/arm64/include"],
+        },
+    },
+    bazel_module: { bp2build_available: true },
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_headers", "foo_headers", AttrNameToString{
+				"export_system_includes": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["sysroot/arm/include"],
+        "//build/bazel_common_rules/platforms/arch:arm64": ["sysroot/arm64/include"],
+        "//conditions:default": [],
+    }) + ["include"]`,
+			}),
+		},
+	})
+}
+
 func TestPrebuiltCcLibraryHeadersPreferredRdepUpdated(t *testing.T) {
 	runCcLibraryHeadersTestCase(t, Bp2buildTestCase{
 		Description:             "cc_library_headers prebuilt preferred is used as rdep",
@@ -15,6 +15,7 @@
 package bp2build
 
 import (
+	"fmt"
 	"testing"
 
 	"android/soong/android"
@@ -336,6 +337,33 @@ cc_library_shared {
 	})
 }
 
+func TestCcLibrarySharedStripKeepSymbolsListIsSymbols(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared strip.keep_symbols_list entries are kept as symbol names",
+		Filesystem: map[string]string{
+			"syms.txt": "",
+		},
+		Blueprint: soongCcLibrarySharedPreamble + `
+cc_library_shared {
+    name: "foo_shared",
+    strip: {
+        keep_symbols_list: ["sym", "syms.txt"],
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo_shared", AttrNameToString{
+				"strip": `{
+        "keep_symbols_list": [
+            "sym",
+            "syms.txt",
+        ],
+    }`,
+			}),
+		},
+	})
+}
+
 func TestCcLibrarySharedVersionScriptAndDynamicList(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_shared version script and dynamic list",
@@ -366,6 +394,83 @@ cc_library_shared {
 	})
 }
 
+func TestCcLibrarySharedVendorAndProductVersionScripts(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared vendor and product version scripts replace the core version script",
+		Filesystem: map[string]string{
+			"version_script": "",
+			"vendor.map":     "",
+			"product.map":    "",
+			"dynamic.list":   "",
+		},
+		Blueprint: soongCcLibrarySharedPreamble + `
+cc_library_shared {
+    name: "foo_shared",
+    version_script: "version_script",
+    dynamic_list: "dynamic.list",
+    target: {
+        vendor: {
+            version_script: "vendor.map",
+        },
+        product: {
+            version_script: "product.map",
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo_shared", AttrNameToString{
+				"additional_linker_inputs": `["dynamic.list"] + select({
+        "@soong_injection//image:product": ["product.map"],
+        "@soong_injection//image:vendor": ["vendor.map"],
+        "//conditions:default": ["version_script"],
+    })`,
+				"linkopts": `["-Wl,--dynamic-list,$(location dynamic.list)"] + select({
+        "@soong_injection//image:product": ["-Wl,--version-script,$(location product.map)"],
+        "@soong_injection//image:vendor": ["-Wl,--version-script,$(location vendor.map)"],
+        "//conditions:default": ["-Wl,--version-script,$(location version_script)"],
+    })`,
+				"features": `["android_cfi_exports_map"]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibrarySharedVendorVersionScriptOnly(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared vendor version script without a core version script",
+		Filesystem: map[string]string{
+			"vendor.map": "",
+		},
+		Blueprint: soongCcLibrarySharedPreamble + `
+cc_library_shared {
+    name: "foo_shared",
+    target: {
+        vendor: {
+            version_script: "vendor.map",
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo_shared", AttrNameToString{
+				"additional_linker_inputs": `select({
+        "@soong_injection//image:vendor": ["vendor.map"],
+        "//conditions:default": [],
+    })`,
+				"linkopts": `select({
+        "@soong_injection//image:vendor": ["-Wl,--version-script,$(location vendor.map)"],
+        "//conditions:default": [],
+    })`,
+				"features": `select({
+        "@soong_injection//image:vendor": ["android_cfi_exports_map"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryLdflagsSplitBySpaceSoongAdded(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Description: "ldflags are split by spaces except for the ones added by soong (version script and dynamic list)",
@@ -504,6 +609,18 @@ func TestCcLibrarySharedProto(t *testing.T) {
 	})
 }
 
+func TestCcLibrarySharedMinSdkVersionApexInherit(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared with min_sdk_version apex_inherit is not converted",
+		Blueprint: `cc_library_shared {
+	name: "foo",
+	min_sdk_version: "apex_inherit",
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
 func TestCcLibrarySharedUseVersionLib(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Filesystem: map[string]string{
@@ -531,6 +648,7 @@ func TestCcLibrarySharedStubs(t *testing.T) {
 		ModuleTypeUnderTestFactory: cc.LibrarySharedFactory,
 		Dir:                        "foo/bar",
 		Filesystem: map[string]string{
+			"foo/bar/a.map.txt": "",
 			"foo/bar/Android.bp": `
 cc_library_shared {
 	name: "a",
@@ -559,6 +677,87 @@ cc_library_shared {
 	})
 }
 
+func TestCcLibrarySharedStubsAndVersionScript(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library_shared stubs and version script",
+		ModuleTypeUnderTest:        "cc_library_shared",
+		ModuleTypeUnderTestFactory: cc.LibrarySharedFactory,
+		Dir:                        "foo/bar",
+		Filesystem: map[string]string{
+			"foo/bar/a.map.txt":      "",
+			"foo/bar/version_script": "",
+			"foo/bar/Android.bp": `
+cc_library_shared {
+	name: "a",
+	stubs: { symbol_file: "a.map.txt", versions: ["28", "29", "current"] },
+	version_script: "version_script",
+	bazel_module: { bp2build_available: true },
+	include_build_directory: false,
+}
+`,
+		},
+		Blueprint: soongCcLibraryPreamble,
+		ExpectedBazelTargets: []string{makeCcStubSuiteTargets("a", AttrNameToString{
+			"api_surface":          `"module-libapi"`,
+			"soname":               `"a.so"`,
+			"source_library_label": `"//foo/bar:a"`,
+			"stubs_symbol_file":    `"a.map.txt"`,
+			"stubs_versions": `[
+        "28",
+        "29",
+        "current",
+    ]`,
+		}),
+			MakeBazelTarget("cc_library_shared", "a", AttrNameToString{
+				"additional_linker_inputs": `["version_script"]`,
+				"features":                 `["android_cfi_exports_map"]`,
+				"linkopts":                 `["-Wl,--version-script,$(location version_script)"]`,
+				"stubs_symbol_file":        `"a.map.txt"`,
+			}),
+		},
+	})
+}
+
+func TestCcLibrarySharedStubsMissingSymbolFile(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library_shared stubs with a missing symbol file",
+		ModuleTypeUnderTest:        "cc_library_shared",
+		ModuleTypeUnderTestFactory: cc.LibrarySharedFactory,
+		Dir:                        "foo/bar",
+		Filesystem: map[string]string{
+			"foo/bar/Android.bp": `
+cc_library_shared {
+	name: "a",
+	stubs: { symbol_file: "a.map.txt", versions: ["28", "29", "current"] },
+	bazel_module: { bp2build_available: true },
+	include_build_directory: false,
+}
+`,
+		},
+		Blueprint:   soongCcLibraryPreamble,
+		ExpectedErr: fmt.Errorf(`"a.map.txt" does not exist, expected at "foo/bar/a.map.txt"`),
+	})
+}
+
+func TestCcLibrarySharedStubsGlobSymbolFile(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library_shared stubs with a glob symbol file",
+		ModuleTypeUnderTest:        "cc_library_shared",
+		ModuleTypeUnderTestFactory: cc.LibrarySharedFactory,
+		Filesystem: map[string]string{
+			"a.map.txt": "",
+		},
+		Blueprint: soongCcLibraryPreamble + `
+cc_library_shared {
+	name: "a",
+	stubs: { symbol_file: "*.map.txt", versions: ["28", "29", "current"] },
+	include_build_directory: false,
+}
+`,
+		ExpectedErr: fmt.Errorf(`globs are not supported, got "*.map.txt"`),
+	})
+}
+
 func TestCcLibrarySharedStubs_UseImplementationInSameApex(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Description:                "cc_library_shared stubs",
@@ -670,8 +869,8 @@ cc_library_shared {
     })`,
 				"tags": `[
         "apex_available=//apex_available:platform",
-        "apex_available=apexfoo",
         "apex_available=apexbar",
+        "apex_available=apexfoo",
     ]`,
 			}),
 		},
@@ -888,6 +1087,60 @@ func TestCcLibrarySharedClangUnknownFlags(t *testing.T) {
 	})
 }
 
+func TestCcLibrarySharedToolchainOwnedLdflags(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared drops copies of ldflags set by the toolchains of a configuration but keeps overrides",
+		Blueprint: soongCcProtoPreamble + `cc_library_shared {
+	name: "foo",
+	ldflags: ["-Wl,-z,now", "-Wl,--exclude-libs=bar.a"],
+	target: {
+		android: {
+			ldflags: ["-Wl,--hash-style=gnu", "-Wl,--build-id=sha1"],
+		},
+		linux_bionic: {
+			ldflags: ["-Wl,--hash-style=gnu", "-Wl,--icf=all"],
+		},
+		linux_glibc: {
+			ldflags: ["-Wl,--hash-style=gnu", "-Wl,-z,relro"],
+		},
+	},
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"linkopts": `[
+        "-Wl,-z,now",
+        "-Wl,--exclude-libs=bar.a",
+    ] + select({
+        "//build/bazel_common_rules/platforms/os:android": ["-Wl,--build-id=sha1"],
+        "//build/bazel_common_rules/platforms/os:linux_bionic": ["-Wl,--icf=all"],
+        "//build/bazel_common_rules/platforms/os:linux_glibc": ["-Wl,--hash-style=gnu"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibrarySharedOverrides(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared overrides",
+		Blueprint: soongCcProtoPreamble + `cc_library_shared {
+	name: "foo",
+	overrides: ["libbar", "libbaz"],
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"overrides": `[
+        "libbar",
+        "libbaz",
+    ]`,
+			}),
+		},
+	})
+}
+
 func TestCCLibraryFlagSpaceSplitting(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Blueprint: soongCcProtoPreamble + `cc_library_shared {
@@ -920,6 +1173,29 @@ func TestCCLibraryFlagSpaceSplitting(t *testing.T) {
 	})
 }
 
+func TestCCLibraryFlagSpaceSplittingQuoted(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared flags with quoted or escaped spaces are not split",
+		Blueprint: soongCcProtoPreamble + `cc_library_shared {
+	name: "foo",
+	cflags: [
+		"-DNAME=\"a b\"",
+		"-DSINGLE='c d' -DESCAPED=e\\ f",
+	],
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"copts": `[
+        "-DNAME=\"a b\"",
+        "-DSINGLE='c d'",
+        "-DESCAPED=e\\ f",
+    ]`,
+			}),
+		},
+	})
+}
+
 func TestCCLibrarySharedRuntimeDeps(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Blueprint: `cc_library_shared {
@@ -1129,6 +1405,38 @@ func TestCcLibrarySharedHeaderAbiChecker(t *testing.T) {
 	})
 }
 
+func TestCcLibrarySharedHeaderAbiCheckerRefDumpDirs(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared with header abi checker reference dump directories",
+		Blueprint: `cc_library_shared {
+    name: "foo",
+    header_abi_checker: {
+        enabled: true,
+        ref_dump_dirs: ["abi-dumps"],
+    },
+    target: {
+        platform: {
+            header_abi_checker: {
+                ref_dump_dirs: ["platform-abi-dumps"],
+                diff_flags: ["-allow-unreferenced-changes"],
+            },
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_shared", "foo", AttrNameToString{
+				"abi_checker_enabled":    `True`,
+				"abi_checker_diff_flags": `["-allow-unreferenced-changes"]`,
+				"abi_checker_ref_dump_dirs": `[
+        "abi-dumps",
+        "platform-abi-dumps",
+    ]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibrarySharedWithIntegerOverflowProperty(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_shared has correct features when integer_overflow property is provided",
@@ -1412,6 +1720,10 @@ cc_library_shared{
 func TestCcLibrarySharedStubsDessertVersionConversion(t *testing.T) {
 	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_shared converts dessert codename versions to numerical versions",
+		Filesystem: map[string]string{
+			"a.map.txt": "",
+			"b.map.txt": "",
+		},
 		Blueprint: `
 cc_library_shared {
 	name: "a",
@@ -1654,3 +1966,39 @@ ndk_library {
 		},
 	})
 }
+
+func TestCcLibrarySharedUniqueHostSoname(t *testing.T) {
+	runCcLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_shared with unique_host_soname",
+		Blueprint: soongCcLibrarySharedPreamble + `
+cc_library_shared {
+    name: "foo",
+    host_supported: true,
+    unique_host_soname: true,
+    include_build_directory: false,
+}
+
+cc_library_shared {
+    name: "bar",
+    host_supported: true,
+    unique_host_soname: true,
+    suffix: "-host",
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTargetNoRestrictions("cc_library_shared", "foo", AttrNameToString{
+				"suffix": `select({
+        "//build/bazel_common_rules/platforms/os:darwin": "-host",
+        "//build/bazel_common_rules/platforms/os:linux_bionic": "-host",
+        "//build/bazel_common_rules/platforms/os:linux_glibc": "-host",
+        "//build/bazel_common_rules/platforms/os:linux_musl": "-host",
+        "//build/bazel_common_rules/platforms/os:windows": "-host",
+        "//conditions:default": None,
+    })`,
+			}),
+			MakeBazelTargetNoRestrictions("cc_library_shared", "bar", AttrNameToString{
+				"suffix": `"-host"`,
+			}),
+		},
+	})
+}
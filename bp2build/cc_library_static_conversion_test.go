@@ -61,6 +61,7 @@ func registerCcLibraryStaticModuleTypes(ctx android.RegistrationContext) {
 	cc.RegisterCCBuildComponents(ctx)
 	ctx.RegisterModuleType("cc_library_headers", cc.LibraryHeaderFactory)
 	ctx.RegisterModuleType("genrule", genrule.GenRuleFactory)
+	ctx.RegisterModuleType("gensrcs", genrule.GenSrcsFactory)
 	// Required for system_shared_libs dependencies.
 	ctx.RegisterModuleType("cc_library", cc.LibraryFactory)
 }
@@ -163,10 +164,9 @@ cc_library_static {
         "header_lib_1",
         "header_lib_2"
     ],
+    export_header_lib_headers: ["header_lib_2"],
     sdk_version: "current",
     min_sdk_version: "29",
-
-    // TODO: Also support export_header_lib_headers
 }`,
 		ExpectedBazelTargets: []string{
 			MakeBazelTarget("cc_library_static", "foo_static", AttrNameToString{
@@ -184,7 +184,6 @@ cc_library_static {
     ]`,
 				"implementation_deps": `[
         ":header_lib_1",
-        ":header_lib_2",
         ":static_lib_1",
         ":static_lib_2",
     ]`,
@@ -203,7 +202,7 @@ cc_library_static {
     ]`,
 				"sdk_version":     `"current"`,
 				"min_sdk_version": `"29"`,
-				"deps": `select({
+				"deps": `[":header_lib_2"] + select({
         "//build/bazel/rules/apex:unbundled_app": ["//build/bazel/rules/cc:ndk_sysroot"],
         "//conditions:default": [],
     })`,
@@ -577,6 +576,34 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticOneOsSrcsExcludeSrcs(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static os specific srcs and exclude_srcs in the same block",
+		Filesystem: map[string]string{
+			"common.c":          "",
+			"for-android.c":     "",
+			"not-for-android.c": "",
+		},
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo_static",
+    srcs: ["common.c", "not-for-android.c"],
+    target: {
+        android: { srcs: ["for-android.c"], exclude_srcs: ["not-for-android.c"] },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_static", AttrNameToString{
+				"srcs_c": `["common.c"] + select({
+        "//build/bazel_common_rules/platforms/os:android": ["for-android.c"],
+        "//conditions:default": ["not-for-android.c"],
+    })`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryStaticTwoArchExcludeSrcs(t *testing.T) {
 	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_static arch specific exclude_srcs for 2 architectures",
@@ -1029,6 +1056,19 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticExportIncludeDirsInGenDir(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static export_include_dirs in a generated directory",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo_static",
+    export_include_dirs: ["$(genDir)/include"],
+    include_build_directory: false,
+}`,
+		ExpectedErr: fmt.Errorf(`"$(genDir)/include": generated include directories must be exported by the generating genrule`),
+	})
+}
+
 // generated_headers has "variant_prepend" tag. In bp2build output,
 // variant info(select) should go before general info.
 func TestCcLibraryStaticArchSrcsExcludeSrcsGeneratedFiles(t *testing.T) {
@@ -1112,6 +1152,87 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticOsSpecificExcludeGeneratedSources(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static exclude_generated_sources in os and os_arch blocks",
+		Blueprint: soongCcLibraryStaticPreamble +
+			simpleModule("genrule", "generated_src") +
+			simpleModule("genrule", "generated_src_not_android") +
+			simpleModule("genrule", "generated_src_android") + `
+cc_library_static {
+    name: "foo_static",
+    generated_sources: ["generated_src", "generated_src_not_android"],
+    target: {
+        android: {
+            generated_sources: ["generated_src_android"],
+            exclude_generated_sources: ["generated_src_not_android"],
+        },
+        android_x86: {
+            exclude_generated_sources: ["generated_src_android"],
+        },
+    },
+    include_build_directory: false,
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_static", AttrNameToString{
+				"srcs": `[":generated_src"] + select({
+        "//build/bazel_common_rules/platforms/os:android": [],
+        "//conditions:default": [":generated_src_not_android"],
+    }) + select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm": [":generated_src_android"],
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": [":generated_src_android"],
+        "//build/bazel_common_rules/platforms/os_arch:android_riscv64": [":generated_src_android"],
+        "//build/bazel_common_rules/platforms/os_arch:android_x86": [],
+        "//build/bazel_common_rules/platforms/os_arch:android_x86_64": [":generated_src_android"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticGeneratedSourcesFromGensrcs(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static generated_sources from gensrcs are partitioned by output extension",
+		StubbedBuildDefinitions: []string{"gen_c", "gen_cpp", "gen_s", "gen_genrule"},
+		Blueprint: soongCcLibraryStaticPreamble +
+			simpleModule("genrule", "gen_genrule") + `
+gensrcs {
+    name: "gen_c",
+    output_extension: "c",
+}
+gensrcs {
+    name: "gen_cpp",
+    output_extension: "cpp",
+}
+gensrcs {
+    name: "gen_s",
+    output_extension: "S",
+}
+cc_library_static {
+    name: "foo_static",
+    srcs: ["foo.c"],
+    generated_sources: ["gen_c", "gen_cpp", "gen_s", "gen_genrule"],
+    include_build_directory: false,
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_static", AttrNameToString{
+				"srcs": `[
+        ":gen_cpp",
+        ":gen_genrule",
+    ]`,
+				"srcs_c": `[
+        "foo.c",
+        ":gen_c",
+    ]`,
+				"srcs_as": `[":gen_s"]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryStaticGetTargetProperties(t *testing.T) {
 	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
 
@@ -1163,6 +1284,79 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticHostLinuxTargetProperties(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static host_linux and host_linux_<arch> properties",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo_static",
+    host_supported: true,
+    target: {
+        host_linux: {
+            srcs: ["host_linux_src.c"],
+        },
+        host_linux_x86_64: {
+            srcs: ["host_linux_x86_64_src.c"],
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTargetNoRestrictions("cc_library_static", "foo_static", AttrNameToString{
+				"srcs_c": `select({
+        "//build/bazel_common_rules/platforms/os:linux_bionic": ["host_linux_src.c"],
+        "//build/bazel_common_rules/platforms/os:linux_glibc": ["host_linux_src.c"],
+        "//build/bazel_common_rules/platforms/os:linux_musl": ["host_linux_src.c"],
+        "//conditions:default": [],
+    }) + select({
+        "//build/bazel_common_rules/platforms/os_arch:linux_bionic_x86_64": ["host_linux_x86_64_src.c"],
+        "//build/bazel_common_rules/platforms/os_arch:linux_glibc_x86_64": ["host_linux_x86_64_src.c"],
+        "//build/bazel_common_rules/platforms/os_arch:linux_musl_x86_64": ["host_linux_x86_64_src.c"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticCflagsMakeVarReferences(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static cflags referencing build variables Bazel provides",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo_static",
+    cflags: ["-DSDK_VERSION=$(PLATFORM_SDK_VERSION)"],
+    arch: {
+        arm64: {
+            cflags: ["-DARM64_SDK_VERSION=$(PLATFORM_SDK_VERSION)"],
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo_static", AttrNameToString{
+				"copts": `["-DSDK_VERSION=$(Platform_sdk_version)"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-DARM64_SDK_VERSION=$(Platform_sdk_version)"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticCflagsUnknownMakeVarReference(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static cflags referencing a build variable Bazel does not provide",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo_static",
+    cflags: ["-DANDROID_BUILD_ID=$(BUILD_ID)"],
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
 func TestCcLibraryStaticProductVariableSelects(t *testing.T) {
 	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_static product variable selects",
@@ -1633,6 +1827,62 @@ func TestCcLibraryStaticProto(t *testing.T) {
 	})
 }
 
+func TestCcLibraryStaticProtoPlugin(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static generating code with a custom protoc plugin is not converted",
+		StubbedBuildDefinitions: []string{"libprotobuf-cpp-full", "libprotobuf-cpp-lite", "protoc-gen-foo"},
+		Blueprint: soongCcProtoPreamble + `cc_library_static {
+	name: "protoc-gen-foo",
+	host_supported: true,
+}
+
+cc_library_static {
+	name: "foo",
+	srcs: ["foo.proto"],
+	proto: {
+		plugin: "foo",
+	},
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{},
+	})
+}
+
+func TestCcLibraryStaticProtoSdkVersion(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static propagates sdk versions to the cc_lite_proto_library",
+		StubbedBuildDefinitions: []string{"libprotobuf-cpp-full", "libprotobuf-cpp-lite"},
+		Blueprint: soongCcProtoPreamble + `cc_library_static {
+	name: "foo",
+	srcs: ["foo.proto"],
+	sdk_version: "current",
+	min_sdk_version: "29",
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("proto_library", "foo_proto", AttrNameToString{
+				"srcs": `["foo.proto"]`,
+			}), MakeBazelTarget("cc_lite_proto_library", "foo_cc_proto_lite", AttrNameToString{
+				"deps": `[":foo_proto"]`,
+				"cc_deps": `select({
+        "//build/bazel/rules/apex:unbundled_app": ["//build/bazel/rules/cc:ndk_sysroot"],
+        "//conditions:default": [],
+    })`,
+				"sdk_version":     `"current"`,
+				"min_sdk_version": `"29"`,
+			}), MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"deps": `[":libprotobuf-cpp-lite"] + select({
+        "//build/bazel/rules/apex:unbundled_app": ["//build/bazel/rules/cc:ndk_sysroot"],
+        "//conditions:default": [],
+    })`,
+				"implementation_whole_archive_deps": `[":foo_cc_proto_lite"]`,
+				"sdk_version":                       `"current"`,
+				"min_sdk_version":                   `"29"`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryStaticUseVersionLib(t *testing.T) {
 	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
 		Filesystem: map[string]string{
@@ -1858,6 +2108,54 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticWithLogtags(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static with logtags",
+		Filesystem: map[string]string{
+			"events.logtags": "",
+		},
+		Blueprint: `
+cc_library_static {
+	name: "foo",
+	srcs: ["blah.cpp"],
+	logtags: ["events.logtags"],
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"srcs":           `["blah.cpp"]`,
+				"local_includes": `["."]`,
+				"logtags":        `["events.logtags"]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticManual(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static with bazel_module.manual adds manual tag to all targets",
+		StubbedBuildDefinitions: []string{"libprotobuf-cpp-full", "libprotobuf-cpp-lite"},
+		Blueprint: soongCcProtoPreamble + `cc_library_static {
+	name: "foo",
+	srcs: ["foo.proto"],
+	include_build_directory: false,
+	bazel_module: { manual: true },
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("proto_library", "foo_proto", AttrNameToString{
+				"srcs": `["foo.proto"]`,
+				"tags": `["manual"]`,
+			}), MakeBazelTarget("cc_lite_proto_library", "foo_cc_proto_lite", AttrNameToString{
+				"deps": `[":foo_proto"]`,
+				"tags": `["manual"]`,
+			}), MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"implementation_whole_archive_deps": `[":foo_cc_proto_lite"]`,
+				"deps":                              `[":libprotobuf-cpp-lite"]`,
+				"tags":                              `["manual"]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryStaticWithIntegerOverflowProperty(t *testing.T) {
 	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_static has correct features when integer_overflow property is provided",
@@ -1878,6 +2176,59 @@ cc_library_static {
 	})
 }
 
+func TestCcLibraryStaticWithHwaddressProperty(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static has correct features when hwaddress property is provided",
+		Blueprint: `
+cc_library_static {
+		name: "foo",
+		sanitize: {
+				hwaddress: true,
+		},
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": ["hwasan"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticWithHwaddressDisabledForTarget(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static opts out of hwasan for the targets disabling hwaddress",
+		Blueprint: `
+cc_library_static {
+		name: "foo",
+		sanitize: {
+				hwaddress: true,
+		},
+		target: {
+				android_arm64: {
+						sanitize: {
+								hwaddress: false,
+						},
+				},
+		},
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": ["-hwasan"],
+        "//conditions:default": [],
+    })`,
+				"local_includes": `["."]`,
+			}),
+		},
+	})
+}
+
 func TestCcLibraryStaticWithMiscUndefinedProperty(t *testing.T) {
 	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
 		Description: "cc_library_static has correct features when misc_undefined property is provided",
@@ -2293,3 +2644,390 @@ genrule {
 		},
 	})
 }
+
+func TestCcLibraryStaticWarningsAsErrorsCflagsKeptInOrder(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:                "cc_library_static keeps flags treating warnings as errors in copts, in order",
+		ModuleTypeUnderTest:        "cc_library_static",
+		ModuleTypeUnderTestFactory: cc.LibraryStaticFactory,
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+	name: "foo",
+	cflags: [
+		"-Wall",
+		"-Werror",
+		"-Wno-error=unused",
+	],
+	arch: {
+		arm: {
+			instruction_set: "arm",
+			cflags: ["-Wno-error"],
+		},
+	},
+	include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"copts": `[
+        "-Wall",
+        "-Werror",
+        "-Wno-error=unused",
+    ] + select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["-Wno-error"],
+        "//conditions:default": [],
+    })`,
+				"features": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["arm_isa_arm"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+func TestCcLibraryStaticVendorAndProductVariantProps(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static with target.vendor and target.product props",
+		StubbedBuildDefinitions: []string{"bar", "baz", "vendor_static", "product_shared"},
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    srcs: [
+        "common.cpp",
+        "core_only.cpp",
+    ],
+    cflags: ["-Wall"],
+    static_libs: ["bar"],
+    shared_libs: ["baz"],
+    target: {
+        vendor: {
+            srcs: ["vendor.cpp"],
+            exclude_srcs: ["core_only.cpp"],
+            cflags: ["-DVENDOR"],
+            static_libs: ["vendor_static"],
+            exclude_shared_libs: ["baz"],
+        },
+        product: {
+            cflags: ["-DPRODUCT"],
+            shared_libs: ["product_shared"],
+        },
+    },
+    include_build_directory: false,
+}
+` + simpleModule("cc_library_static", "bar") +
+			simpleModule("cc_library", "baz") +
+			simpleModule("cc_library_static", "vendor_static") +
+			simpleModule("cc_library", "product_shared"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"copts": `["-Wall"] + select({
+        "@soong_injection//image:product": ["-DPRODUCT"],
+        "@soong_injection//image:vendor": ["-DVENDOR"],
+        "//conditions:default": [],
+    })`,
+				"srcs": `["common.cpp"] + select({
+        "@soong_injection//image:vendor": ["vendor.cpp"],
+        "//conditions:default": ["core_only.cpp"],
+    })`,
+				"implementation_deps": `[":bar"] + select({
+        "@soong_injection//image:vendor": [":vendor_static"],
+        "//conditions:default": [],
+    })`,
+				"implementation_dynamic_deps": `select({
+        "@soong_injection//image:product": [
+            ":baz",
+            ":product_shared",
+        ],
+        "@soong_injection//image:vendor": [],
+        "//conditions:default": [":baz"],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticRecoveryVariantProps(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static with target.recovery props",
+		StubbedBuildDefinitions: []string{"baz", "recovery_static"},
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    recovery_available: true,
+    srcs: [
+        "common.cpp",
+        "core_only.cpp",
+    ],
+    shared_libs: ["baz"],
+    target: {
+        recovery: {
+            srcs: ["recovery.cpp"],
+            exclude_srcs: ["core_only.cpp"],
+            cflags: ["-DRECOVERY"],
+            static_libs: ["recovery_static"],
+            exclude_shared_libs: ["baz"],
+        },
+    },
+    include_build_directory: false,
+}
+` + simpleModule("cc_library", "baz") +
+			simpleModule("cc_library_static", "recovery_static"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"copts": `select({
+        "@soong_injection//image:recovery": ["-DRECOVERY"],
+        "//conditions:default": [],
+    })`,
+				"srcs": `["common.cpp"] + select({
+        "@soong_injection//image:recovery": ["recovery.cpp"],
+        "//conditions:default": ["core_only.cpp"],
+    })`,
+				"implementation_deps": `select({
+        "@soong_injection//image:recovery": [":recovery_static"],
+        "//conditions:default": [],
+    })`,
+				"implementation_dynamic_deps": `select({
+        "@soong_injection//image:recovery": [],
+        "//conditions:default": [":baz"],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticRecoveryOnly(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static installed to the recovery partition only",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    recovery: true,
+    srcs: ["foo.cpp"],
+    include_build_directory: false,
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTargetNoRestrictions("cc_library_static", "foo", AttrNameToString{
+				"srcs": `["foo.cpp"]`,
+				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"] + select({
+        "@soong_injection//image:recovery": [],
+        "//conditions:default": ["@platforms//:incompatible"],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticArchExportHeaderLibHeaders(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static with export_header_lib_headers in an arch block",
+		StubbedBuildDefinitions: []string{"common_headers", "arm64_headers", "arm64_exported_headers"},
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    header_libs: ["common_headers"],
+    export_header_lib_headers: ["common_headers"],
+    arch: {
+        arm64: {
+            header_libs: [
+                "arm64_headers",
+                "arm64_exported_headers",
+            ],
+            export_header_lib_headers: ["arm64_exported_headers"],
+        },
+    },
+    include_build_directory: false,
+}
+` + simpleModule("cc_library_headers", "common_headers") +
+			simpleModule("cc_library_headers", "arm64_headers") +
+			simpleModule("cc_library_headers", "arm64_exported_headers"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"deps": `[":common_headers"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":arm64_exported_headers"],
+        "//conditions:default": [],
+    })`,
+				"implementation_deps": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":arm64_headers"],
+        "//conditions:default": [],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticWrapLdflags(t *testing.T) {
+	bp := soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    ldflags: [
+        "-Wl,--wrap=malloc",
+        "-Wl,--exclude-libs=bar.a",
+    ],
+    include_build_directory: false,
+}
+`
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static ignores ldflags like Soong by default",
+		Blueprint:   bp,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{}),
+		},
+	})
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static exports --wrap ldflags to its dependents when opted in",
+		ExtraFixturePreparer: android.FixtureMergeEnv(map[string]string{
+			"BP2BUILD_STATIC_LIBRARY_WRAP_LDFLAGS": "true",
+		}),
+		Blueprint: bp,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"linkopts": `["-Wl,--wrap=malloc"]`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticDist(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static with dist and dists",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    dist: {
+        targets: ["dist_files"],
+        dir: "libs",
+    },
+    dists: [
+        {
+            targets: ["sdk"],
+            suffix: "_unstripped",
+            tag: "unstripped",
+        },
+    ],
+    include_build_directory: false,
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{}),
+			MakeBazelTarget("copy_to_dist_dir", "foo_dist_1", AttrNameToString{
+				"data":   `[":foo"]`,
+				"flat":   `True`,
+				"prefix": `"libs"`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticArchExcludeHeaderLibs(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static with exclude_header_libs in an arch block",
+		StubbedBuildDefinitions: []string{"common_headers", "not_for_arm_headers", "exported_headers"},
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    header_libs: [
+        "common_headers",
+        "not_for_arm_headers",
+        "exported_headers",
+    ],
+    export_header_lib_headers: ["exported_headers"],
+    arch: {
+        arm: {
+            exclude_header_libs: [
+                "not_for_arm_headers",
+                "exported_headers",
+            ],
+        },
+    },
+    include_build_directory: false,
+}
+` + simpleModule("cc_library_headers", "common_headers") +
+			simpleModule("cc_library_headers", "not_for_arm_headers") +
+			simpleModule("cc_library_headers", "exported_headers"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"deps": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": [],
+        "//conditions:default": [":exported_headers"],
+    })`,
+				"implementation_deps": `[":common_headers"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm": [],
+        "//conditions:default": [":not_for_arm_headers"],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticNativeBridgeProps(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description: "cc_library_static with target.native_bridge props",
+		Blueprint: soongCcLibraryStaticPreamble + `
+cc_library_static {
+    name: "foo",
+    native_bridge_supported: true,
+    srcs: [
+        "common.cpp",
+        "native_only.cpp",
+    ],
+    cflags: ["-Wall"],
+    target: {
+        native_bridge: {
+            srcs: ["native_bridge.cpp"],
+            exclude_srcs: ["native_only.cpp"],
+            cflags: ["-DNATIVE_BRIDGE"],
+        },
+    },
+    include_build_directory: false,
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"copts": `["-Wall"] + select({
+        "@soong_injection//native_bridge:native_bridge": ["-DNATIVE_BRIDGE"],
+        "//conditions:default": [],
+    })`,
+				"srcs": `["common.cpp"] + select({
+        "@soong_injection//native_bridge:native_bridge": ["native_bridge.cpp"],
+        "//conditions:default": ["native_only.cpp"],
+    })`,
+			}),
+		},
+	})
+}
+
+func TestCcLibraryStaticArchVariantRequired(t *testing.T) {
+	runCcLibraryStaticTestCase(t, Bp2buildTestCase{
+		Description:             "cc_library_static with arch variant required",
+		StubbedBuildDefinitions: []string{"bar", "baz"},
+		Filesystem: map[string]string{
+			"foo.c": "",
+		},
+		Blueprint: `
+cc_library_static {
+    name: "foo",
+    srcs: ["foo.c"],
+    include_build_directory: false,
+    required: ["bar", "foo"],
+    arch: {
+        arm64: {
+            required: ["baz"],
+        },
+    },
+}
+cc_library_static {
+    name: "bar",
+}
+cc_library_static {
+    name: "baz",
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_library_static", "foo", AttrNameToString{
+				"required": `[":bar"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": [":baz"],
+        "//conditions:default": [],
+    })`,
+				"srcs_c": `["foo.c"]`,
+			}),
+		},
+	})
+}
@@ -381,6 +381,59 @@ cc_object {
 	})
 }
 
+func TestCcObjectCrtArchSrcsAndExcludeSrcs(t *testing.T) {
+	runCcObjectTestCase(t, Bp2buildTestCase{
+		Description:             "crt cc_object with arch-specific srcs and exclude_srcs",
+		StubbedBuildDefinitions: []string{"crtbrand"},
+		Blueprint: `cc_object {
+    name: "crtbegin_dynamic",
+    crt: true,
+    system_shared_libs: [],
+    srcs: [
+        "crtbegin_common.c",
+        "crtbegin.c",
+    ],
+    objs: ["crtbrand"],
+    linker_script: "crtbegin.lds",
+    arch: {
+        arm: {
+            srcs: ["arch-arm/crtbegin.c"],
+            exclude_srcs: ["crtbegin.c"],
+        },
+        x86: {
+            srcs: ["arch-x86/crtbegin.c"],
+        },
+    },
+    include_build_directory: false,
+}
+
+cc_object {
+    name: "crtbrand",
+    system_shared_libs: [],
+    srcs: ["crtbrand.c"],
+    include_build_directory: false,
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_object", "crtbegin_dynamic", AttrNameToString{
+				"copts":         `["-fno-addrsig"]`,
+				"crt":           "True",
+				"linker_script": `"crtbegin.lds"`,
+				"objs":          `[":crtbrand"]`,
+				"srcs": `["crtbegin_common.c"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["arch-arm/crtbegin.c"],
+        "//build/bazel_common_rules/platforms/arch:x86": [
+            "crtbegin.c",
+            "arch-x86/crtbegin.c",
+        ],
+        "//conditions:default": ["crtbegin.c"],
+    })`,
+				"system_dynamic_deps": `[]`,
+			}),
+		},
+	})
+}
+
 func TestCcObjectSelectOnLinuxAndBionicArchs(t *testing.T) {
 	runCcObjectTestCase(t, Bp2buildTestCase{
 		Description: "cc_object setting srcs based on linux and bionic archs",
@@ -163,3 +163,33 @@ cc_prebuilt_library_shared {
 		},
 	})
 }
+
+func TestPrebuiltLibrarySharedArchSrcsInSubdirsWithExportIncludes(t *testing.T) {
+	runCcPrebuiltLibrarySharedTestCase(t, Bp2buildTestCase{
+		Description: "cc_prebuilt_library_shared with per-arch srcs in subdirectories and export_includes",
+		Filesystem: map[string]string{
+			"lib/foo.so":   "",
+			"lib64/foo.so": "",
+		},
+		Blueprint: `
+cc_prebuilt_library_shared {
+	name: "libfoo",
+	export_include_dirs: ["include"],
+	arch: {
+		arm: { srcs: ["lib/foo.so"], },
+		arm64: { srcs: ["lib64/foo.so"], },
+	},
+	bazel_module: { bp2build_available: true },
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_prebuilt_library_shared", "libfoo", AttrNameToString{
+				"shared_library": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": "lib/foo.so",
+        "//build/bazel_common_rules/platforms/arch:arm64": "lib64/foo.so",
+        "//conditions:default": None,
+    })`,
+				"export_includes": `["include"]`,
+			}),
+		},
+	})
+}
@@ -171,6 +171,54 @@ cc_test {
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
 				"gtest":          "False",
+				"isolated":       "False",
+				"local_includes": `["."]`,
+				"srcs":           `["test.cpp"]`,
+				"runs_on": `[
+        "host_without_device",
+        "device",
+    ]`,
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": [
+            "memtag_heap",
+            "diag_memtag_heap",
+        ],
+        "//conditions:default": [],
+    })`,
+			},
+			},
+		},
+	})
+}
+
+func TestCcTestDataLibs(t *testing.T) {
+	runCcTestTestCase(t, ccTestBp2buildTestCase{
+		description:             "cc test with data_libs",
+		stubbedBuildDefinitions: []string{"libfoo", "libbar"},
+		blueprint: `
+cc_test {
+    name: "mytest",
+    host_supported: true,
+    srcs: ["test.cpp"],
+    gtest: false,
+    isolated: false,
+    data_libs: ["libfoo"],
+    target: {
+        android: {
+            data_libs: ["libbar"],
+        },
+    },
+}
+` + simpleModule("cc_library", "libfoo") +
+			simpleModule("cc_library", "libbar"),
+		targets: []testBazelTarget{
+			{"cc_test", "mytest", AttrNameToString{
+				"data": `[":libfoo"] + select({
+        "//build/bazel_common_rules/platforms/os:android": [":libbar"],
+        "//conditions:default": [],
+    })`,
+				"gtest":          "False",
+				"isolated":       "False",
 				"local_includes": `["."]`,
 				"srcs":           `["test.cpp"]`,
 				"runs_on": `[
@@ -328,6 +376,7 @@ cc_test {
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
 				"auto_generate_test_config": "True",
+				"isolated":                  "True",
 				"local_includes":            `["."]`,
 				"srcs":                      `["test.cpp"]`,
 				"target_compatible_with":    `["//build/bazel_common_rules/platforms/os:android"]`,
@@ -403,6 +452,7 @@ cc_test {
 			simpleModule("cc_library", "liblog"),
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
+				"isolated":               "True",
 				"local_includes":         `["."]`,
 				"srcs":                   `["test.cpp"]`,
 				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
@@ -493,6 +543,7 @@ cc_test {
 			simpleModule("cc_library", "liblog"),
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
+				"isolated":               "True",
 				"local_includes":         `["."]`,
 				"srcs":                   `["test.cpp"]`,
 				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
@@ -530,6 +581,7 @@ cc_test {
 			simpleModule("cc_library", "liblog"),
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
+				"isolated":               "True",
 				"local_includes":         `["."]`,
 				"srcs":                   `["test.cpp"]`,
 				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
@@ -567,6 +619,7 @@ cc_test {
 			simpleModule("cc_library", "liblog"),
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
+				"isolated":               "True",
 				"local_includes":         `["."]`,
 				"srcs":                   `["test.cpp"]`,
 				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
@@ -613,6 +666,7 @@ cc_test {
 			simpleModule("cc_library", "liblog"),
 		targets: []testBazelTarget{
 			{"cc_test", "mytest", AttrNameToString{
+				"isolated":               "True",
 				"local_includes":         `["."]`,
 				"srcs":                   `["test.cpp"]`,
 				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
@@ -631,3 +685,75 @@ cc_test {
 		},
 	})
 }
+
+func TestCcBenchmarkData(t *testing.T) {
+	RunBp2BuildTestCase(t, registerCcTestModuleTypes, Bp2buildTestCase{
+		Description:                "cc_benchmark with data",
+		ModuleTypeUnderTest:        "cc_benchmark",
+		ModuleTypeUnderTestFactory: cc.BenchmarkFactory,
+		StubbedBuildDefinitions:    []string{"libgoogle-benchmark", "data_mod"},
+		Blueprint: `
+cc_benchmark {
+    name: "mybench",
+    srcs: ["bench.cpp"],
+    data: [":data_mod", "file.txt"],
+}
+` + simpleModule("cc_library_static", "libgoogle-benchmark") +
+			simpleModule("genrule", "data_mod"),
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("cc_binary", "mybench", AttrNameToString{
+				"data": `[
+        ":data_mod",
+        "file.txt",
+    ]`,
+				"deps":           `[":libgoogle-benchmark"]`,
+				"local_includes": `["."]`,
+				"srcs":           `["bench.cpp"]`,
+			}),
+		},
+	})
+}
+
+func TestCcTest_TestSuites(t *testing.T) {
+	runCcTestTestCase(t, ccTestBp2buildTestCase{
+		description:             "cc test with test_suites",
+		stubbedBuildDefinitions: []string{"libgtest_main", "libgtest"},
+		blueprint: `
+cc_test {
+	name: "mytest",
+	srcs: ["test.cpp"],
+	test_suites: ["general-tests"],
+	arch: {
+		arm64: {
+			test_suites: ["device-tests"],
+		},
+	},
+}
+` + simpleModule("cc_library_static", "libgtest_main") +
+			simpleModule("cc_library_static", "libgtest"),
+		targets: []testBazelTarget{
+			{"cc_test", "mytest", AttrNameToString{
+				"local_includes":         `["."]`,
+				"srcs":                   `["test.cpp"]`,
+				"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
+				"deps": `[
+        ":libgtest_main",
+        ":libgtest",
+    ]`,
+				"runs_on": `["device"]`,
+				"test_suites": `["general-tests"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["device-tests"],
+        "//conditions:default": [],
+    })`,
+				"features": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": [
+            "memtag_heap",
+            "diag_memtag_heap",
+        ],
+        "//conditions:default": [],
+    })`,
+			},
+			},
+		},
+	})
+}
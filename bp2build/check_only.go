@@ -0,0 +1,73 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// staleBazelFiles compares the files bp2build would generate under dir with
+// the ones currently on disk, without writing anything. It returns one line
+// per file that is missing, has different contents, or, if bp2build owns dir,
+// would be deleted by a regular bp2build run, sorted by path.
+func staleBazelFiles(dir string, files []BazelFile, ownsDir bool) ([]string, error) {
+	onDisk := make(map[string]bool)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.IsDir() {
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			onDisk[relPath] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, f := range files {
+		relPath := filepath.Join(f.Dir, f.Basename)
+		if !onDisk[relPath] {
+			stale = append(stale, fmt.Sprintf("%s: missing", relPath))
+			continue
+		}
+		delete(onDisk, relPath)
+		contents, err := os.ReadFile(filepath.Join(dir, relPath))
+		if err != nil {
+			return nil, err
+		}
+		if string(contents) != f.Contents {
+			stale = append(stale, fmt.Sprintf("%s: out of date", relPath))
+		}
+	}
+	if ownsDir {
+		for relPath := range onDisk {
+			stale = append(stale, fmt.Sprintf("%s: no longer generated", relPath))
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
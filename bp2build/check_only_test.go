@@ -0,0 +1,83 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStaleBazelFiles(t *testing.T) {
+	dir := t.TempDir()
+	onDisk := map[string]string{
+		"a/BUILD.bazel":   "unchanged",
+		"b/BUILD.bazel":   "old contents",
+		"c/BUILD.bazel":   "no longer generated",
+		"WORKSPACE.bazel": "",
+	}
+	for path, contents := range onDisk {
+		absPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(absPath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []BazelFile{
+		newFile("a", "BUILD.bazel", "unchanged"),
+		newFile("b", "BUILD.bazel", "new contents"),
+		newFile("d", "BUILD.bazel", "new package"),
+		newFile("", "WORKSPACE.bazel", ""),
+	}
+	got, err := staleBazelFiles(dir, files, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"b/BUILD.bazel: out of date",
+		"c/BUILD.bazel: no longer generated",
+		"d/BUILD.bazel: missing",
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// Files of directories bp2build doesn't own, like soong_injection, are
+	// not reported when they aren't generated.
+	got, err = staleBazelFiles(dir, files, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		"b/BUILD.bazel: out of date",
+		"d/BUILD.bazel: missing",
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	got, err = staleBazelFiles(filepath.Join(dir, "nonexistent"), files[:1], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"a/BUILD.bazel: missing"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
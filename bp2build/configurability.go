@@ -156,59 +156,84 @@ var (
 	bazelNone      = "None"
 )
 
-// prettyPrintAttribute converts an Attribute to its Bazel syntax. May contain
-// select statements.
-func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
-	var value reflect.Value
-	// configurableAttrs is the list of individual select statements to be
-	// concatenated together. These select statements should be along different
-	// axes. For example, one element may be
+// resolvedAttribute holds the plain value and the select() statements an
+// attribute is rendered as.
+type resolvedAttribute struct {
+	// attribute is the attribute the selects were built from, after collapsing
+	// its axes if it is a single-valued attribute.
+	attribute interface{}
+	value     reflect.Value
+	// selects is the list of individual select statements to be concatenated
+	// together. These select statements should be along different axes. For
+	// example, one element may be
 	// `select({"//color:red": "one", "//color:green": "two"})`, and the second
 	// element may be `select({"//animal:cat": "three", "//animal:dog": "four"}).
 	// These selects should be sorted by axis identifier.
-	var configurableAttrs []selects
-	var prepend bool
-	var defaultSelectValue *string
-	var emitZeroValues bool
+	selects            []selects
+	prepend            bool
+	defaultSelectValue *string
+	emitZeroValues     bool
 	// If true, print the default attribute value, even if the attribute is zero.
-	shouldPrintDefault := false
+	shouldPrintDefault bool
+}
+
+// resolveAttribute computes the plain value and the select() statements the
+// given attribute is rendered as.
+func resolveAttribute(v bazel.Attribute) (resolvedAttribute, error) {
+	var ret resolvedAttribute
 	switch list := v.(type) {
 	case bazel.StringAttribute:
 		if err := list.Collapse(); err != nil {
-			return "", err
+			return ret, err
 		}
-		value, configurableAttrs = getStringValue(list)
-		defaultSelectValue = &bazelNone
+		ret.value, ret.selects = getStringValue(list)
+		ret.defaultSelectValue = &bazelNone
+		ret.attribute = &list
 	case bazel.StringListAttribute:
-		value, configurableAttrs, prepend = getStringListValues(list)
-		defaultSelectValue = &emptyBazelList
+		ret.value, ret.selects, ret.prepend = getStringListValues(list)
+		ret.defaultSelectValue = &emptyBazelList
+		ret.attribute = &list
 	case bazel.LabelListAttribute:
-		value, configurableAttrs, prepend = getLabelListValues(list)
-		emitZeroValues = list.EmitEmptyList
-		defaultSelectValue = &emptyBazelList
-		if list.ForceSpecifyEmptyList && (!value.IsNil() || list.HasConfigurableValues()) {
-			shouldPrintDefault = true
+		ret.value, ret.selects, ret.prepend = getLabelListValues(list)
+		ret.emitZeroValues = list.EmitEmptyList
+		ret.defaultSelectValue = &emptyBazelList
+		if list.ForceSpecifyEmptyList && (!ret.value.IsNil() || list.HasConfigurableValues()) {
+			ret.shouldPrintDefault = true
 		}
+		ret.attribute = &list
 	case bazel.LabelAttribute:
 		if err := list.Collapse(); err != nil {
-			return "", err
+			return ret, err
 		}
-		value, configurableAttrs = getLabelValue(list)
-		defaultSelectValue = &bazelNone
+		ret.value, ret.selects = getLabelValue(list)
+		ret.defaultSelectValue = &bazelNone
+		ret.attribute = &list
 	case bazel.BoolAttribute:
 		if err := list.Collapse(); err != nil {
-			return "", err
+			return ret, err
 		}
-		value, configurableAttrs = getBoolValue(list)
-		defaultSelectValue = &bazelNone
+		ret.value, ret.selects = getBoolValue(list)
+		ret.defaultSelectValue = &bazelNone
+		ret.attribute = &list
 	default:
-		return "", fmt.Errorf("Not a supported Bazel attribute type: %s", v)
+		return ret, fmt.Errorf("Not a supported Bazel attribute type: %s", v)
+	}
+
+	ret.value, ret.selects = collapseSelects(ret.value, ret.selects, ret.prepend, ret.emitZeroValues)
+	return ret, nil
+}
+
+// prettyPrintAttribute converts an Attribute to its Bazel syntax. May contain
+// select statements.
+func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
+	resolved, err := resolveAttribute(v)
+	if err != nil {
+		return "", err
 	}
 
-	var err error
 	ret := ""
-	if value.Kind() != reflect.Invalid {
-		s, err := prettyPrint(value, indent, false) // never emit zero values for the base value
+	if resolved.value.Kind() != reflect.Invalid {
+		s, err := prettyPrint(resolved.value, indent, false) // never emit zero values for the base value
 		if err != nil {
 			return ret, err
 		}
@@ -217,7 +242,7 @@ func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
 	}
 	// Convenience function to prepend/append selects components to an attribute value.
 	concatenateSelects := func(selectsData selects, defaultValue *string, s string, prepend bool) (string, error) {
-		selectMap, err := prettyPrintSelectMap(selectsData, defaultValue, indent, emitZeroValues)
+		selectMap, err := prettyPrintSelectMap(selectsData, defaultValue, indent, resolved.emitZeroValues)
 		if err != nil {
 			return "", err
 		}
@@ -235,39 +260,108 @@ func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
 		return left, nil
 	}
 
-	for _, configurableAttr := range configurableAttrs {
-		ret, err = concatenateSelects(configurableAttr, defaultSelectValue, ret, prepend)
+	for _, configurableAttr := range resolved.selects {
+		ret, err = concatenateSelects(configurableAttr, resolved.defaultSelectValue, ret, resolved.prepend)
 		if err != nil {
 			return "", err
 		}
 	}
 
-	if ret == "" && shouldPrintDefault {
-		return *defaultSelectValue, nil
+	if ret == "" && resolved.shouldPrintDefault {
+		return *resolved.defaultSelectValue, nil
 	}
 	return ret, nil
 }
 
-// prettyPrintSelectMap converts a map of select keys to reflected Values as a generic way
-// to construct a select map for any kind of attribute type.
-func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *string, indent int, emitZeroValues bool) (string, error) {
-	if selectMap == nil {
-		return "", nil
+// collapseSelects simplifies selects whose branches all have the same value as
+// //conditions:default, as their value doesn't depend on the configuration. For
+// list attributes the common value is merged into the plain value instead of
+// being concatenated as a separate list; for other attributes it replaces the
+// plain value if that is unset. A list select is only merged if every select
+// before it was merged too, as the flags would otherwise be reordered. The
+// remaining selects are returned in order.
+func collapseSelects(value reflect.Value, configurableAttrs []selects, prepend bool, emitZeroValues bool) (reflect.Value, []selects) {
+	var ret []selects
+	for _, selectMap := range configurableAttrs {
+		common, ok := commonSelectValue(selectMap)
+		if !ok {
+			ret = append(ret, selectMap)
+			continue
+		}
+		if value.Kind() == reflect.Slice && common.Kind() == reflect.Slice && value.Type() == common.Type() {
+			if common.Len() == 0 {
+				if emitZeroValues {
+					// Keep the select so that the empty list is still printed.
+					ret = append(ret, selectMap)
+				}
+				continue
+			}
+			if len(ret) > 0 {
+				// A select is concatenated between the plain value and this one, keep it in place.
+				ret = append(ret, selectMap)
+				continue
+			}
+			// Copy into a new slice so the attribute's own value isn't modified.
+			merged := reflect.MakeSlice(value.Type(), 0, value.Len()+common.Len())
+			if prepend {
+				merged = reflect.AppendSlice(reflect.AppendSlice(merged, common), value)
+			} else {
+				merged = reflect.AppendSlice(reflect.AppendSlice(merged, value), common)
+			}
+			value = merged
+		} else if value.Kind() != reflect.Slice && isZero(value) {
+			value = common
+		} else {
+			ret = append(ret, selectMap)
+		}
 	}
+	return value, ret
+}
 
-	var selects string
+// commonSelectValue returns the value of //conditions:default if every branch of
+// the select has that same value.
+func commonSelectValue(selectMap selects) (reflect.Value, bool) {
+	defaultValue, ok := selectMap[bazel.ConditionsDefaultSelectKey]
+	if !ok || !defaultValue.IsValid() {
+		return reflect.Value{}, false
+	}
+	for _, value := range selectMap {
+		if !value.IsValid() || !reflect.DeepEqual(value.Interface(), defaultValue.Interface()) {
+			return reflect.Value{}, false
+		}
+	}
+	return defaultValue, true
+}
+
+// printedSelectKeys returns the sorted keys of the branches of the select, other
+// than //conditions:default, which are printed.
+func printedSelectKeys(selectMap selects, emitZeroValues bool) []string {
+	var ret []string
 	for _, selectKey := range android.SortedKeys(selectMap) {
 		if selectKey == bazel.ConditionsDefaultSelectKey {
 			// Handle default condition later.
 			continue
 		}
-		value := selectMap[selectKey]
-		if isZero(value) && !emitZeroValues && isZero(selectMap[bazel.ConditionsDefaultSelectKey]) {
+		if isZero(selectMap[selectKey]) && !emitZeroValues && isZero(selectMap[bazel.ConditionsDefaultSelectKey]) {
 			// Ignore zero values to not generate empty lists. However, always note zero values if
 			// the default value is non-zero.
 			continue
 		}
-		s, err := prettyPrintSelectEntry(value, selectKey, indent, true)
+		ret = append(ret, selectKey)
+	}
+	return ret
+}
+
+// prettyPrintSelectMap converts a map of select keys to reflected Values as a generic way
+// to construct a select map for any kind of attribute type.
+func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *string, indent int, emitZeroValues bool) (string, error) {
+	if selectMap == nil {
+		return "", nil
+	}
+
+	var selects string
+	for _, selectKey := range printedSelectKeys(selectMap, emitZeroValues) {
+		s, err := prettyPrintSelectEntry(selectMap[selectKey], selectKey, indent, true)
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,72 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"encoding/json"
+
+	"android/soong/android"
+)
+
+// conversionManifestFileName is the name of the file, in the metrics package of
+// the soong_injection directory, listing the targets each converted module
+// was converted to.
+const conversionManifestFileName = "conversion_manifest.json"
+
+// convertedTarget describes a target generated for a converted module.
+type convertedTarget struct {
+	// Label is the fully qualified label of the target.
+	Label string `json:"label"`
+	// RuleClass is the kind of the target, e.g. "cc_library_shared".
+	RuleClass string `json:"rule_class"`
+	// ModuleType is the type of the module the target was converted from.
+	ModuleType string `json:"module_type"`
+	// Axes are the names of the configuration axes the attributes of the target
+	// select() on, e.g. "arch" or "os".
+	Axes []string `json:"axes,omitempty"`
+}
+
+// conversionManifestFile returns a JSON file mapping each module converted by
+// bp2build to the targets generated for it, so that mixed builds can consume the
+// result of the conversion rather than re-deriving it. Modules are keyed by
+// <directory>:<name>, as modules of different namespaces may share a name.
+func conversionManifestFile(buildToTargets map[string]BazelTargets) (BazelFile, error) {
+	manifest := map[string][]convertedTarget{}
+	for _, dir := range android.SortedKeys(buildToTargets) {
+		for _, target := range buildToTargets[dir] {
+			if target.soongModuleName == "" {
+				continue
+			}
+			axes := map[string]bool{}
+			for _, branches := range target.selectBranches {
+				for _, branch := range branches {
+					axes[branch.Axis] = true
+				}
+			}
+			key := target.soongModuleDir + ":" + target.soongModuleName
+			manifest[key] = append(manifest[key], convertedTarget{
+				Label:      target.Label(),
+				RuleClass:  target.ruleClass,
+				ModuleType: target.soongModuleType,
+				Axes:       android.SortedKeys(axes),
+			})
+		}
+	}
+	contents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return BazelFile{}, err
+	}
+	return newFile("metrics", conversionManifestFileName, string(contents)+"\n"), nil
+}
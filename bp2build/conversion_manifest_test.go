@@ -0,0 +1,92 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/bazel"
+)
+
+func TestConversionManifestFile(t *testing.T) {
+	copts := bazel.MakeStringListAttribute([]string{"-Wall"})
+	copts.SetSelectValue(bazel.ArchConfigurationAxis, "arm64", []string{"-DARM64"})
+	attrs := struct {
+		Copts bazel.StringListAttribute
+	}{
+		Copts: copts,
+	}
+	static, err := generateBazelTarget(nil, bTarget{
+		targetName:     "foo_bp2build_cc_library_static",
+		targetPackage:  "pkg",
+		bazelRuleClass: "cc_library_static",
+		bazelAttributes: []interface{}{
+			&attrs,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	static.soongModuleName = "foo"
+	static.soongModuleDir = "pkg"
+	static.soongModuleType = "cc_library"
+
+	file, err := conversionManifestFile(map[string]BazelTargets{
+		"pkg": {
+			static,
+			{name: "foo", packageName: "pkg", ruleClass: "cc_library_shared", soongModuleName: "foo", soongModuleDir: "pkg", soongModuleType: "cc_library"},
+			// Targets not converted from a module, e.g. product config targets, are not listed.
+			{name: "product", packageName: "pkg", ruleClass: "android_product"},
+		},
+		// A module of another namespace with the same name is listed separately.
+		"other": {
+			{name: "foo", packageName: "other", ruleClass: "filegroup", soongModuleName: "foo", soongModuleDir: "other", soongModuleType: "filegroup"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Dir != "metrics" || file.Basename != conversionManifestFileName {
+		t.Errorf("Unexpected conversion manifest file %s/%s", file.Dir, file.Basename)
+	}
+	expected := `{
+  "other:foo": [
+    {
+      "label": "//other:foo",
+      "rule_class": "filegroup",
+      "module_type": "filegroup"
+    }
+  ],
+  "pkg:foo": [
+    {
+      "label": "//pkg:foo_bp2build_cc_library_static",
+      "rule_class": "cc_library_static",
+      "module_type": "cc_library",
+      "axes": [
+        "arch"
+      ]
+    },
+    {
+      "label": "//pkg:foo",
+      "rule_class": "cc_library_shared",
+      "module_type": "cc_library"
+    }
+  ]
+}
+`
+	if file.Contents != expected {
+		t.Errorf("Expected conversion manifest:\n%s\ngot:\n%s", expected, file.Contents)
+	}
+}
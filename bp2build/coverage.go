@@ -0,0 +1,283 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/cc"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// The statuses of a property in the property coverage report.
+const (
+	// The BUILD file changes when the property is set.
+	propertyConverted = "converted"
+	// The module is not converted when the property is set.
+	propertyUnsupported = "unsupported"
+	// The BUILD file is the same whether the property is set or not.
+	propertyNotConverted = "not converted"
+	// The conversion failed when the property was set, e.g. because the probe
+	// value is invalid for the property.
+	propertyProbeFailed = "probe failed"
+)
+
+// probeModuleName is the name of the module converted to probe a property.
+const probeModuleName = "bp2build_coverage_probe"
+
+// PropertyCoverage describes whether bp2build converts a property of a module type.
+type PropertyCoverage struct {
+	ModuleType string
+	// Property is the name of the property in an Android.bp file, e.g. "sanitize.address".
+	Property string
+	// Status is one of "converted", "unsupported", "not converted" or "probe failed".
+	Status string
+	// Detail is the reason the module was not converted, or the error of the probe.
+	Detail string
+}
+
+// probedProperty is a property of a module type along with the values it is set to
+// when probing its conversion.
+type probedProperty struct {
+	name   string
+	values []string
+}
+
+// probedProperties returns the properties of the given property struct which can be
+// set in an Android.bp file, flattening the nested property structs. Interface
+// fields, i.e. the arch, multilib and target properties, are populated at runtime
+// and are not probed.
+func probedProperties(prefix string, structType reflect.Type) []probedProperty {
+	var properties []probedProperty
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if shouldSkipStructField(field) {
+			continue
+		}
+		name := prefix
+		if !field.Anonymous {
+			name = proptools.PropertyNameForField(field.Name)
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+		}
+		t := field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		var values []string
+		switch t.Kind() {
+		case reflect.Bool:
+			// Probe both values, as the default of a property may be either.
+			values = []string{"true", "false"}
+		case reflect.String:
+			values = []string{`"probe"`}
+		case reflect.Int, reflect.Int64, reflect.Uint:
+			values = []string{"1"}
+		case reflect.Slice:
+			if t.Elem().Kind() != reflect.String {
+				continue
+			}
+			values = []string{`["probe"]`}
+		case reflect.Struct:
+			properties = append(properties, probedProperties(name, t)...)
+			continue
+		default:
+			continue
+		}
+		properties = append(properties, probedProperty{name: name, values: values})
+	}
+	return properties
+}
+
+// moduleTypeProbedProperties returns the properties of the module created by the
+// given factory, except the ones identifying the probe module.
+func moduleTypeProbedProperties(factory android.ModuleFactory) []probedProperty {
+	propertiesByName := make(map[string]probedProperty)
+	for _, p := range factory().GetProperties() {
+		for _, prop := range probedProperties("", reflect.ValueOf(p).Elem().Type()) {
+			propertiesByName[prop.name] = prop
+		}
+	}
+	var properties []probedProperty
+	for _, name := range android.SortedKeys(propertiesByName) {
+		if name == "name" || strings.HasPrefix(name, "bazel_module.") {
+			continue
+		}
+		properties = append(properties, propertiesByName[name])
+	}
+	return properties
+}
+
+// probeBlueprint returns an Android.bp file defining a module of the given type
+// to convert, with the given property set to value if property is not empty.
+func probeBlueprint(moduleType, property, value string) string {
+	bp := fmt.Sprintf("%s {\n    name: %q,\n    bazel_module: { bp2build_available: true },\n", moduleType, probeModuleName)
+	if property != "" {
+		path := strings.Split(property, ".")
+		prop := path[len(path)-1] + ": " + value
+		for i := len(path) - 2; i >= 0; i-- {
+			prop = path[i] + ": { " + prop + " }"
+		}
+		bp += "    " + prop + ",\n"
+	}
+	return bp + "}\n"
+}
+
+// probeResult is the result of the conversion of a probe module.
+type probeResult struct {
+	// targets are the generated BUILD files contents, keyed by directory.
+	targets string
+	// unconvertedDetail is the reason the module was not converted, if it was not.
+	unconvertedDetail string
+	converted         bool
+}
+
+// probeConversion converts the probe module of the given Android.bp file with a
+// context registering the cc build components and the given module type.
+func probeConversion(buildDir, moduleType string, factory android.ModuleFactory, bp string) (result probeResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	config := android.TestConfig(buildDir, nil, bp, nil)
+	ctx := android.NewTestContext(config)
+	cc.RegisterCCBuildComponents(ctx)
+	ctx.RegisterModuleType(moduleType, factory)
+	ctx.RegisterForBazelConversion()
+
+	if _, errs := ctx.ParseFileList(".", []string{"Android.bp"}); len(errs) > 0 {
+		return probeResult{}, errs[0]
+	}
+	if _, errs := ctx.ResolveDependencies(config); len(errs) > 0 {
+		return probeResult{}, errs[0]
+	}
+	codegenCtx := NewCodegenContext(config, ctx.Context, Bp2Build, "")
+	res, errs := GenerateBazelTargets(codegenCtx, false)
+	if len(errs) > 0 {
+		return probeResult{}, errs[0]
+	}
+
+	var targets strings.Builder
+	for _, dir := range android.SortedKeys(res.buildFileToTargets) {
+		fmt.Fprintf(&targets, "# %s\n%s\n", dir, res.buildFileToTargets[dir].String())
+	}
+	result.targets = targets.String()
+	if reason, ok := res.metrics.serialized.UnconvertedModules[probeModuleName]; ok {
+		result.unconvertedDetail = reason.Type.String()
+		if reason.Detail != "" {
+			result.unconvertedDetail += ": " + reason.Detail
+		}
+	} else {
+		result.converted = true
+	}
+	return result, nil
+}
+
+// moduleTypePropertyCoverage returns whether bp2build converts each property of
+// the given module type. A property is converted if setting it in the Android.bp
+// file changes the generated BUILD files.
+func moduleTypePropertyCoverage(buildDir, moduleType string, factory android.ModuleFactory) []PropertyCoverage {
+	var coverage []PropertyCoverage
+	baseline, baselineErr := probeConversion(buildDir, moduleType, factory, probeBlueprint(moduleType, "", ""))
+	for _, prop := range moduleTypeProbedProperties(factory) {
+		c := PropertyCoverage{ModuleType: moduleType, Property: prop.name, Status: propertyNotConverted}
+		if baselineErr != nil {
+			c.Status, c.Detail = propertyProbeFailed, baselineErr.Error()
+			coverage = append(coverage, c)
+			continue
+		}
+		var probeErr error
+		for _, value := range prop.values {
+			result, err := probeConversion(buildDir, moduleType, factory, probeBlueprint(moduleType, prop.name, value))
+			if err != nil {
+				probeErr = err
+			} else if baseline.converted && !result.converted {
+				c.Status, c.Detail = propertyUnsupported, result.unconvertedDetail
+			} else if result.targets != baseline.targets {
+				c.Status, c.Detail = propertyConverted, ""
+				break
+			}
+		}
+		if c.Status == propertyNotConverted && probeErr != nil {
+			c.Status, c.Detail = propertyProbeFailed, probeErr.Error()
+		}
+		coverage = append(coverage, c)
+	}
+	return coverage
+}
+
+// CcPropertyCoverage returns whether bp2build converts each property of the
+// registered cc module types, to prioritize the conversion gaps.
+func CcPropertyCoverage(buildDir string) []PropertyCoverage {
+	var coverage []PropertyCoverage
+	factories := android.ModuleTypeFactories()
+	for _, moduleType := range android.SortedKeys(factories) {
+		factory := factories[moduleType]
+		if _, ok := factory().(*cc.Module); !ok {
+			continue
+		}
+		coverage = append(coverage, moduleTypePropertyCoverage(buildDir, moduleType, factory)...)
+	}
+	return coverage
+}
+
+func writePropertyCoverageCsv(w io.Writer, coverage []PropertyCoverage) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Write([]string{"module_type", "property", "status", "detail"})
+	for _, c := range coverage {
+		csvWriter.Write([]string{c.ModuleType, c.Property, c.Status, c.Detail})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func writePropertyCoverageHtml(w io.Writer, coverage []PropertyCoverage) error {
+	var b strings.Builder
+	b.WriteString("<html>\n<head><title>bp2build cc property coverage</title></head>\n<body>\n<table border=\"1\">\n")
+	b.WriteString("<tr><th>module type</th><th>property</th><th>status</th><th>detail</th></tr>\n")
+	for _, c := range coverage {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(c.ModuleType), html.EscapeString(c.Property),
+			html.EscapeString(c.Status), html.EscapeString(c.Detail))
+	}
+	b.WriteString("</table>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCcPropertyCoverage writes the property coverage of the cc module types to
+// the given file, as an HTML table if its extension is .html and as CSV otherwise.
+func WriteCcPropertyCoverage(path, buildDir string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	coverage := CcPropertyCoverage(buildDir)
+	if strings.HasSuffix(path, ".html") {
+		return writePropertyCoverageHtml(f, coverage)
+	}
+	return writePropertyCoverageCsv(f, coverage)
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"strings"
+	"testing"
+
+	"android/soong/cc"
+)
+
+func TestModuleTypePropertyCoverage(t *testing.T) {
+	coverage := moduleTypePropertyCoverage(buildDir, "custom", customModuleFactoryHostAndDevice)
+	statuses := make(map[string]string)
+	for _, c := range coverage {
+		if c.ModuleType != "custom" {
+			t.Errorf("expected module type custom, got %q", c.ModuleType)
+		}
+		statuses[c.Property] = c.Status
+	}
+	for property, expected := range map[string]string{
+		"string_ptr_prop":              propertyConverted,
+		"string_list_prop":             propertyConverted,
+		"embedded_prop":                propertyConverted,
+		"other_embedded_prop":          propertyConverted,
+		"bool_prop":                    propertyNotConverted,
+		"int64_ptr_prop":               propertyNotConverted,
+		"nested_props.nested_prop":     propertyNotConverted,
+		"nested_props_ptr.nested_prop": propertyNotConverted,
+		"does_not_convert_to_bazel":    propertyUnsupported,
+	} {
+		if actual, ok := statuses[property]; !ok {
+			t.Errorf("expected the coverage of property %q", property)
+		} else if actual != expected {
+			t.Errorf("expected property %q to be %q, got %q", property, expected, actual)
+		}
+	}
+	for _, property := range []string{"name", "int_prop", "bazel_module.bp2build_available"} {
+		if _, ok := statuses[property]; ok {
+			t.Errorf("expected no coverage of property %q", property)
+		}
+	}
+}
+
+func TestCcLibraryPropertyCoverage(t *testing.T) {
+	coverage := moduleTypePropertyCoverage(buildDir, "cc_library", cc.LibraryFactory)
+	statuses := make(map[string]PropertyCoverage)
+	for _, c := range coverage {
+		statuses[c.Property] = c
+	}
+	for property, expected := range map[string]string{
+		"srcs":          propertyConverted,
+		"cflags":        propertyConverted,
+		"shared.srcs":   propertyConverted,
+		"static.cflags": propertyConverted,
+	} {
+		if actual, ok := statuses[property]; !ok {
+			t.Errorf("expected the coverage of property %q", property)
+		} else if actual.Status != expected {
+			t.Errorf("expected property %q to be %q, got %q (%s)", property, expected, actual.Status, actual.Detail)
+		}
+	}
+}
+
+func TestWritePropertyCoverageCsv(t *testing.T) {
+	var b strings.Builder
+	err := writePropertyCoverageCsv(&b, []PropertyCoverage{
+		{ModuleType: "cc_library", Property: "srcs", Status: propertyConverted},
+		{ModuleType: "cc_library", Property: "sanitize.scs", Status: propertyUnsupported, Detail: "PROPERTY_UNSUPPORTED: sanitize.scs"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `module_type,property,status,detail
+cc_library,srcs,converted,
+cc_library,sanitize.scs,unsupported,PROPERTY_UNSUPPORTED: sanitize.scs
+`
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint/proptools"
+)
+
+const (
+	// defaultsBzlFileName is the name of the per-package .bzl file holding the
+	// macros of the defaults modules of the package.
+	defaultsBzlFileName = "bp2build_defaults.bzl"
+
+	// minDefaultsConsumers is the number of targets of a rule class a defaults
+	// module must be applied to before a macro is generated for them.
+	minDefaultsConsumers = 2
+)
+
+// defaultsModule identifies a defaults module, e.g. a cc_defaults, applied to
+// the Soong module a Bazel target was converted from.
+type defaultsModule struct {
+	name string
+	dir  string
+}
+
+// defaultsMacro identifies the macro instantiating the targets of a rule class
+// converted from the modules a defaults module is applied to.
+type defaultsMacro struct {
+	defaultsModule
+	ruleClass string
+}
+
+var nonIdentifierChars = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// setPropertyNames returns the names of the properties set in the given property
+// structs, including the names of the properties nested in them, e.g. "arch", "arm64"
+// and "cflags" for arch.arm64.cflags.
+func setPropertyNames(properties []interface{}) map[string]bool {
+	names := map[string]bool{}
+	var visit func(v reflect.Value) bool
+	visit = func(v reflect.Value) bool {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return false
+			}
+			v = v.Elem()
+			if v.Kind() != reflect.Struct && v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+				// Pointers to values, e.g. *bool, are set even to the zero value.
+				return true
+			}
+		}
+		if v.Kind() != reflect.Struct {
+			return !v.IsZero()
+		}
+		set := false
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() || !visit(v.Field(i)) {
+				continue
+			}
+			set = true
+			if !t.Field(i).Anonymous {
+				names[proptools.PropertyNameForField(t.Field(i).Name)] = true
+			}
+		}
+		return set
+	}
+	for _, p := range properties {
+		visit(reflect.ValueOf(p))
+	}
+	return names
+}
+
+// contributesAttribute returns whether the given attribute is converted from one of
+// the given properties, see soongPropertiesOfAttribute. Attributes computed from
+// several properties are not attributed to any of them.
+func contributesAttribute(properties map[string]bool, attribute string) bool {
+	soongProperties, ok := soongPropertiesOfAttribute[attribute]
+	if !ok {
+		soongProperties = []string{attribute}
+	}
+	for _, p := range soongProperties {
+		if properties[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// exportDefaultsAttributes exports the attributes converted from the properties
+// of a defaults module and rendered identically by all the targets of a rule class
+// converted from the modules it is applied to (e.g. the copts coming from a
+// cc_defaults) into a macro of a .bzl file in the package of the defaults module.
+// The macro instantiates the rule with these attributes and the ones it is called
+// with, and the targets are rewritten in-place to call it instead of the rule. It
+// returns the .bzl files to write, and adds an empty package for those without
+// targets so that the .bzl files can be loaded.
+func exportDefaultsAttributes(buildToTargets map[string]BazelTargets) []BazelFile {
+	consumers := map[defaultsMacro][]*BazelTarget{}
+	for _, dir := range android.SortedKeys(buildToTargets) {
+		targets := buildToTargets[dir]
+		for i := range targets {
+			for _, d := range targets[i].defaults {
+				m := defaultsMacro{defaultsModule: d, ruleClass: targets[i].ruleClass}
+				consumers[m] = append(consumers[m], &targets[i])
+			}
+		}
+	}
+
+	var macros []defaultsMacro
+	for m := range consumers {
+		macros = append(macros, m)
+	}
+	sort.Slice(macros, func(i, j int) bool {
+		if macros[i].dir != macros[j].dir {
+			return macros[i].dir < macros[j].dir
+		}
+		if macros[i].name != macros[j].name {
+			return macros[i].name < macros[j].name
+		}
+		return macros[i].ruleClass < macros[j].ruleClass
+	})
+
+	bzlLoads := map[string][]BazelLoad{}
+	bzlMacros := map[string]*strings.Builder{}
+	symbols := map[string]map[string]bool{}
+	// A target is only instantiated by the macro of the first of its defaults
+	// modules which has attributes in common with the other consumers, as a
+	// target can only be instantiated by a single macro.
+	for _, m := range macros {
+		var targets []*BazelTarget
+		for _, target := range consumers[m] {
+			if target.macro == "" {
+				targets = append(targets, target)
+			}
+		}
+		if len(targets) < minDefaultsConsumers {
+			continue
+		}
+		properties := targets[0].defaultsProperties[m.defaultsModule]
+		common := make(map[string]string, len(targets[0].attributes))
+		for name, value := range targets[0].attributes {
+			if contributesAttribute(properties, name) {
+				common[name] = value
+			}
+		}
+		for _, target := range targets[1:] {
+			for name, value := range common {
+				if other, ok := target.attributes[name]; !ok || other != value {
+					delete(common, name)
+				}
+			}
+		}
+		if len(common) == 0 {
+			continue
+		}
+
+		if symbols[m.dir] == nil {
+			symbols[m.dir] = map[string]bool{}
+			bzlMacros[m.dir] = &strings.Builder{}
+		}
+		// Defaults modules whose names only differ by non-identifier characters,
+		// e.g. foo-defaults and foo_defaults, are disambiguated by a suffix.
+		base := nonIdentifierChars.ReplaceAllString(m.name, "_") + "_" + m.ruleClass
+		symbol := base
+		for i := 2; symbols[m.dir][symbol]; i++ {
+			symbol = fmt.Sprintf("%s_%d", base, i)
+		}
+		symbols[m.dir][symbol] = true
+
+		rule := "native." + m.ruleClass
+		if load, ok := ruleClassLoad(targets[0]); ok {
+			rule = m.ruleClass
+			bzlLoads[m.dir] = append(bzlLoads[m.dir], load)
+		}
+		macro := bzlMacros[m.dir]
+		fmt.Fprintf(macro, "\n# Instantiates %s with the attributes of the modules using %s.\n", m.ruleClass, m.name)
+		fmt.Fprintf(macro, "def %s(name, **kwargs):\n    %s(\n        name = name,\n", symbol, rule)
+		for _, line := range strings.SplitAfter(propsToAttributes(common), "\n") {
+			if line != "" {
+				macro.WriteString("    " + line)
+			}
+		}
+		macro.WriteString("        **kwargs\n    )\n")
+
+		bzlLabel := "//" + m.dir + ":" + defaultsBzlFileName
+		if m.dir == "." || m.dir == "" {
+			bzlLabel = "//:" + defaultsBzlFileName
+		}
+		for _, target := range targets {
+			attrs := make(map[string]string, len(target.attributes)-len(common))
+			for name, value := range target.attributes {
+				if _, ok := common[name]; !ok {
+					attrs[name] = value
+				}
+			}
+			target.attributes = attrs
+			target.macro = symbol
+			target.content = ruleTargetContent(target.callee(), target.name, attrs)
+			target.loads = append(withoutRuleClassLoad(target), BazelLoad{
+				file:    bzlLabel,
+				symbols: []BazelLoadSymbol{{symbol: symbol}},
+			})
+		}
+	}
+
+	var files []BazelFile
+	for _, dir := range android.SortedKeys(bzlMacros) {
+		var bzl strings.Builder
+		bzl.WriteString(`# READ THIS FIRST:
+# This file was automatically generated by bp2build for the Bazel migration project.
+# Feel free to edit or test it, but do *not* check it into your version control system.
+`)
+		if loads := (BazelTargets{{loads: bzlLoads[dir]}}).LoadStatements(); loads != "" {
+			bzl.WriteString("\n" + loads + "\n")
+		}
+		bzl.WriteString(bzlMacros[dir].String())
+		files = append(files, newFile(dir, defaultsBzlFileName, bzl.String()))
+		if _, ok := buildToTargets[dir]; !ok {
+			buildToTargets[dir] = BazelTargets{}
+		}
+	}
+	return files
+}
+
+// ruleClassLoad returns the load of the rule class of the given target, which
+// is not loaded if it is a native rule.
+func ruleClassLoad(target *BazelTarget) (BazelLoad, bool) {
+	for _, load := range target.loads {
+		for _, symbol := range load.symbols {
+			if symbol.symbol == target.ruleClass && (symbol.alias == "" || symbol.alias == symbol.symbol) {
+				return BazelLoad{file: load.file, symbols: []BazelLoadSymbol{symbol}}, true
+			}
+		}
+	}
+	return BazelLoad{}, false
+}
+
+// withoutRuleClassLoad returns the loads of the given target, except the one of
+// its rule class.
+func withoutRuleClassLoad(target *BazelTarget) []BazelLoad {
+	var loads []BazelLoad
+	for _, load := range target.loads {
+		var symbols []BazelLoadSymbol
+		for _, symbol := range load.symbols {
+			if symbol.symbol != target.ruleClass {
+				symbols = append(symbols, symbol)
+			}
+		}
+		if len(symbols) > 0 {
+			loads = append(loads, BazelLoad{file: load.file, symbols: symbols})
+		}
+	}
+	return loads
+}
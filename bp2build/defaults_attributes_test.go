@@ -0,0 +1,166 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+func TestExportDefaultsAttributes(t *testing.T) {
+	archCopts := `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-DARM64"],
+        "//conditions:default": [],
+    })`
+	ruleLoad := BazelLoad{
+		file:    "//build/bazel/rules/cc:cc_library_static.bzl",
+		symbols: []BazelLoadSymbol{{symbol: "cc_library_static"}},
+	}
+	fooDashDefaults := defaultsModule{name: "foo-defaults", dir: "defaults"}
+	fooDefaults := defaultsModule{name: "foo_defaults", dir: "defaults"}
+	// foo-defaults sets cflags and stl, and foo_defaults only sets cflags.
+	defaultsProperties := map[defaultsModule]map[string]bool{
+		fooDashDefaults: {"arch": true, "arm64": true, "cflags": true, "stl": true},
+		fooDefaults:     {"arch": true, "arm64": true, "cflags": true},
+	}
+	newTarget := func(pkg, name string, attrs map[string]string, defaults ...defaultsModule) BazelTarget {
+		return BazelTarget{
+			name:               name,
+			packageName:        pkg,
+			ruleClass:          "cc_library_static",
+			content:            ruleTargetContent("cc_library_static", name, attrs),
+			loads:              []BazelLoad{ruleLoad},
+			attributes:         attrs,
+			defaults:           defaults,
+			defaultsProperties: defaultsProperties,
+		}
+	}
+	buildToTargets := map[string]BazelTargets{
+		"a": {
+			newTarget("a", "a", map[string]string{"copts": archCopts, "srcs": `["a.cpp"]`, "stl": `"none"`}, fooDashDefaults),
+			newTarget("a", "b", map[string]string{"copts": archCopts, "srcs": `["b.cpp"]`, "stl": `"none"`}, fooDashDefaults, fooDefaults),
+		},
+		"c": {
+			newTarget("c", "c", map[string]string{"copts": archCopts, "srcs": `["c.cpp"]`, "stl": `"none"`}, fooDefaults),
+			newTarget("c", "d", map[string]string{"copts": archCopts}),
+			newTarget("c", "e", map[string]string{"copts": archCopts, "srcs": `["e.cpp"]`, "stl": `"none"`}, fooDefaults),
+		},
+	}
+
+	files := exportDefaultsAttributes(buildToTargets)
+	if len(files) != 1 {
+		t.Fatalf("Expected one defaults file, got %d: %v", len(files), files)
+	}
+	if files[0].Dir != "defaults" || files[0].Basename != defaultsBzlFileName {
+		t.Errorf("Unexpected defaults file %s/%s", files[0].Dir, files[0].Basename)
+	}
+	// b is instantiated by the macro of foo-defaults, so the macro of
+	// foo_defaults only instantiates c and e. Its name is suffixed, as both
+	// defaults modules map to the same identifier. The stl of c and e is not
+	// set by foo_defaults, so it is not part of its macro.
+	expectedBzl := `# READ THIS FIRST:
+# This file was automatically generated by bp2build for the Bazel migration project.
+# Feel free to edit or test it, but do *not* check it into your version control system.
+
+load("//build/bazel/rules/cc:cc_library_static.bzl", "cc_library_static")
+
+# Instantiates cc_library_static with the attributes of the modules using foo-defaults.
+def foo_defaults_cc_library_static(name, **kwargs):
+    cc_library_static(
+        name = name,
+        copts = select({
+            "//build/bazel_common_rules/platforms/arch:arm64": ["-DARM64"],
+            "//conditions:default": [],
+        }),
+        stl = "none",
+        **kwargs
+    )
+
+# Instantiates cc_library_static with the attributes of the modules using foo_defaults.
+def foo_defaults_cc_library_static_2(name, **kwargs):
+    cc_library_static(
+        name = name,
+        copts = select({
+            "//build/bazel_common_rules/platforms/arch:arm64": ["-DARM64"],
+            "//conditions:default": [],
+        }),
+        **kwargs
+    )
+`
+	if files[0].Contents != expectedBzl {
+		t.Errorf("Expected defaults file:\n%s\ngot:\n%s", expectedBzl, files[0].Contents)
+	}
+
+	a := buildToTargets["a"]
+	expectedB := `foo_defaults_cc_library_static(
+    name = "b",
+    srcs = ["b.cpp"],
+)`
+	if a[1].content != expectedB {
+		t.Errorf("Expected target:\n%s\ngot:\n%s", expectedB, a[1].content)
+	}
+	expectedLoads := `load("//defaults:bp2build_defaults.bzl", "foo_defaults_cc_library_static")`
+	if loads := a.LoadStatements(); loads != expectedLoads {
+		t.Errorf("Expected loads %q, got %q", expectedLoads, loads)
+	}
+
+	c := buildToTargets["c"]
+	expectedC := `foo_defaults_cc_library_static_2(
+    name = "c",
+    srcs = ["c.cpp"],
+    stl = "none",
+)`
+	if c[0].content != expectedC {
+		t.Errorf("Expected target:\n%s\ngot:\n%s", expectedC, c[0].content)
+	}
+	expectedLoads = `load("//build/bazel/rules/cc:cc_library_static.bzl", "cc_library_static")
+load("//defaults:bp2build_defaults.bzl", "foo_defaults_cc_library_static_2")`
+	if loads := c.LoadStatements(); loads != expectedLoads {
+		t.Errorf("Expected loads %q, got %q", expectedLoads, loads)
+	}
+	if c[1].attributes["copts"] != archCopts {
+		t.Errorf("Expected attributes of target without defaults to be left inline")
+	}
+	if _, ok := buildToTargets["defaults"]; !ok {
+		t.Errorf("Expected a package for the defaults file")
+	}
+}
+
+func TestSetPropertyNames(t *testing.T) {
+	type archProps struct {
+		Cflags []string
+	}
+	type props struct {
+		Cflags []string
+		Stl    *string
+		Rtti   *bool
+		Arch   interface{}
+		Static struct {
+			Srcs []string
+		}
+	}
+	got := setPropertyNames([]interface{}{
+		&props{
+			Rtti: proptools.BoolPtr(false),
+			Arch: &struct{ Arm64 archProps }{Arm64: archProps{Cflags: []string{"-DARM64"}}},
+		},
+	})
+	expected := map[string]bool{"rtti": true, "arch": true, "arm64": true, "cflags": true}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected set properties %v, got %v", expected, got)
+	}
+}
@@ -703,6 +703,68 @@ func TestCcGenruleArchAndExcludeSrcs(t *testing.T) {
 	})
 }
 
+func TestCcGenruleArchTools(t *testing.T) {
+	bp := `
+	cc_genrule {
+		name: "tool_arm",
+		out: ["tool_arm.out"],
+		cmd: "touch $(out)",
+	}
+
+	cc_genrule {
+		name: "foo",
+		srcs: ["foo.in"],
+		arch: {
+			arm: {
+				srcs: ["foo_arm.in"],
+				tools: [":tool_arm"],
+				cmd: "$(location) --arm $(location foo_arm.in) > $(out)",
+			},
+			arm64: {
+				tool_files: ["tool_arm64.sh"],
+				cmd: "$(location tool_arm64.sh) $(in) > $(out)",
+			},
+		},
+		out: ["foo.out"],
+		cmd: "cat $(in) > $(out)",
+		bazel_module: { bp2build_available: true },
+	}`
+
+	expectedBazelAttrs := AttrNameToString{
+		"srcs": `["foo.in"] + select({
+        "//build/bazel_common_rules/platforms/arch:arm": ["foo_arm.in"],
+        "//conditions:default": [],
+    })`,
+		"outs": `["foo.out"]`,
+		"cmd": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": "$(location :tool_arm) --arm $(location foo_arm.in) > $(OUTS)",
+        "//build/bazel_common_rules/platforms/arch:arm64": "$(location tool_arm64.sh) $(SRCS) > $(OUTS)",
+        "//conditions:default": "cat $(SRCS) > $(OUTS)",
+    })`,
+		"tools": `select({
+        "//build/bazel_common_rules/platforms/arch:arm": [":tool_arm"],
+        "//build/bazel_common_rules/platforms/arch:arm64": ["tool_arm64.sh"],
+        "//conditions:default": [],
+    })`,
+		"target_compatible_with": `["//build/bazel_common_rules/platforms/os:android"]`,
+	}
+
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {},
+		Bp2buildTestCase{
+			Description:                "cc_genrule with arch-variant tools and cmd",
+			ModuleTypeUnderTest:        "cc_genrule",
+			ModuleTypeUnderTestFactory: cc.GenRuleFactory,
+			Blueprint:                  bp,
+			StubbedBuildDefinitions:    []string{"tool_arm"},
+			Filesystem: map[string]string{
+				"tool_arm64.sh": "",
+			},
+			ExpectedBazelTargets: []string{
+				MakeBazelTargetNoRestrictions("genrule", "foo", expectedBazelAttrs),
+			},
+		})
+}
+
 func TestGenruleWithExportIncludeDirs(t *testing.T) {
 	testCases := []struct {
 		moduleType string
@@ -831,7 +893,7 @@ my_genrule {
 	for _, tc := range testCases {
 		moduleAttrs := AttrNameToString{
 			"cmd": `select({
-        "//build/bazel/product_config/config_settings:my_namespace__my_variable": "echo 'with variable' > $(OUTS)",
+        "@soong_injection//soong_config_settings:my_namespace__my_variable": "echo 'with variable' > $(OUTS)",
         "//conditions:default": "echo 'no variable' > $(OUTS)",
     })`,
 			"outs": `["foo.txt"]`,
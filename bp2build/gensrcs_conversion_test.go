@@ -19,10 +19,12 @@ import (
 
 	"android/soong/android"
 	"android/soong/genrule"
+	"android/soong/python"
 )
 
 func registerModulesForGensrcsTests(ctx android.RegistrationContext) {
 	ctx.RegisterModuleType("filegroup", android.FileGroupFactory)
+	ctx.RegisterModuleType("python_binary_host", python.PythonBinaryHostFactory)
 }
 
 func TestGensrcs(t *testing.T) {
@@ -62,6 +64,27 @@ func TestGensrcs(t *testing.T) {
     ]`,
 			},
 		},
+		{
+			name: "gensrcs with a python_binary_host tool",
+			bp: `
+			gensrcs {
+                name: "foo",
+                srcs: ["input.txt"],
+                tools: ["tool"],
+                cmd: "$(location) --in $(in) --out $(out) && $(location tool) --check $(out)",
+                bazel_module: { bp2build_available: true },
+			}
+      python_binary_host {
+                name: "tool",
+                srcs: ["tool.py"],
+			}`,
+			stubbedBuildDefinitions: []string{"tool"},
+			expectedBazelAttrs: AttrNameToString{
+				"srcs":  `["input.txt"]`,
+				"tools": `[":tool"]`,
+				"cmd":   `"$(location :tool) --in $(SRC) --out $(OUT) && $(location :tool) --check $(OUT)"`,
+			},
+		},
 		{
 			name: "gensrcs with out_extension unset",
 			bp: `
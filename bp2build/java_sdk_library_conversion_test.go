@@ -146,3 +146,88 @@ func TestJavaSdkLibraryApiSurfaceNoScopeIsSet(t *testing.T) {
 		},
 	})
 }
+
+func TestJavaSdkLibraryImplAndStubsLibraries(t *testing.T) {
+	runJavaSdkLibraryTestCaseWithRegistrationCtxFunc(t, Bp2buildTestCase{
+		Description: "java_sdk_library with sdk_version converts impl and per scope stubs libraries",
+		Filesystem: map[string]string{
+			"build/soong/scripts/gen-java-current-api-files.sh": "",
+			"api/current.txt":        "",
+			"api/system-current.txt": "",
+			"api/removed.txt":        "",
+			"api/system-removed.txt": "",
+		},
+		StubbedBuildDefinitions: []string{"impl-dep", "stub-dep"},
+		Blueprint: `java_library {
+    name: "impl-dep",
+}
+
+java_library {
+    name: "stub-dep",
+}
+
+java_sdk_library {
+    name: "java-sdk-lib",
+    srcs: ["a.java"],
+    sdk_version: "current",
+    public: {enabled: true},
+    system: {enabled: true},
+    impl_only_libs: ["impl-dep"],
+    stub_only_libs: ["stub-dep"],
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("java_library", "java-sdk-lib.impl", AttrNameToString{
+				"srcs":        `["a.java"]`,
+				"deps":        `[":impl-dep-neverlink"]`,
+				"sdk_version": `"current"`,
+			}),
+			MakeNeverlinkDuplicateTarget("java_library", "java-sdk-lib.impl"),
+			MakeBazelTarget("java_sdk_library_stubs", "java-sdk-lib.stubs", AttrNameToString{
+				"api_surface": `"public"`,
+				"api_file":    `"api/current.txt"`,
+				"sdk_version": `"current"`,
+				"deps":        `[":stub-dep-neverlink"]`,
+			}),
+			MakeBazelTarget("java_sdk_library_stubs", "java-sdk-lib.stubs.system", AttrNameToString{
+				"api_surface": `"system"`,
+				"api_file":    `"api/system-current.txt"`,
+				"sdk_version": `"system_current"`,
+				"deps":        `[":stub-dep-neverlink"]`,
+			}),
+			MakeBazelTarget("java_sdk_library", "java-sdk-lib", AttrNameToString{
+				"public": `"api/current.txt"`,
+				"system": `"api/system-current.txt"`,
+			}),
+		},
+	}, func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("java_library", java.LibraryFactory)
+	})
+}
+
+func TestJavaSdkLibraryApiOnlyDoesNotConvertImpl(t *testing.T) {
+	runJavaSdkLibraryTestCase(t, Bp2buildTestCase{
+		Description: "java_sdk_library with api_only converts only the stubs libraries",
+		Filesystem: map[string]string{
+			"build/soong/scripts/gen-java-current-api-files.sh": "",
+			"api/current.txt": "",
+			"api/removed.txt": "",
+		},
+		Blueprint: `java_sdk_library {
+    name: "java-sdk-lib",
+    srcs: ["a.java"],
+    sdk_version: "current",
+    api_only: true,
+    public: {enabled: true},
+}`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("java_sdk_library_stubs", "java-sdk-lib.stubs", AttrNameToString{
+				"api_surface": `"public"`,
+				"api_file":    `"api/current.txt"`,
+				"sdk_version": `"current"`,
+			}),
+			MakeBazelTarget("java_sdk_library", "java-sdk-lib", AttrNameToString{
+				"public": `"api/current.txt"`,
+			}),
+		},
+	})
+}
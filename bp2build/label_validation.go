@@ -0,0 +1,158 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/bazel"
+)
+
+var labelType = reflect.TypeOf(bazel.Label{})
+
+// validateLabelReferences checks that the labels referenced by the attributes of the
+// generated targets resolve, so that references to modules which were not converted
+// (e.g. denylisted ones) are reported when generating the BUILD files rather than
+// when Bazel later analyzes them.
+//
+// A label resolves if its package exists and it is a generated target, a handcrafted
+// target of a package whose existing BUILD file is kept, as reported by existingTarget,
+// or a source file, as reported by fileExists for a path relative to the top of the
+// tree. A package exists if it has generated targets or a BUILD file in the source
+// tree. The targets of the BUILD files of other packages are not known to bp2build, so
+// only the existence of those packages is checked.
+func validateLabelReferences(buildToTargets map[string]BazelTargets, keepExistingBuildFile func(dir string) bool,
+	existingTarget func(dir, name string) bool, fileExists func(path string) bool) []error {
+	generated := map[string]bool{}
+	for _, targets := range buildToTargets {
+		for _, target := range targets {
+			generated[target.Label()] = true
+		}
+	}
+	hasBuildFile := func(pkg string) bool {
+		return fileExists(filepath.Join(pkg, "BUILD")) || fileExists(filepath.Join(pkg, "BUILD.bazel"))
+	}
+
+	var errs []error
+	for _, dir := range android.SortedKeys(buildToTargets) {
+		for _, target := range buildToTargets[dir] {
+			seen := map[string]bool{}
+			for _, label := range target.labels {
+				pkg, name, ok := resolveLabel(target.PackageName(), label)
+				if !ok {
+					continue
+				}
+				resolved := "//" + pkg + ":" + name
+				if pkg == "." {
+					resolved = "//:" + name
+				}
+				if seen[resolved] || generated[resolved] {
+					continue
+				}
+				seen[resolved] = true
+				keepExisting := keepExistingBuildFile(pkg)
+				if _, ok := buildToTargets[pkg]; !ok {
+					if !hasBuildFile(pkg) {
+						errs = append(errs, fmt.Errorf("%s references %s, but its package has neither generated targets nor a BUILD file",
+							target.Label(), resolved))
+						continue
+					}
+					if !keepExisting {
+						// The targets of the BUILD file are not known.
+						continue
+					}
+				}
+				if keepExisting && existingTarget(pkg, name) {
+					continue
+				}
+				if fileExists(filepath.Join(pkg, name)) {
+					continue
+				}
+				errs = append(errs, fmt.Errorf("%s references %s, which is neither a generated target nor a source file",
+					target.Label(), resolved))
+			}
+		}
+	}
+	return errs
+}
+
+// resolveLabel returns the package and target name the given label refers to from
+// the given package. Labels without a package, including relative paths to source
+// files, refer to the given package. Labels of other repositories are not resolved.
+func resolveLabel(currentPkg, label string) (pkg, name string, ok bool) {
+	switch {
+	case strings.HasPrefix(label, "@"):
+		return "", "", false
+	case strings.HasPrefix(label, ":"):
+		return currentPkg, label[1:], true
+	case strings.HasPrefix(label, "//"):
+		pkg = strings.TrimPrefix(label, "//")
+		if i := strings.Index(pkg, ":"); i != -1 {
+			pkg, name = pkg[:i], pkg[i+1:]
+		} else {
+			name = filepath.Base(pkg)
+		}
+		if pkg == "" {
+			pkg = "."
+		}
+		return pkg, name, true
+	default:
+		return currentPkg, label, true
+	}
+}
+
+// attributeLabels returns the sorted labels referenced by the given attribute structs.
+func attributeLabels(attrs []interface{}) []string {
+	var labels []string
+	for _, attr := range attrs {
+		labels = collectLabels(reflect.ValueOf(attr), labels)
+	}
+	return android.SortedUniqueStrings(labels)
+}
+
+// collectLabels appends the labels included by the given attribute value to labels.
+// The excluded labels of label lists are not rendered, so they are skipped.
+func collectLabels(value reflect.Value, labels []string) []string {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			labels = collectLabels(value.Elem(), labels)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			labels = collectLabels(value.Index(i), labels)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			labels = collectLabels(iter.Value(), labels)
+		}
+	case reflect.Struct:
+		if value.Type() == labelType {
+			return append(labels, value.FieldByName("Label").String())
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).Name == "Excludes" {
+				continue
+			}
+			labels = collectLabels(value.Field(i), labels)
+		}
+	}
+	return labels
+}
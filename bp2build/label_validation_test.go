@@ -0,0 +1,106 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/bazel"
+)
+
+func TestValidateLabelReferences(t *testing.T) {
+	makeTarget := func(pkg, name string, deps ...string) BazelTarget {
+		var labels []bazel.Label
+		for _, dep := range deps {
+			labels = append(labels, bazel.Label{Label: dep})
+		}
+		attrs := struct {
+			Deps bazel.LabelListAttribute
+		}{
+			Deps: bazel.MakeLabelListAttribute(bazel.LabelList{
+				Includes: labels,
+				// Excluded labels are not rendered, so they are not checked.
+				Excludes: []bazel.Label{{Label: ":excluded"}},
+			}),
+		}
+		target, err := generateBazelTarget(nil, bTarget{
+			targetName:      name,
+			targetPackage:   pkg,
+			bazelRuleClass:  "cc_library_static",
+			bazelAttributes: []interface{}{&attrs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return target
+	}
+
+	buildToTargets := map[string]BazelTargets{
+		"a": {
+			makeTarget("a", "foo",
+				":bar",                 // generated target of the same package
+				"//b:baz",              // generated target of another package
+				"foo.cpp",              // source file of the package
+				"missing.cpp",          // missing source file of the package
+				"//b:sub/qux.h",        // source file of another package
+				":denylisted",          // unconverted module of the same package
+				"//b:missing",          // unconverted module of another package
+				"//b",                  // shorthand for //b:b, which isn't generated
+				"//handcrafted:x",      // package with a BUILD file but without generated targets
+				"//nonexistent:w",      // package with neither a BUILD file nor generated targets
+				"//kept:y",             // handcrafted target of a package keeping its BUILD file
+				"//kept:missing",       // missing target of a package keeping its BUILD file
+				"//kept_handcrafted:v", // handcrafted target of a kept package without generated targets
+				"//kept_missing:u",     // kept package without a BUILD file nor generated targets
+				"@repo//c:d",           // other repository
+			),
+			makeTarget("a", "bar"),
+		},
+		"b":    {makeTarget("b", "baz", "//a:foo")},
+		"kept": {makeTarget("kept", "z")},
+	}
+	keepExistingBuildFile := func(dir string) bool {
+		return dir == "kept" || dir == "kept_handcrafted" || dir == "kept_missing"
+	}
+	existingTarget := func(dir, name string) bool {
+		return (dir == "kept" && name == "y") || (dir == "kept_handcrafted" && name == "v")
+	}
+	fileExists := func(path string) bool {
+		switch path {
+		case "a/foo.cpp", "b/sub/qux.h", "handcrafted/BUILD", "kept/BUILD.bazel", "kept_handcrafted/BUILD":
+			return true
+		}
+		return false
+	}
+
+	errs := validateLabelReferences(buildToTargets, keepExistingBuildFile, existingTarget, fileExists)
+	expected := []string{
+		"//a:foo references //b:b, which is neither a generated target nor a source file",
+		"//a:foo references //b:missing, which is neither a generated target nor a source file",
+		"//a:foo references //kept:missing, which is neither a generated target nor a source file",
+		"//a:foo references //kept_missing:u, but its package has neither generated targets nor a BUILD file",
+		"//a:foo references //nonexistent:w, but its package has neither generated targets nor a BUILD file",
+		"//a:foo references //a:denylisted, which is neither a generated target nor a source file",
+		"//a:foo references //a:missing.cpp, which is neither a generated target nor a source file",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected error %d to be %q, got %q", i, expected[i], err.Error())
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"android/soong/android"
+	"android/soong/cc"
 	"android/soong/shared"
 	"android/soong/ui/metrics/bp2build_metrics_proto"
 
@@ -33,12 +34,24 @@ type CodegenMetrics struct {
 	// NOTE: NOT in the .proto
 	moduleWithMissingDepsMsgs []string
 
+	// List of warnings about parts of converted modules that were dropped or altered
+	// NOTE: NOT in the .proto
+	moduleWithWarningsMsgs []string
+
 	// Map of converted modules and paths to call
 	// NOTE: NOT in the .proto
 	convertedModulePathMap map[string]string
 
 	// Name and type of converted modules
 	convertedModuleWithType []moduleInfo
+
+	// Map of unconverted modules to their type
+	// NOTE: NOT in the .proto
+	unconvertedModuleTypes map[string]string
+
+	// Set of the converted and unconverted modules which are cc modules
+	// NOTE: NOT in the .proto
+	ccModules map[string]bool
 }
 
 func CreateCodegenMetrics() CodegenMetrics {
@@ -50,6 +63,8 @@ func CreateCodegenMetrics() CodegenMetrics {
 			UnconvertedModules:       make(map[string]*bp2build_metrics_proto.UnconvertedReason),
 		},
 		convertedModulePathMap: make(map[string]string),
+		unconvertedModuleTypes: make(map[string]string),
+		ccModules:              make(map[string]bool),
 	}
 }
 
@@ -72,6 +87,8 @@ func (metrics *CodegenMetrics) Print() {
 	%s
 %d converted modules have missing deps:
 	%s
+%d conversion warnings:
+	%s
 `,
 		metrics.serialized.GeneratedModuleCount,
 		generatedTargetCount,
@@ -81,6 +98,8 @@ func (metrics *CodegenMetrics) Print() {
 		strings.Join(metrics.moduleWithUnconvertedDepsMsgs, "\n\t"),
 		len(metrics.moduleWithMissingDepsMsgs),
 		strings.Join(metrics.moduleWithMissingDepsMsgs, "\n\t"),
+		len(metrics.moduleWithWarningsMsgs),
+		strings.Join(metrics.moduleWithWarningsMsgs, "\n\t"),
 	)
 }
 
@@ -147,6 +166,8 @@ func ReadCodegenMetrics(dir string) *CodegenMetrics {
 		return &CodegenMetrics{
 			serialized:             &bp2BuildMetrics,
 			convertedModulePathMap: make(map[string]string),
+			unconvertedModuleTypes: make(map[string]string),
+			ccModules:              make(map[string]bool),
 		}
 	}
 }
@@ -205,6 +226,9 @@ func (metrics *CodegenMetrics) AddConvertedModule(m blueprint.Module, moduleType
 		moduleType,
 	})
 	metrics.convertedModulePathMap[moduleName] = "//" + dir
+	if _, ok := m.(*cc.Module); ok {
+		metrics.ccModules[moduleName] = true
+	}
 	metrics.serialized.ConvertedModuleTypeCount[moduleType] += 1
 	metrics.serialized.TotalModuleTypeCount[moduleType] += 1
 	metrics.serialized.GeneratedModuleCount += 1
@@ -222,6 +246,10 @@ func (metrics *CodegenMetrics) AddUnconvertedModule(m blueprint.Module, moduleTy
 		Type:   bp2build_metrics_proto.UnconvertedReasonType(reason.ReasonType),
 		Detail: reason.Detail,
 	}
+	metrics.unconvertedModuleTypes[moduleName] = moduleType
+	if _, ok := m.(*cc.Module); ok {
+		metrics.ccModules[moduleName] = true
+	}
 	metrics.serialized.UnconvertedModuleCount += 1
 	metrics.serialized.TotalModuleTypeCount[moduleType] += 1
 
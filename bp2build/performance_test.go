@@ -24,6 +24,11 @@ package bp2build
 import (
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -81,14 +86,46 @@ func genCustomModuleBp(pctConverted float64) string {
 	return strings.Join(bp, "\n\n")
 }
 
+// genCustomModuleWorkspace generates the Android.bp files of a synthetic workspace with the
+// given number of modules spread across the given number of directories. Each module but the
+// first has an arch-variant dependency on the module generated before it, which is defined in
+// another directory, so that dependencies cross package boundaries like in a real tree.
+func genCustomModuleWorkspace(modules, dirs int) map[string][]byte {
+	fs := make(map[string][]byte, dirs)
+	for d := 0; d < dirs; d++ {
+		var bp []string
+		for i := d; i < modules; i += dirs {
+			dep := fmt.Sprintf(`"x86_src_%d"`, i)
+			if i > 0 {
+				dep = fmt.Sprintf(`":ws_module_%d"`, i-1)
+			}
+			bp = append(bp, fmt.Sprintf(`
+custom {
+    name: "ws_module_%[1]d",
+    string_list_prop: ["a", "b"],
+    arch_paths: ["src_%[1]d"],
+    arch: {
+      x86: {
+        arch_paths: [%[2]s],
+      },
+    },
+    bazel_module: { bp2build_available: true },
+}`, i, dep))
+		}
+		fs[filepath.Join(fmt.Sprintf("dir_%d", d), "Android.bp")] = []byte(strings.Join(bp, "\n"))
+	}
+	return fs
+}
+
 type testConfig struct {
 	config     android.Config
 	ctx        *android.TestContext
 	codegenCtx *CodegenContext
+	bpFiles    []string
 }
 
 func (tc testConfig) parse() []error {
-	_, errs := tc.ctx.ParseFileList(performance_test_dir, []string{"Android.bp"})
+	_, errs := tc.ctx.ParseFileList(performance_test_dir, tc.bpFiles)
 	return errs
 }
 
@@ -111,6 +148,77 @@ func setup(builddir string, tcSize float64) testConfig {
 		config,
 		ctx,
 		codegenCtx,
+		[]string{"Android.bp"},
+	}
+}
+
+func setupWorkspace(builddir string, modules, dirs int) testConfig {
+	fs := genCustomModuleWorkspace(modules, dirs)
+	config := android.TestConfig(builddir, nil, "", fs)
+	ctx := android.NewTestContext(config)
+
+	registerCustomModuleForBp2buildConversion(ctx)
+	codegenCtx := NewCodegenContext(config, ctx.Context, Bp2Build, "")
+	return testConfig{
+		config,
+		ctx,
+		codegenCtx,
+		android.SortedKeys(fs),
+	}
+}
+
+// workspaceSizes are the {modules, directories} sizes of the synthetic workspaces to benchmark.
+var workspaceSizes = [][2]int{{100, 10}, {1000, 100}, {5000, 500}}
+
+// convertWorkspace runs the full conversion of a synthetic workspace.
+func convertWorkspace(tb testing.TB, modules, dirs int) {
+	tc := setupWorkspace(buildDir, modules, dirs)
+	if errs := tc.parse(); len(errs) > 0 {
+		tb.Fatalf("Unexpected errors: %s", errs)
+	}
+	if errs := tc.resolveDependencies(); len(errs) > 0 {
+		tb.Fatalf("Unexpected errors: %s", errs)
+	}
+	res, errs := GenerateBazelTargets(tc.codegenCtx, false)
+	if len(errs) > 0 {
+		tb.Fatalf("Unexpected errors: %s", errs)
+	}
+	for d := 0; d < dirs; d++ {
+		if dir := fmt.Sprintf("dir_%d", d); len(res.buildFileToTargets[dir]) == 0 {
+			tb.Fatalf("Expected targets in package %s", dir)
+		}
+	}
+}
+
+// TestGenerateBazelTargetsIsDeterministic verifies that the targets of a package are
+// generated in the same order on every run, although they are generated concurrently.
+func TestGenerateBazelTargetsIsDeterministic(t *testing.T) {
+	generate := func() map[string][]string {
+		tc := setupWorkspace(buildDir, 200, 4)
+		if errs := tc.parse(); len(errs) > 0 {
+			t.Fatalf("Unexpected errors: %s", errs)
+		}
+		if errs := tc.resolveDependencies(); len(errs) > 0 {
+			t.Fatalf("Unexpected errors: %s", errs)
+		}
+		res, errs := GenerateBazelTargets(tc.codegenCtx, false)
+		if len(errs) > 0 {
+			t.Fatalf("Unexpected errors: %s", errs)
+		}
+		labels := map[string][]string{}
+		for dir, targets := range res.buildFileToTargets {
+			for _, target := range targets {
+				labels[dir] = append(labels[dir], target.Label())
+			}
+		}
+		return labels
+	}
+
+	expected := generate()
+	for i := 0; i < 5; i++ {
+		if actual := generate(); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Expected the same targets on every run, got:\n%v\nand:\n%v", expected, actual)
+		}
 	}
 }
 
@@ -217,3 +325,90 @@ func BenchmarkManyModulesGenerateBazelTargetsForDir(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkManyDirectoriesFull(b *testing.B) {
+	for _, size := range workspaceSizes {
+		modules, dirs := size[0], size[1]
+		b.Run(fmt.Sprintf("modules %d dirs %d", modules, dirs), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				convertWorkspace(b, modules, dirs)
+			}
+		})
+	}
+}
+
+// BenchmarkManyDirectoriesGenerateBazelTargets measures GenerateBazelTargets alone, with a
+// single worker and with as many workers as GOMAXPROCS allows, to compare the speedup of
+// generating the targets of the modules concurrently.
+func BenchmarkManyDirectoriesGenerateBazelTargets(b *testing.B) {
+	for _, size := range workspaceSizes {
+		modules, dirs := size[0], size[1]
+		for _, procs := range []int{1, runtime.GOMAXPROCS(0)} {
+			b.Run(fmt.Sprintf("modules %d dirs %d procs %d", modules, dirs, procs), func(b *testing.B) {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					b.StopTimer()
+					tc := setupWorkspace(buildDir, modules, dirs)
+					if errs := tc.parse(); len(errs) > 0 {
+						b.Fatalf("Unexpected errors: %s", errs)
+					}
+					if errs := tc.resolveDependencies(); len(errs) > 0 {
+						b.Fatalf("Unexpected errors: %s", errs)
+					}
+
+					b.StartTimer()
+					if _, errs := GenerateBazelTargets(tc.codegenCtx, false); len(errs) > 0 {
+						b.Fatalf("Unexpected errors: %s", errs)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestManyDirectoriesConversionRegression fails if converting a synthetic workspace takes more
+// time or allocations per module than the thresholds given by the environment. It is skipped
+// unless at least one of them is set, since the limits depend on the machine running the test:
+//
+//	BP2BUILD_PERF_MAX_NS_PER_MODULE
+//	BP2BUILD_PERF_MAX_ALLOCS_PER_MODULE
+func TestManyDirectoriesConversionRegression(t *testing.T) {
+	maxNsPerModule := perfThresholdFromEnv(t, "BP2BUILD_PERF_MAX_NS_PER_MODULE")
+	maxAllocsPerModule := perfThresholdFromEnv(t, "BP2BUILD_PERF_MAX_ALLOCS_PER_MODULE")
+	if maxNsPerModule == 0 && maxAllocsPerModule == 0 {
+		t.Skip("no bp2build performance thresholds set")
+	}
+
+	modules, dirs := 1000, 100
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			convertWorkspace(b, modules, dirs)
+		}
+	})
+	nsPerModule := result.NsPerOp() / int64(modules)
+	allocsPerModule := result.AllocsPerOp() / int64(modules)
+	t.Logf("converted %d modules in %d directories: %d ns/module, %d allocs/module",
+		modules, dirs, nsPerModule, allocsPerModule)
+
+	if maxNsPerModule > 0 && nsPerModule > maxNsPerModule {
+		t.Errorf("conversion took %d ns/module, above the threshold of %d", nsPerModule, maxNsPerModule)
+	}
+	if maxAllocsPerModule > 0 && allocsPerModule > maxAllocsPerModule {
+		t.Errorf("conversion made %d allocs/module, above the threshold of %d", allocsPerModule, maxAllocsPerModule)
+	}
+}
+
+func perfThresholdFromEnv(t *testing.T, name string) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	threshold, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s: %s", name, err)
+	}
+	return threshold
+}
@@ -136,6 +136,42 @@ prebuilt_etc {
 				"dir": `"etc/tz"`,
 			})}})
 }
+func TestPrebuiltEtcArchVariantSubDir(t *testing.T) {
+	runPrebuiltEtcTestCase(t, Bp2buildTestCase{
+		Description: "prebuilt_etc - arch variant sub_dir and relative_install_path",
+		Filesystem:  map[string]string{},
+		Blueprint: `
+prebuilt_etc {
+    name: "foo",
+    src: "fooSrc",
+    sub_dir: "tz",
+    arch: {
+      arm64: {
+        sub_dir: "tz64",
+      },
+    },
+    target: {
+      linux_glibc: {
+        relative_install_path: "tz_host",
+      },
+    },
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("prebuilt_file", "foo", AttrNameToString{
+				"filename": `"foo"`,
+				"src":      `"fooSrc"`,
+				"dir": `select({
+        "//build/bazel_common_rules/platforms/os_arch:android_arm64": "etc/tz64",
+        "//build/bazel_common_rules/platforms/os_arch:darwin_arm64": "etc/tz64",
+        "//build/bazel_common_rules/platforms/os_arch:linux_bionic_arm64": "etc/tz64",
+        "//build/bazel_common_rules/platforms/os_arch:linux_glibc_x86": "etc/tz_host",
+        "//build/bazel_common_rules/platforms/os_arch:linux_glibc_x86_64": "etc/tz_host",
+        "//conditions:default": "etc/tz",
+    })`,
+			})}})
+}
+
 func TestPrebuiltEtcProductVariables(t *testing.T) {
 	runPrebuiltEtcTestCase(t, Bp2buildTestCase{
 		Description: "prebuilt etc - product variables",
@@ -394,3 +430,49 @@ prebuilt_root_host {
     })`,
 			})}})
 }
+
+func runPrebuiltFirmwareTestCase(t *testing.T, tc Bp2buildTestCase) {
+	t.Helper()
+	(&tc).ModuleTypeUnderTest = "prebuilt_firmware"
+	(&tc).ModuleTypeUnderTestFactory = etc.PrebuiltFirmwareFactory
+	RunBp2BuildTestCase(t, registerPrebuiltModuleTypes, tc)
+}
+
+func TestPrebuiltFirmwareSimple(t *testing.T) {
+	runPrebuiltFirmwareTestCase(t, Bp2buildTestCase{
+		Description: "prebuilt_firmware - simple example",
+		Filesystem:  map[string]string{},
+		Blueprint: `
+prebuilt_firmware {
+    name: "wifi_fw",
+    src: "wifi.bin",
+    sub_dir: "wlan",
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("prebuilt_file", "wifi_fw", AttrNameToString{
+				"filename": `"wifi_fw"`,
+				"src":      `"wifi.bin"`,
+				"dir":      `"etc/firmware/wlan"`,
+			})}})
+}
+
+func TestPrebuiltFirmwareSocSpecific(t *testing.T) {
+	runPrebuiltFirmwareTestCase(t, Bp2buildTestCase{
+		Description: "prebuilt_firmware - soc specific installs to firmware",
+		Filesystem:  map[string]string{},
+		Blueprint: `
+prebuilt_firmware {
+    name: "wifi_fw",
+    src: "wifi.bin",
+    filename: "wifi.bin",
+    soc_specific: true,
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("prebuilt_file", "wifi_fw", AttrNameToString{
+				"filename": `"wifi.bin"`,
+				"src":      `"wifi.bin"`,
+				"dir":      `"firmware"`,
+			})}})
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/android"
+	"android/soong/kernel"
+)
+
+func runPrebuiltKernelModulesTestCase(t *testing.T, tc Bp2buildTestCase) {
+	t.Helper()
+	(&tc).ModuleTypeUnderTest = "prebuilt_kernel_modules"
+	(&tc).ModuleTypeUnderTestFactory = kernel.PrebuiltKernelModulesFactory
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {}, tc)
+}
+
+func TestPrebuiltKernelModules(t *testing.T) {
+	runPrebuiltKernelModulesTestCase(t, Bp2buildTestCase{
+		Description: "prebuilt_kernel_modules - kernel version",
+		Filesystem: map[string]string{
+			"mod1.ko": "",
+			"mod2.ko": "",
+		},
+		Blueprint: `
+prebuilt_kernel_modules {
+    name: "foo",
+    srcs: ["*.ko"],
+    kernel_version: "5.10",
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("prebuilt_kernel_modules", "foo", AttrNameToString{
+				"srcs": `[
+        "mod1.ko",
+        "mod2.ko",
+    ]`,
+				"dir": `"lib/modules/5.10"`,
+			})}})
+}
+
+func TestPrebuiltKernelModulesArchVariant(t *testing.T) {
+	runPrebuiltKernelModulesTestCase(t, Bp2buildTestCase{
+		Description: "prebuilt_kernel_modules - arch variant srcs",
+		Filesystem:  map[string]string{},
+		Blueprint: `
+prebuilt_kernel_modules {
+    name: "foo",
+    arch: {
+        arm64: {
+            srcs: ["arm64/mod.ko"],
+        },
+        x86_64: {
+            srcs: ["x86_64/mod.ko"],
+        },
+    },
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("prebuilt_kernel_modules", "foo", AttrNameToString{
+				"srcs": `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["arm64/mod.ko"],
+        "//build/bazel_common_rules/platforms/arch:x86_64": ["x86_64/mod.ko"],
+        "//conditions:default": [],
+    })`,
+				"dir": `"lib/modules"`,
+			})}})
+}
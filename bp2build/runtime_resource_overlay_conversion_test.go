@@ -0,0 +1,98 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/android"
+	"android/soong/java"
+)
+
+func runRuntimeResourceOverlayTestCase(t *testing.T, tc Bp2buildTestCase) {
+	t.Helper()
+	(&tc).ModuleTypeUnderTest = "runtime_resource_overlay"
+	(&tc).ModuleTypeUnderTestFactory = java.RuntimeResourceOverlayFactory
+	RunBp2BuildTestCase(t, func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("android_library", java.AndroidLibraryFactory)
+		ctx.RegisterModuleType("android_app", java.AndroidAppFactory)
+	}, tc)
+}
+
+func TestRuntimeResourceOverlay(t *testing.T) {
+	runRuntimeResourceOverlayTestCase(t, Bp2buildTestCase{
+		Description: "runtime_resource_overlay - simple example",
+		Filesystem: map[string]string{
+			"res/values/strings.xml": "",
+			"AndroidManifest.xml":    "",
+		},
+		Blueprint: `
+runtime_resource_overlay {
+	name: "TestOverlay",
+	sdk_version: "current",
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("runtime_resource_overlay", "TestOverlay", AttrNameToString{
+				"manifest":       `"AndroidManifest.xml"`,
+				"resource_files": `["res/values/strings.xml"]`,
+				"sdk_version":    `"current"`,
+			}),
+		}})
+}
+
+func TestRuntimeResourceOverlayAllSupportedFields(t *testing.T) {
+	runRuntimeResourceOverlayTestCase(t, Bp2buildTestCase{
+		Description: "runtime_resource_overlay - all supported fields",
+		Filesystem: map[string]string{
+			"overlay_res/values/strings.xml": "",
+			"manifest/AndroidManifest.xml":   "",
+		},
+		StubbedBuildDefinitions: []string{"static_lib_dep", "resource_lib_dep"},
+		Blueprint: simpleModule("android_library", "static_lib_dep") +
+			simpleModule("android_app", "resource_lib_dep") + `
+runtime_resource_overlay {
+	name: "TestOverlay",
+	manifest: "manifest/AndroidManifest.xml",
+	resource_dirs: ["overlay_res"],
+	theme: "faza",
+	certificate: "platform",
+	sdk_version: "current",
+	min_sdk_version: "30",
+	static_libs: ["static_lib_dep"],
+	resource_libs: ["resource_lib_dep"],
+	package_name: "com.android.overlay",
+	target_package_name: "com.android.target",
+	category: "android.theme.customization.font",
+}
+`,
+		ExpectedBazelTargets: []string{
+			MakeBazelTarget("runtime_resource_overlay", "TestOverlay", AttrNameToString{
+				"manifest":         `"manifest/AndroidManifest.xml"`,
+				"resource_files":   `["overlay_res/values/strings.xml"]`,
+				"theme":            `"faza"`,
+				"certificate_name": `"platform"`,
+				"sdk_version":      `"current"`,
+				"manifest_values": `{
+        "minSdkVersion": "30",
+    }`,
+				"deps":                `[":static_lib_dep"]`,
+				"resource_libs":       `[":resource_lib_dep"]`,
+				"custom_package":      `"com.android.overlay"`,
+				"target_package_name": `"com.android.target"`,
+				"category":            `"android.theme.customization.font"`,
+			}),
+		}})
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"android/soong/android"
+)
+
+const (
+	// sharedSelectsBzlFileName is the name of the per-package .bzl file holding
+	// select() values shared by several targets of the package.
+	sharedSelectsBzlFileName = "bp2build_shared_selects.bzl"
+
+	// minSharedSelectUses is the number of targets of a package that must render
+	// an identical select() before it is hoisted into the shared .bzl file.
+	minSharedSelectUses = 2
+)
+
+// sharedSelectSymbol returns the name of the constant holding the given select()
+// value. It is derived from the value, so that adding or removing a shared select
+// in a package doesn't rename the constants of the others.
+func sharedSelectSymbol(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return "shared_select_" + hex.EncodeToString(hash[:6])
+}
+
+// factorSharedSelects hoists select() attribute values that are rendered
+// identically by several targets of a package (e.g. arch-specific cflags coming
+// from a cc_defaults applied to many libraries) into named constants of a .bzl
+// file in that package. The targets are rewritten in-place to load and reference
+// those constants. It returns the .bzl files to write.
+func factorSharedSelects(buildToTargets map[string]BazelTargets) []BazelFile {
+	var files []BazelFile
+	for _, dir := range android.SortedKeys(buildToTargets) {
+		targets := buildToTargets[dir]
+
+		uses := map[string]int{}
+		for _, target := range targets {
+			seen := map[string]bool{}
+			for _, value := range target.attributes {
+				if strings.Contains(value, "select(") && !seen[value] {
+					seen[value] = true
+					uses[value]++
+				}
+			}
+		}
+
+		var shared []string
+		for value, count := range uses {
+			if count >= minSharedSelectUses {
+				shared = append(shared, value)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		symbols := make(map[string]string, len(shared))
+		for _, value := range shared {
+			symbols[value] = sharedSelectSymbol(value)
+		}
+		sort.Slice(shared, func(i, j int) bool {
+			return symbols[shared[i]] < symbols[shared[j]]
+		})
+
+		var bzl strings.Builder
+		bzl.WriteString(`# READ THIS FIRST:
+# This file was automatically generated by bp2build for the Bazel migration project.
+# Feel free to edit or test it, but do *not* check it into your version control system.
+`)
+		for _, value := range shared {
+			bzl.WriteString(fmt.Sprintf("\n%s = %s\n", symbols[value], value))
+		}
+		files = append(files, newFile(dir, sharedSelectsBzlFileName, bzl.String()))
+
+		bzlLabel := "//" + dir + ":" + sharedSelectsBzlFileName
+		if dir == "." || dir == "" {
+			bzlLabel = "//:" + sharedSelectsBzlFileName
+		}
+		for i := range targets {
+			target := &targets[i]
+			attrs := make(map[string]string, len(target.attributes))
+			var loaded []BazelLoadSymbol
+			for name, value := range target.attributes {
+				if symbol, ok := symbols[value]; ok {
+					attrs[name] = symbol
+					loaded = append(loaded, BazelLoadSymbol{symbol: symbol})
+				} else {
+					attrs[name] = value
+				}
+			}
+			if len(loaded) == 0 {
+				continue
+			}
+			target.attributes = attrs
+			target.content = ruleTargetContent(target.callee(), target.name, attrs)
+			target.loads = append(target.loads, BazelLoad{
+				file:    bzlLabel,
+				symbols: loaded,
+			})
+		}
+	}
+	return files
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+)
+
+func TestFactorSharedSelects(t *testing.T) {
+	archCopts := `select({
+        "//build/bazel_common_rules/platforms/arch:arm64": ["-DARM64"],
+        "//conditions:default": [],
+    })`
+	newTarget := func(name string, attrs map[string]string) BazelTarget {
+		return BazelTarget{
+			name:        name,
+			packageName: "foo",
+			ruleClass:   "cc_library_static",
+			content:     ruleTargetContent("cc_library_static", name, attrs),
+			attributes:  attrs,
+		}
+	}
+	buildToTargets := map[string]BazelTargets{
+		"foo": {
+			newTarget("a", map[string]string{"copts": archCopts, "srcs": `["a.cpp"]`}),
+			newTarget("b", map[string]string{"copts": archCopts, "srcs": `["b.cpp"]`}),
+			newTarget("c", map[string]string{"srcs": `["c.cpp"]`}),
+		},
+		"bar": {
+			newTarget("d", map[string]string{"copts": archCopts}),
+		},
+	}
+
+	files := factorSharedSelects(buildToTargets)
+	if len(files) != 1 {
+		t.Fatalf("Expected one shared selects file, got %d: %v", len(files), files)
+	}
+	if files[0].Dir != "foo" || files[0].Basename != sharedSelectsBzlFileName {
+		t.Errorf("Unexpected shared selects file %s/%s", files[0].Dir, files[0].Basename)
+	}
+	expectedBzl := `# READ THIS FIRST:
+# This file was automatically generated by bp2build for the Bazel migration project.
+# Feel free to edit or test it, but do *not* check it into your version control system.
+
+shared_select_7304bba7258f = ` + archCopts + "\n"
+	if files[0].Contents != expectedBzl {
+		t.Errorf("Expected shared selects file:\n%s\ngot:\n%s", expectedBzl, files[0].Contents)
+	}
+
+	expectedA := `cc_library_static(
+    name = "a",
+    copts = shared_select_7304bba7258f,
+    srcs = ["a.cpp"],
+)`
+	foo := buildToTargets["foo"]
+	if foo[0].content != expectedA {
+		t.Errorf("Expected target:\n%s\ngot:\n%s", expectedA, foo[0].content)
+	}
+	expectedLoads := `load("//foo:bp2build_shared_selects.bzl", "shared_select_7304bba7258f")`
+	if loads := foo.LoadStatements(); loads != expectedLoads {
+		t.Errorf("Expected loads %q, got %q", expectedLoads, loads)
+	}
+	if len(foo[2].loads) != 0 {
+		t.Errorf("Expected no loads for target without shared selects, got %v", foo[2].loads)
+	}
+	if buildToTargets["bar"][0].attributes["copts"] != archCopts {
+		t.Errorf("Expected select used by a single target to be left inline")
+	}
+}
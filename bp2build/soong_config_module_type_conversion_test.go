@@ -92,7 +92,7 @@ custom_cc_library_static {
 		ExpectedBazelTargets: []string{`cc_library_static(
     name = "foo",
     copts = select({
-        "//build/bazel/product_config/config_settings:acme__feature1": ["-DFEATURE1"],
+        "@soong_injection//soong_config_settings:acme__feature1": ["-DFEATURE1"],
         "//conditions:default": ["-DDEFAULT1"],
     }),
     local_includes = ["."],
@@ -141,7 +141,7 @@ custom_cc_library_static {
 		ExpectedBazelTargets: []string{`cc_library_static(
     name = "foo",
     copts = select({
-        "//build/bazel/product_config/config_settings:acme__feature1": ["-DFEATURE1"],
+        "@soong_injection//soong_config_settings:acme__feature1": ["-DFEATURE1"],
         "//conditions:default": ["-DDEFAULT1"],
     }),
     local_includes = ["."],
@@ -241,7 +241,7 @@ custom_cc_library_static {
 		ExpectedBazelTargets: []string{`cc_library_static(
     name = "foo",
     copts = select({
-        "//build/bazel/product_config/config_settings:acme__feature1": ["-DFEATURE1"],
+        "@soong_injection//soong_config_settings:acme__feature1": ["-DFEATURE1"],
         "//conditions:default": ["-DDEFAULT1"],
     }),
     local_includes = ["."],
@@ -316,10 +316,10 @@ custom_cc_library_static {
         "//build/bazel/product_config/config_settings:acme__board__soc_c": [],
         "//conditions:default": ["-DSOC_DEFAULT"],
     }) + select({
-        "//build/bazel/product_config/config_settings:acme__feature1": ["-DFEATURE1"],
+        "@soong_injection//soong_config_settings:acme__feature1": ["-DFEATURE1"],
         "//conditions:default": ["-DDEFAULT1"],
     }) + select({
-        "//build/bazel/product_config/config_settings:acme__feature2": ["-DFEATURE2"],
+        "@soong_injection//soong_config_settings:acme__feature2": ["-DFEATURE2"],
         "//conditions:default": ["-DDEFAULT2"],
     }),
     local_includes = ["."],
@@ -397,6 +397,69 @@ cc_library_static { name: "soc_default_static_dep"}
 )`}})
 }
 
+func TestSoongConfigModuleType_SrcsAndFlagsWithVendorCflags(t *testing.T) {
+	bp := `
+soong_config_module_type {
+	name: "custom_cc_library_static",
+	module_type: "cc_library_static",
+	config_namespace: "acme",
+	bool_variables: ["feature1"],
+	properties: ["cflags", "conlyflags", "srcs"],
+}
+
+custom_cc_library_static {
+	name: "foo",
+	bazel_module: { bp2build_available: true },
+	host_supported: true,
+	srcs: ["common.c"],
+	target: {
+		vendor: {
+			cflags: ["-DVENDOR"],
+		},
+	},
+	soong_config_variables: {
+		feature1: {
+			cflags: ["-DFEATURE1"],
+			conlyflags: ["-DFEATURE1_C"],
+			srcs: [
+				"feature1.c",
+				"feature1.cpp",
+			],
+		},
+	},
+}
+`
+
+	runSoongConfigModuleTypeTest(t, Bp2buildTestCase{
+		Description:                "soong config variables - srcs and flags are combined with target.vendor props",
+		ModuleTypeUnderTest:        "cc_library_static",
+		ModuleTypeUnderTestFactory: cc.LibraryStaticFactory,
+		Blueprint:                  bp,
+		ExpectedBazelTargets: []string{`cc_library_static(
+    name = "foo",
+    conlyflags = select({
+        "@soong_injection//soong_config_settings:acme__feature1": ["-DFEATURE1_C"],
+        "//conditions:default": [],
+    }),
+    copts = select({
+        "@soong_injection//soong_config_settings:acme__feature1": ["-DFEATURE1"],
+        "//conditions:default": [],
+    }) + select({
+        "@soong_injection//image:vendor": ["-DVENDOR"],
+        "//conditions:default": [],
+    }),
+    local_includes = ["."],
+    srcs = select({
+        "@soong_injection//soong_config_settings:acme__feature1": ["feature1.cpp"],
+        "//conditions:default": [],
+    }),
+    srcs_c = ["common.c"] + select({
+        "@soong_injection//soong_config_settings:acme__feature1": ["feature1.c"],
+        "//conditions:default": [],
+    }),
+)`}})
+}
+
 func TestSoongConfigModuleType_Defaults_SingleNamespace(t *testing.T) {
 	bp := `
 soong_config_module_type {
@@ -448,7 +511,7 @@ cc_library_static {
 		ExpectedBazelTargets: []string{`cc_library_static(
     name = "lib",
     copts = select({
-        "//build/bazel/product_config/config_settings:vendor_foo__feature": [
+        "@soong_injection//soong_config_settings:vendor_foo__feature": [
             "-cflag_feature_2",
             "-cflag_feature_1",
         ],
@@ -529,11 +592,11 @@ cc_library_static {
 		ExpectedBazelTargets: []string{`cc_library_static(
     name = "lib",
     asflags = select({
-        "//build/bazel/product_config/config_settings:acme__feature": ["-asflag_bar"],
+        "@soong_injection//soong_config_settings:acme__feature": ["-asflag_bar"],
         "//conditions:default": ["-asflag_default_bar"],
     }),
     copts = select({
-        "//build/bazel/product_config/config_settings:acme__feature": [
+        "@soong_injection//soong_config_settings:acme__feature": [
             "-cflag_foo",
             "-cflag_bar",
         ],
@@ -548,11 +611,11 @@ cc_library_static {
 			`cc_library_static(
     name = "lib2",
     asflags = select({
-        "//build/bazel/product_config/config_settings:acme__feature": ["-asflag_bar"],
+        "@soong_injection//soong_config_settings:acme__feature": ["-asflag_bar"],
         "//conditions:default": ["-asflag_default_bar"],
     }),
     copts = select({
-        "//build/bazel/product_config/config_settings:acme__feature": [
+        "@soong_injection//soong_config_settings:acme__feature": [
             "-cflag_bar",
             "-cflag_foo",
         ],
@@ -645,13 +708,13 @@ cc_library_static {
 		ExpectedBazelTargets: []string{`cc_library_static(
     name = "lib",
     copts = select({
-        "//build/bazel/product_config/config_settings:vendor_bar__feature": ["-DVENDOR_BAR_FEATURE"],
+        "@soong_injection//soong_config_settings:vendor_bar__feature": ["-DVENDOR_BAR_FEATURE"],
         "//conditions:default": ["-DVENDOR_BAR_DEFAULT"],
     }) + select({
-        "//build/bazel/product_config/config_settings:vendor_foo__feature": ["-DVENDOR_FOO_FEATURE"],
+        "@soong_injection//soong_config_settings:vendor_foo__feature": ["-DVENDOR_FOO_FEATURE"],
         "//conditions:default": ["-DVENDOR_FOO_DEFAULT"],
     }) + select({
-        "//build/bazel/product_config/config_settings:vendor_qux__feature": ["-DVENDOR_QUX_FEATURE"],
+        "@soong_injection//soong_config_settings:vendor_qux__feature": ["-DVENDOR_QUX_FEATURE"],
         "//conditions:default": ["-DVENDOR_QUX_DEFAULT"],
     }),
     local_includes = ["."],
@@ -1216,7 +1279,7 @@ cc_binary {
         "//build/bazel_common_rules/platforms/os_arch:windows_x86_64": ["@platforms//:incompatible"],
         "//conditions:default": [],
     }) + select({
-        "//build/bazel/product_config/config_settings:alphabet_module__special_build": [],
+        "@soong_injection//soong_config_settings:alphabet_module__special_build": [],
         "//conditions:default": ["@platforms//:incompatible"],
     }),
 )`}})
@@ -1281,7 +1344,7 @@ cc_binary {
     local_includes = ["."],
     srcs = ["main.cc"],
     target_compatible_with = select({
-        "//build/bazel/product_config/config_settings:alphabet_module__special_build": [],
+        "@soong_injection//soong_config_settings:alphabet_module__special_build": [],
         "//conditions:default": ["@platforms//:incompatible"],
     }),
 )`,
@@ -1426,8 +1489,8 @@ cc_binary {
         "//build/bazel_common_rules/platforms/os:android": ["-DFOO"],
         "//conditions:default": [],
     }) + select({
-        "//build/bazel/product_config/config_settings:my_namespace__my_bool_variable__android": ["-DBAR"],
-        "//build/bazel/product_config/config_settings:my_namespace__my_bool_variable__conditions_default__android": ["-DBAZ"],
+        "@soong_injection//soong_config_settings:my_namespace__my_bool_variable__android": ["-DBAR"],
+        "@soong_injection//soong_config_settings:my_namespace__my_bool_variable__conditions_default__android": ["-DBAZ"],
         "//conditions:default": [],
     }) + select({
         "//build/bazel/product_config/config_settings:my_namespace__my_string_variable__value1": ["-DVALUE1_NOT_ANDROID"],
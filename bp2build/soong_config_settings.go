@@ -0,0 +1,130 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/android/soongconfig"
+	"android/soong/bazel"
+)
+
+// soongConfigFlagsDir is the package of soong_injection holding a bool_flag for each
+// Soong config bool variable selected on by a target, which the bazelrc of a product sets.
+const soongConfigFlagsDir = "soong_config_variables"
+
+// soongConfigFlagLabel returns the label of the bool_flag of the given Soong config bool
+// variable.
+func soongConfigFlagLabel(flag string) string {
+	return fmt.Sprintf("@%s//%s:%s", bazel.SoongInjectionDirName, soongConfigFlagsDir, flag)
+}
+
+// soongConfigBoolSettingsFiles returns the soong_injection BUILD files defining a
+// bool_flag for each Soong config bool variable the given targets select on, and a
+// config_setting for each select() key of those variables, e.g. "acme__feature" or
+// "acme__feature__conditions_default__android". No files are returned if the targets
+// don't select on any bool variable.
+//
+// The select() keys lowercase the namespace and variable names, so it is an error
+// for several variables to map to the same key.
+func soongConfigBoolSettingsFiles(defs soongconfig.Bp2BuildSoongConfigDefinitions, buildToTargets map[string]BazelTargets) ([]BazelFile, error) {
+	// Map the lowercased keys of the bool variables to their variable, to find
+	// the variable of a select() key.
+	boolVars := map[string]string{}
+	for _, variable := range android.SortedKeys(defs.BoolVars) {
+		key := strings.ToLower(variable)
+		if existing, ok := boolVars[key]; ok {
+			return nil, fmt.Errorf("soong config bool variables %q and %q map to the same config setting %q", existing, variable, key)
+		}
+		boolVars[key] = variable
+	}
+
+	flags := map[string]bool{}
+	settings := map[string]map[string]string{}
+	axisPrefix := bazel.SoongConfigBoolVariableConfigurationAxis("").Name() + ":"
+	for _, dir := range android.SortedKeys(buildToTargets) {
+		for _, target := range buildToTargets[dir] {
+			for _, branches := range target.selectBranches {
+				for _, branch := range branches {
+					// The axes of Soong config variables are named after
+					// <namespace>__<variable>__<os>.
+					parts := strings.Split(strings.TrimPrefix(branch.Axis, axisPrefix), "__")
+					if !strings.HasPrefix(branch.Axis, axisPrefix) || len(parts) != 3 {
+						continue
+					}
+					flag := strings.ToLower(parts[0] + "__" + parts[1])
+					if _, ok := boolVars[flag]; !ok || branch.Config == bazel.ConditionsDefaultConfigKey {
+						continue
+					}
+					if _, ok := settings[branch.Config]; ok {
+						continue
+					}
+					flags[flag] = true
+					settings[branch.Config] = soongConfigBoolSetting(flag, branch.Config)
+				}
+			}
+		}
+	}
+
+	if len(flags) == 0 {
+		return nil, nil
+	}
+
+	var flagTargets, settingTargets []string
+	for _, flag := range android.SortedKeys(flags) {
+		flagTargets = append(flagTargets, ruleTargetContent("bool_flag", flag, map[string]string{
+			"build_setting_default": "False",
+		}))
+	}
+	for _, name := range android.SortedKeys(settings) {
+		settingTargets = append(settingTargets, ruleTargetContent("config_setting", name, settings[name]))
+	}
+
+	return []BazelFile{
+		newFile(soongConfigFlagsDir, GeneratedBuildFileName, fmt.Sprintf(`load("@bazel_skylib//rules:common_settings.bzl", "bool_flag")
+
+package(default_visibility = ["//visibility:public"])
+
+%s
+`, strings.Join(flagTargets, "\n\n"))),
+		newFile(bazel.SoongConfigBoolSettingsDir, GeneratedBuildFileName, fmt.Sprintf(`package(default_visibility = ["//visibility:public"])
+
+%s
+`, strings.Join(settingTargets, "\n\n"))),
+	}, nil
+}
+
+// soongConfigBoolSetting returns the attributes of the config_setting for the given
+// select() key of a Soong config bool variable, which is one of <flag>,
+// <flag>__<os> or <flag>__conditions_default__<os>.
+func soongConfigBoolSetting(flag, selectKey string) map[string]string {
+	value := "True"
+	os := strings.TrimPrefix(strings.TrimPrefix(selectKey, flag), "__")
+	if strings.HasPrefix(os, bazel.ConditionsDefaultConfigKey) {
+		value = "False"
+		os = strings.TrimPrefix(strings.TrimPrefix(os, bazel.ConditionsDefaultConfigKey), "__")
+	}
+	attrs := map[string]string{
+		"flag_values": fmt.Sprintf(`{
+        "%s": "%s",
+    }`, soongConfigFlagLabel(flag), value),
+	}
+	if os != "" {
+		attrs["constraint_values"] = fmt.Sprintf(`["%s"]`, bazel.OsConfigurationAxis.SelectKey(os))
+	}
+	return attrs
+}
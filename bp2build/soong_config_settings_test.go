@@ -0,0 +1,220 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"strings"
+	"testing"
+
+	"android/soong/android"
+	"android/soong/android/soongconfig"
+	"android/soong/bazel"
+)
+
+func TestSoongConfigBoolSettingsFiles(t *testing.T) {
+	copts := bazel.StringListAttribute{}
+	copts.SetSelectValue(bazel.SoongConfigBoolVariableConfigurationAxis("acme__feature__"), "acme__feature", []string{"-DFEATURE"})
+	copts.SetSelectValue(bazel.ProductVariableConfigurationAxis(false, "acme__board__"), "acme__board__soc_a", []string{"-DSOC_A"})
+	srcs := bazel.StringListAttribute{}
+	srcs.SetSelectValue(bazel.SoongConfigBoolVariableConfigurationAxis("Acme__Other__android"), "acme__other__android", []string{"other.cpp"})
+	srcs.SetSelectValue(bazel.SoongConfigBoolVariableConfigurationAxis("Acme__Other__android"), "acme__other__conditions_default__android", []string{"no_other.cpp"})
+	attrs := struct {
+		Copts bazel.StringListAttribute
+		Srcs  bazel.StringListAttribute
+	}{
+		Copts: copts,
+		Srcs:  srcs,
+	}
+	target, err := generateBazelTarget(nil, bTarget{
+		targetName:     "foo",
+		targetPackage:  "pkg",
+		bazelRuleClass: "cc_library_static",
+		bazelAttributes: []interface{}{
+			&attrs,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defs := soongconfig.Bp2BuildSoongConfigDefinitions{
+		BoolVars: map[string]bool{
+			"acme__feature": true,
+			"Acme__Other":   true,
+			"acme__unused":  true,
+		},
+		StringVars: map[string]map[string]bool{
+			"acme__board": {"soc_a": true},
+		},
+	}
+	files, err := soongConfigBoolSettingsFiles(defs, map[string]BazelTargets{"pkg": {target}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+
+	android.AssertStringEquals(t, "flags package", soongConfigFlagsDir, files[0].Dir)
+	android.AssertStringEquals(t, "flags", `load("@bazel_skylib//rules:common_settings.bzl", "bool_flag")
+
+package(default_visibility = ["//visibility:public"])
+
+bool_flag(
+    name = "acme__feature",
+    build_setting_default = False,
+)
+
+bool_flag(
+    name = "acme__other",
+    build_setting_default = False,
+)
+`, files[0].Contents)
+
+	android.AssertStringEquals(t, "settings package", bazel.SoongConfigBoolSettingsDir, files[1].Dir)
+	android.AssertStringEquals(t, "settings", `package(default_visibility = ["//visibility:public"])
+
+config_setting(
+    name = "acme__feature",
+    flag_values = {
+        "@soong_injection//soong_config_variables:acme__feature": "True",
+    },
+)
+
+config_setting(
+    name = "acme__other__android",
+    constraint_values = ["//build/bazel_common_rules/platforms/os:android"],
+    flag_values = {
+        "@soong_injection//soong_config_variables:acme__other": "True",
+    },
+)
+
+config_setting(
+    name = "acme__other__conditions_default__android",
+    constraint_values = ["//build/bazel_common_rules/platforms/os:android"],
+    flag_values = {
+        "@soong_injection//soong_config_variables:acme__other": "False",
+    },
+)
+`, files[1].Contents)
+}
+
+// TestSoongConfigBoolSettingsLabels checks that the generated config_settings are
+// the targets the select() keys of Soong config bool variables refer to, and that
+// their bool_flags are the ones the platform mappings of products set.
+func TestSoongConfigBoolSettingsLabels(t *testing.T) {
+	copts := bazel.StringListAttribute{}
+	copts.SetSelectValue(bazel.SoongConfigBoolVariableConfigurationAxis("acme__feature__"), "acme__feature", []string{"-DFEATURE"})
+	attrs := struct {
+		Copts bazel.StringListAttribute
+	}{
+		Copts: copts,
+	}
+	target, err := generateBazelTarget(nil, bTarget{
+		targetName:      "foo",
+		targetPackage:   "pkg",
+		bazelRuleClass:  "cc_library_static",
+		bazelAttributes: []interface{}{&attrs},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs := soongconfig.Bp2BuildSoongConfigDefinitions{
+		BoolVars: map[string]bool{"acme__feature": true},
+	}
+	files, err := soongConfigBoolSettingsFiles(defs, map[string]BazelTargets{"pkg": {target}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags, settings := files[0], files[1]
+
+	branches := target.selectBranches["copts"]
+	if len(branches) != 1 {
+		t.Fatalf("Expected one select() branch, got %v", branches)
+	}
+	android.AssertStringEquals(t, "config_setting label", branches[0].SelectKey, "@soong_injection//"+settings.Dir+":acme__feature")
+	android.AssertStringDoesContain(t, "config_settings", settings.Contents, `name = "acme__feature",`)
+	android.AssertStringDoesContain(t, "config_setting flag", settings.Contents, `"@soong_injection//`+flags.Dir+`:acme__feature": "True"`)
+
+	var platformMapping strings.Builder
+	productVariables := &android.ProductVariables{
+		VendorVars: map[string]map[string]string{
+			"acme": {"feature": "true"},
+		},
+	}
+	platformMappingSingleProduct(bazelLabel{pkg: "product", target: "acme"}, productVariables, defs, nil, &platformMapping)
+	android.AssertStringDoesContain(t, "platform mapping", platformMapping.String(), "--@soong_injection//"+flags.Dir+":acme__feature=true\n")
+	android.AssertStringDoesContain(t, "bool_flags", flags.Contents, `name = "acme__feature",`)
+}
+
+func TestSoongConfigBoolSettingsFilesCollision(t *testing.T) {
+	testCases := []struct {
+		description string
+		defs        soongconfig.Bp2BuildSoongConfigDefinitions
+		expectedErr string
+	}{
+		{
+			description: "bool variables differing in case",
+			defs: soongconfig.Bp2BuildSoongConfigDefinitions{
+				BoolVars: map[string]bool{
+					"Acme__feature": true,
+					"acme__feature": true,
+				},
+			},
+			expectedErr: `soong config bool variables "Acme__feature" and "acme__feature" map to the same config setting "acme__feature"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := soongConfigBoolSettingsFiles(tc.defs, nil)
+			if err == nil {
+				t.Fatalf("Expected error %q, got none", tc.expectedErr)
+			}
+			android.AssertStringEquals(t, "error", tc.expectedErr, err.Error())
+		})
+	}
+}
+
+func TestSoongConfigBoolSettingsFilesWithoutBoolVariables(t *testing.T) {
+	copts := bazel.StringListAttribute{}
+	copts.SetSelectValue(bazel.ProductVariableConfigurationAxis(false, "acme__board__"), "acme__board__soc_a", []string{"-DSOC_A"})
+	attrs := struct {
+		Copts bazel.StringListAttribute
+	}{
+		Copts: copts,
+	}
+	target, err := generateBazelTarget(nil, bTarget{
+		targetName:      "foo",
+		targetPackage:   "pkg",
+		bazelRuleClass:  "cc_library_static",
+		bazelAttributes: []interface{}{&attrs},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs := soongconfig.Bp2BuildSoongConfigDefinitions{
+		BoolVars: map[string]bool{"acme__feature": true},
+		StringVars: map[string]map[string]bool{
+			"acme__board": {"soc_a": true},
+		},
+	}
+	files, err := soongConfigBoolSettingsFiles(defs, map[string]BazelTargets{"pkg": {target}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no files, got %v", files)
+	}
+}
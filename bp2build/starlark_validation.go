@@ -0,0 +1,47 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"path/filepath"
+	"strings"
+
+	"go.starlark.net/syntax"
+)
+
+// isStarlarkFile returns true for the generated files that Bazel loads as
+// Starlark, i.e. BUILD and .bzl files.
+func isStarlarkFile(basename string) bool {
+	return basename == "BUILD" || basename == "WORKSPACE" ||
+		strings.HasSuffix(basename, ".bazel") || strings.HasSuffix(basename, ".bzl")
+}
+
+// validateStarlarkSyntax parses each generated BUILD and .bzl file with the
+// Starlark parser, so that malformed attribute values (e.g. unbalanced quotes
+// or stray commas) are reported when generating the files rather than when
+// Bazel later loads them. Each returned error is prefixed with the path of the
+// file and the position of the syntax error in it.
+func validateStarlarkSyntax(files []BazelFile) []error {
+	var errs []error
+	for _, f := range files {
+		if !isStarlarkFile(f.Basename) {
+			continue
+		}
+		if _, err := syntax.Parse(filepath.Join(f.Dir, f.Basename), f.Contents, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
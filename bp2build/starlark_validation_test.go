@@ -0,0 +1,46 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateStarlarkSyntax(t *testing.T) {
+	files := []BazelFile{
+		newFile("a", GeneratedBuildFileName, `cc_library(
+    name = "a",
+    copts = ["-DFOO=\"bar\""],
+)`),
+		newFile("b", GeneratedBuildFileName, `cc_library(
+    name = "b",
+    copts = ["-DFOO="bar""],
+)`),
+		newFile("c", "defs.bzl", `x = [1,, 2]`),
+		// Not a Starlark file, so it isn't parsed.
+		newFile("d", "metadata.json", `{`),
+	}
+
+	errs := validateStarlarkSyntax(files)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	for i, prefix := range []string{"b/BUILD.bazel:3:", "c/defs.bzl:1:"} {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			t.Errorf("Expected error %d to start with %q, got %q", i, prefix, errs[i].Error())
+		}
+	}
+}
@@ -288,7 +288,7 @@ func (b *bazelTestRunner) PostParseProcessor(result android.CustomTestResult) {
 	}
 
 	// Store additional data for access by tests.
-	bazelResult.conversionResults = res
+	bazelResult.ConversionResults = res
 }
 
 // BazelTestResult is a wrapper around android.TestResult to provide type safe access to the bazel
@@ -297,7 +297,7 @@ type BazelTestResult struct {
 	*android.TestResult
 
 	// The result returned by the GenerateBazelTargets function.
-	conversionResults
+	ConversionResults
 }
 
 // CompareAllBazelTargets compares the BazelTargets produced by the test for all the directories
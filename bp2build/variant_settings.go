@@ -0,0 +1,85 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"strings"
+
+	"android/soong/bazel"
+)
+
+const (
+	// imageFlag is the string_flag holding the image variant a target is built for,
+	// which is "core" outside of the vendor, product and recovery variants.
+	imageFlag = "image"
+	// nativeBridgeFlag is the bool_flag set when a target is built for the native
+	// bridge variant.
+	nativeBridgeFlag = "enabled"
+)
+
+// variantSettingsFiles returns the soong_injection BUILD files defining the
+// config_settings the select() keys of the image and native bridge axes refer to,
+// along with the flags backing them, which the transitions into those variants set.
+func variantSettingsFiles() []BazelFile {
+	images := []string{bazel.ImageProduct, bazel.ImageRecovery, bazel.ImageVendor}
+	imageDir, _ := splitSelectKey(bazel.ImageAxis.SelectKey(bazel.ImageVendor))
+	imageTargets := []string{ruleTargetContent("string_flag", imageFlag, map[string]string{
+		"build_setting_default": `"core"`,
+		"values":                fmt.Sprintf(`["core", "%s"]`, strings.Join(images, `", "`)),
+	})}
+	for _, image := range images {
+		_, name := splitSelectKey(bazel.ImageAxis.SelectKey(image))
+		imageTargets = append(imageTargets, ruleTargetContent("config_setting", name, map[string]string{
+			"flag_values": fmt.Sprintf(`{
+        ":%s": "%s",
+    }`, imageFlag, image),
+		}))
+	}
+
+	nativeBridgeDir, nativeBridgeName := splitSelectKey(bazel.NativeBridgeAxis.SelectKey(bazel.NativeBridge))
+	nativeBridgeTargets := []string{
+		ruleTargetContent("bool_flag", nativeBridgeFlag, map[string]string{
+			"build_setting_default": "False",
+		}),
+		ruleTargetContent("config_setting", nativeBridgeName, map[string]string{
+			"flag_values": fmt.Sprintf(`{
+        ":%s": "True",
+    }`, nativeBridgeFlag),
+		}),
+	}
+
+	return []BazelFile{
+		newFile(imageDir, GeneratedBuildFileName, fmt.Sprintf(`load("@bazel_skylib//rules:common_settings.bzl", "string_flag")
+
+package(default_visibility = ["//visibility:public"])
+
+%s
+`, strings.Join(imageTargets, "\n\n"))),
+		newFile(nativeBridgeDir, GeneratedBuildFileName, fmt.Sprintf(`load("@bazel_skylib//rules:common_settings.bzl", "bool_flag")
+
+package(default_visibility = ["//visibility:public"])
+
+%s
+`, strings.Join(nativeBridgeTargets, "\n\n"))),
+	}
+}
+
+// splitSelectKey returns the soong_injection package and target name of a select()
+// key, e.g. "image" and "vendor" for "@soong_injection//image:vendor".
+func splitSelectKey(key string) (string, string) {
+	dir, name, _ := strings.Cut(strings.TrimPrefix(key, "@"+bazel.SoongInjectionDirName+"//"), ":")
+	return dir, name
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+	"android/soong/bazel"
+)
+
+func TestVariantSettingsFiles(t *testing.T) {
+	files := variantSettingsFiles()
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+
+	android.AssertStringEquals(t, "image package", "image", files[0].Dir)
+	android.AssertStringEquals(t, "image settings", `load("@bazel_skylib//rules:common_settings.bzl", "string_flag")
+
+package(default_visibility = ["//visibility:public"])
+
+string_flag(
+    name = "image",
+    build_setting_default = "core",
+    values = ["core", "product", "recovery", "vendor"],
+)
+
+config_setting(
+    name = "product",
+    flag_values = {
+        ":image": "product",
+    },
+)
+
+config_setting(
+    name = "recovery",
+    flag_values = {
+        ":image": "recovery",
+    },
+)
+
+config_setting(
+    name = "vendor",
+    flag_values = {
+        ":image": "vendor",
+    },
+)
+`, files[0].Contents)
+
+	android.AssertStringEquals(t, "native bridge package", "native_bridge", files[1].Dir)
+	android.AssertStringEquals(t, "native bridge settings", `load("@bazel_skylib//rules:common_settings.bzl", "bool_flag")
+
+package(default_visibility = ["//visibility:public"])
+
+bool_flag(
+    name = "enabled",
+    build_setting_default = False,
+)
+
+config_setting(
+    name = "native_bridge",
+    flag_values = {
+        ":enabled": "True",
+    },
+)
+`, files[1].Contents)
+}
+
+func TestVariantSettingsFilesDefineSelectKeys(t *testing.T) {
+	files := variantSettingsFiles()
+	for axis, configs := range map[bazel.ConfigurationAxis][]string{
+		bazel.ImageAxis:        {bazel.ImageProduct, bazel.ImageRecovery, bazel.ImageVendor},
+		bazel.NativeBridgeAxis: {bazel.NativeBridge},
+	} {
+		for _, config := range configs {
+			key := axis.SelectKey(config)
+			dir, name := splitSelectKey(key)
+			defined := false
+			for _, file := range files {
+				if file.Dir == dir && strings.Contains(file.Contents, fmt.Sprintf("config_setting(\n    name = %q,", name)) {
+					defined = true
+				}
+			}
+			if !defined {
+				t.Errorf("select() key %q is not defined by the generated settings", key)
+			}
+		}
+	}
+}
@@ -605,6 +605,7 @@ func binaryBp2buildAttrs(ctx android.Bp2buildMutatorContext, m *Module) binaryAt
 
 	if proptools.BoolDefault(binaryLinkerAttrs.Linkshared, true) {
 		baseAttrs.implementationDynamicDeps.Add(baseAttrs.protoDependency)
+		baseAttrs.implementationDynamicDeps.Append(baseAttrs.sanitizerRuntimeDynamicDeps)
 	} else {
 		baseAttrs.implementationDeps.Add(baseAttrs.protoDependency)
 	}
@@ -654,6 +655,7 @@ func binaryBp2buildAttrs(ctx android.Bp2buildMutatorContext, m *Module) binaryAt
 		SdkAttributes: Bp2BuildParseSdkAttributes(m),
 
 		Native_coverage: baseAttrs.Native_coverage,
+		Logtags:         baseAttrs.Logtags,
 	}
 
 	m.convertTidyAttributes(ctx, &attrs.tidyAttributes)
@@ -664,13 +666,19 @@ func binaryBp2buildAttrs(ctx android.Bp2buildMutatorContext, m *Module) binaryAt
 func binaryBp2build(ctx android.Bp2buildMutatorContext, m *Module) {
 	// shared with cc_test
 	binaryAttrs := binaryBp2buildAttrs(ctx, m)
+	binaryAttrs.Required = android.Bp2buildRequiredLabels(ctx)
 
 	tags := android.ApexAvailableTagsWithoutTestApexes(ctx, m)
 	ctx.CreateBazelTargetModule(bazel.BazelTargetModuleProperties{
 		Rule_class:        "cc_binary",
 		Bzl_load_location: "//build/bazel/rules/cc:cc_binary.bzl",
 	},
-		android.CommonAttributes{Name: m.Name(), Tags: tags},
+		android.CommonAttributes{
+			Name: m.Name(),
+			Tags: tags,
+			// The required property is converted to the required attribute instead.
+			SkipData: proptools.BoolPtr(true),
+		},
 		&binaryAttrs)
 }
 
@@ -713,4 +721,11 @@ type binaryAttributes struct {
 	tidyAttributes
 
 	Native_coverage *bool
+
+	// The .logtags files merged into the event-log-tags file of the partition.
+	Logtags bazel.LabelListAttribute
+
+	// Labels of the modules installed along with this binary. Unset for cc_test,
+	// which converts the required property to data.
+	Required bazel.LabelListAttribute
 }
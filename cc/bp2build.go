@@ -16,6 +16,8 @@ package cc
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -23,8 +25,10 @@ import (
 	"android/soong/bazel"
 	"android/soong/cc/config"
 	"android/soong/genrule"
+	"android/soong/ui/metrics/bp2build_metrics_proto"
 
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/pathtools"
 
 	"github.com/google/blueprint/proptools"
 )
@@ -81,6 +85,9 @@ type staticOrSharedAttributes struct {
 
 	Native_coverage *bool
 
+	// The .logtags files merged into the event-log-tags file of the partition.
+	Logtags bazel.LabelListAttribute
+
 	Apex_available []string
 
 	Features bazel.StringListAttribute
@@ -91,31 +98,40 @@ type staticOrSharedAttributes struct {
 }
 
 type tidyAttributes struct {
-	Tidy                  *string
-	Tidy_flags            []string
-	Tidy_checks           []string
-	Tidy_checks_as_errors []string
+	Tidy                  bazel.StringAttribute
+	Tidy_flags            bazel.StringListAttribute
+	Tidy_checks           bazel.StringListAttribute
+	Tidy_checks_as_errors bazel.StringListAttribute
 	Tidy_disabled_srcs    bazel.LabelListAttribute
 	Tidy_timeout_srcs     bazel.LabelListAttribute
 }
 
 func (m *Module) convertTidyAttributes(ctx android.Bp2buildMutatorContext, moduleAttrs *tidyAttributes) {
 	for _, f := range m.features {
-		if tidy, ok := f.(*tidyFeature); ok {
-			var tidyAttr *string
-			if tidy.Properties.Tidy != nil {
-				if *tidy.Properties.Tidy {
-					tidyAttr = proptools.StringPtr("local")
-				} else {
-					tidyAttr = proptools.StringPtr("never")
+		if _, ok := f.(*tidyFeature); ok {
+			for axis, configToProps := range m.GetArchVariantProperties(ctx, &TidyProperties{}) {
+				for cfg, _props := range configToProps {
+					if tidyProps, ok := _props.(*TidyProperties); ok {
+						if tidyProps.Tidy != nil {
+							tidyAttr := "never"
+							if *tidyProps.Tidy {
+								tidyAttr = "local"
+							}
+							moduleAttrs.Tidy.SetSelectValue(axis, cfg, proptools.StringPtr(tidyAttr))
+						}
+						if tidyProps.Tidy_flags != nil {
+							moduleAttrs.Tidy_flags.SetSelectValue(axis, cfg, tidyProps.Tidy_flags)
+						}
+						if tidyProps.Tidy_checks != nil {
+							moduleAttrs.Tidy_checks.SetSelectValue(axis, cfg, tidyProps.Tidy_checks)
+						}
+						if tidyProps.Tidy_checks_as_errors != nil {
+							moduleAttrs.Tidy_checks_as_errors.SetSelectValue(axis, cfg, tidyProps.Tidy_checks_as_errors)
+						}
+					}
 				}
 			}
-			moduleAttrs.Tidy = tidyAttr
-			moduleAttrs.Tidy_flags = tidy.Properties.Tidy_flags
-			moduleAttrs.Tidy_checks = tidy.Properties.Tidy_checks
-			moduleAttrs.Tidy_checks_as_errors = tidy.Properties.Tidy_checks_as_errors
 		}
-
 	}
 	archVariantProps := m.GetArchVariantProperties(ctx, &BaseCompilerProperties{})
 	for axis, configToProps := range archVariantProps {
@@ -187,11 +203,24 @@ func groupSrcsByExtension(ctx android.BazelConversionPathContext, srcs bazel.Lab
 		}
 	}
 
+	// The outputs of gensrcs modules all have the same extension, so they can be partitioned by
+	// language, unlike the outputs of genrules.
+	addSuffixForFilegroupOrGensrcs := func(suffix string, gensrcsExtensions ...string) bazel.LabelMapper {
+		gensrcsMapper := genrule.GensrcsOutputExtensionLabelMapper(gensrcsExtensions...)
+		filegroupMapper := addSuffixForFilegroup(suffix)
+		return func(otherModuleCtx bazel.OtherModuleContext, label bazel.Label) (string, bool) {
+			if labelStr, ok := gensrcsMapper(otherModuleCtx, label); ok {
+				return labelStr, true
+			}
+			return filegroupMapper(otherModuleCtx, label)
+		}
+	}
+
 	// TODO(b/190006308): Handle language detection of sources in a Bazel rule.
 	labels := bazel.LabelPartitions{
 		protoSrcPartition: android.ProtoSrcLabelPartition,
-		cSrcPartition:     bazel.LabelPartition{Extensions: []string{".c"}, LabelMapper: addSuffixForFilegroup("_c_srcs")},
-		asSrcPartition:    bazel.LabelPartition{Extensions: []string{".s", ".S"}, LabelMapper: addSuffixForFilegroup("_as_srcs")},
+		cSrcPartition:     bazel.LabelPartition{Extensions: []string{".c"}, LabelMapper: addSuffixForFilegroupOrGensrcs("_c_srcs", "c")},
+		asSrcPartition:    bazel.LabelPartition{Extensions: []string{".s", ".S"}, LabelMapper: addSuffixForFilegroupOrGensrcs("_as_srcs", "s", "S")},
 		asmSrcPartition:   bazel.LabelPartition{Extensions: []string{".asm"}},
 		aidlSrcPartition:  android.AidlSrcLabelPartition,
 		// TODO(http://b/231968910): If there is ever a filegroup target that
@@ -294,6 +323,8 @@ func bp2buildParseStaticOrSharedProps(ctx android.Bp2buildMutatorContext, module
 	setAttrs := func(axis bazel.ConfigurationAxis, config string, props StaticOrSharedProperties) {
 		attrs.Copts.SetSelectValue(axis, config, parseCommandLineFlags(props.Cflags, filterOutStdFlag, filterOutHiddenVisibility))
 		attrs.Srcs.SetSelectValue(axis, config, android.BazelLabelForModuleSrc(ctx, props.Srcs))
+		attrs.Tidy_disabled_srcs.SetSelectValue(axis, config, android.BazelLabelForModuleSrc(ctx, props.Tidy_disabled_srcs))
+		attrs.Tidy_timeout_srcs.SetSelectValue(axis, config, android.BazelLabelForModuleSrc(ctx, props.Tidy_timeout_srcs))
 		attrs.System_dynamic_deps.SetSelectValue(axis, config, bazelLabelForSharedDeps(ctx, props.System_shared_libs))
 
 		staticDeps := maybePartitionExportedAndImplementationsDeps(ctx, true, props.Static_libs, props.Export_static_lib_headers, bazelLabelForStaticDeps)
@@ -442,6 +473,7 @@ type baseAttributes struct {
 	protoDependency *bazel.LabelAttribute
 	aidlDependency  *bazel.LabelAttribute
 	Native_coverage *bool
+	Logtags         bazel.LabelListAttribute
 }
 
 // Convenience struct to hold all attributes parsed from compiler properties.
@@ -550,14 +582,79 @@ func parseCommandLineFlags(soongFlags []string, filterOut ...filterOutFn) []stri
 		// Soong's cflags can contain spaces, like `-include header.h`. For
 		// Bazel's copts, split them up to be compatible with the
 		// no_copts_tokenization feature.
-		result = append(result, strings.Split(flag, " ")...)
+		result = append(result, splitCommandLineFlag(flag)...)
 	}
 	return result
 }
 
+var makeVarReferencePattern = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// bazelMakeVars maps the build variables which can be referenced in cc flags to the make
+// variables Bazel provides for them, the same as for product_variables substitutions.
+var bazelMakeVars = map[string]string{
+	"PLATFORM_SDK_VERSION": "Platform_sdk_version",
+}
+
+// bp2buildMakeVarReferences translates the Make-style $(VAR) references in the given flags
+// to references to the Bazel make variables in bazelMakeVars, e.g. $(PLATFORM_SDK_VERSION)
+// to $(Platform_sdk_version). Bazel fails to expand references to other variables, so the
+// module is marked unconvertible for them.
+func bp2buildMakeVarReferences(ctx android.Bp2buildMutatorContext, prop string, flags []string) []string {
+	var result []string
+	for _, flag := range flags {
+		result = append(result, makeVarReferencePattern.ReplaceAllStringFunc(flag, func(ref string) string {
+			makeVar := makeVarReferencePattern.FindStringSubmatch(ref)[1]
+			bazelMakeVar, ok := bazelMakeVars[makeVar]
+			if !ok {
+				ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED,
+					fmt.Sprintf("reference to build variable %s in %s", makeVar, prop))
+				return ref
+			}
+			return "$(" + bazelMakeVar + ")"
+		}))
+	}
+	return result
+}
+
+// splitCommandLineFlag splits a flag on the spaces the shell would split it on, i.e. spaces that
+// are neither quoted nor escaped. Quotes and escapes are preserved in the returned arguments, so
+// that e.g. `-DNAME=\"a b\"` stays a single argument.
+func splitCommandLineFlag(flag string) []string {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	escaped := false
+	for _, c := range flag {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ':
+			if arg.Len() > 0 {
+				args = append(args, arg.String())
+				arg.Reset()
+			}
+			continue
+		}
+		arg.WriteRune(c)
+	}
+	if arg.Len() > 0 {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
 func (ca *compilerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutatorContext, axis bazel.ConfigurationAxis, config string, props *BaseCompilerProperties) {
-	// If there's arch specific srcs or exclude_srcs, generate a select entry for it.
-	// TODO(b/186153868): do this for OS specific srcs and exclude_srcs too.
+	// If there's arch or OS specific srcs or exclude_srcs, generate a select entry for it. The
+	// excludes are only applied to this configuration when resolving the attribute, so a file
+	// excluded here is still compiled for the other configurations.
 	srcsList, ok := parseSrcs(ctx, props)
 
 	if ok {
@@ -575,22 +672,102 @@ func (ca *compilerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutat
 	ca.absoluteIncludes.SetSelectValue(axis, config, props.Include_dirs)
 	ca.localIncludes.SetSelectValue(axis, config, localIncludeDirs)
 
-	instructionSet := proptools.StringDefault(props.Instruction_set, "")
-	if instructionSet == "arm" {
-		ca.features.SetSelectValue(axis, config, []string{"arm_isa_arm"})
-	} else if instructionSet != "" && instructionSet != "thumb" {
-		ctx.ModuleErrorf("Unknown value for instruction_set: %s", instructionSet)
+	var axisFeatures []string
+	switch instructionSet := proptools.String(props.Instruction_set); instructionSet {
+	case "":
+	case "arm", "thumb":
+		if !supportsInstructionSet(axis, config) {
+			ctx.PropertyErrorf("instruction_set", "%q is only supported for arm, not %s", instructionSet, config)
+		} else if instructionSet == "arm" {
+			axisFeatures = append(axisFeatures, "arm_isa_arm")
+		}
+	default:
+		ctx.PropertyErrorf("instruction_set", "%q is not a supported instruction set, expected \"arm\" or \"thumb\"", instructionSet)
+	}
+	if axisFeatures != nil {
+		ca.features.SetSelectValue(axis, config, axisFeatures)
 	}
 
 	// In Soong, cflags occur on the command line before -std=<val> flag, resulting in the value being
 	// overridden. In Bazel we always allow overriding, via flags; however, this can cause
 	// incompatibilities, so we remove "-std=" flags from Cflag properties while leaving it in other
 	// cases.
-	ca.copts.SetSelectValue(axis, config, parseCommandLineFlags(props.Cflags, filterOutStdFlag, filterOutClangUnknownCflags, filterOutHiddenVisibility))
-	ca.asFlags.SetSelectValue(axis, config, parseCommandLineFlags(props.Asflags, nil))
-	ca.conlyFlags.SetSelectValue(axis, config, parseCommandLineFlags(props.Conlyflags, filterOutClangUnknownCflags))
-	ca.cppFlags.SetSelectValue(axis, config, parseCommandLineFlags(props.Cppflags, filterOutClangUnknownCflags))
+	ca.copts.SetSelectValue(axis, config, bp2buildMakeVarReferences(ctx, "cflags", parseCommandLineFlags(props.Cflags, filterOutStdFlag, filterOutClangUnknownCflags, filterOutHiddenVisibility)))
+	ca.asFlags.SetSelectValue(axis, config, bp2buildMakeVarReferences(ctx, "asflags", parseCommandLineFlags(props.Asflags, nil)))
+	ca.conlyFlags.SetSelectValue(axis, config, bp2buildMakeVarReferences(ctx, "conlyflags", parseCommandLineFlags(props.Conlyflags, filterOutClangUnknownCflags)))
+	ca.cppFlags.SetSelectValue(axis, config, bp2buildMakeVarReferences(ctx, "cppflags", parseCommandLineFlags(props.Cppflags, filterOutClangUnknownCflags)))
 	ca.rtti.SetSelectValue(axis, config, props.Rtti)
+
+	if axis == bazel.NoConfigAxis {
+		ca.resolveTargetImageProps(ctx, props)
+	}
+}
+
+// supportsInstructionSet returns whether the instruction_set property may be set for the given
+// configuration. It selects between the arm and thumb instruction sets, so like the Soong
+// toolchains, only arm configurations support it. Whether the base value is supported depends on
+// the configuration it applies to, so it is not checked.
+func supportsInstructionSet(axis bazel.ConfigurationAxis, config string) bool {
+	switch axis {
+	case bazel.ArchConfigurationAxis:
+		return config == "arm"
+	case bazel.OsArchConfigurationAxis:
+		return strings.HasSuffix(config, "_arm")
+	default:
+		return true
+	}
+}
+
+// resolveTargetImageProps converts the srcs, exclude_srcs and cflags specific to the
+// vendor, product and recovery variants of the module to selects on the image axis.
+func (ca *compilerAttributes) resolveTargetImageProps(ctx android.Bp2buildMutatorContext, props *BaseCompilerProperties) {
+	vendor, product := props.Target.Vendor, props.Target.Product
+	ca.setSrcsAndCflags(ctx, bazel.ImageAxis, bazel.ImageVendor, vendor.Srcs, vendor.Exclude_srcs, vendor.Cflags)
+	ca.setSrcsAndCflags(ctx, bazel.ImageAxis, bazel.ImageProduct, product.Srcs, product.Exclude_srcs, product.Cflags)
+	recovery := props.Target.Recovery
+	ca.setSrcsAndCflags(ctx, bazel.ImageAxis, bazel.ImageRecovery, recovery.Srcs, recovery.Exclude_srcs, recovery.Cflags)
+}
+
+// convertNativeBridgeProps converts the srcs, exclude_srcs and cflags of the target.native_bridge
+// properties, which only apply to the variants of the module translated with native bridge (e.g.
+// arm on x86), to selects on the native bridge axis.
+func (ca *compilerAttributes) convertNativeBridgeProps(ctx android.Bp2buildMutatorContext, module *Module) {
+	if props, ok := module.GetNativeBridgeProperties(ctx, &BaseCompilerProperties{}).(*BaseCompilerProperties); ok {
+		ca.setSrcsAndCflags(ctx, bazel.NativeBridgeAxis, bazel.NativeBridge, props.Srcs, props.Exclude_srcs, props.Cflags)
+	}
+}
+
+// convertUniqueHostSoname appends "-host" to the suffix of the host variants of a library with
+// unique_host_soname, which are renamed to prevent overlap with system installed libraries.
+func (ca *compilerAttributes) convertUniqueHostSoname(ctx android.Bp2buildMutatorContext, module *Module) {
+	library, ok := module.linker.(*libraryDecorator)
+	if !ok || !Bool(library.Properties.Unique_host_soname) {
+		return
+	}
+	if ca.stem.HasConfigurableValues() || ca.suffix.HasConfigurableValues() {
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "unique_host_soname with a variant specific stem or suffix")
+		return
+	}
+	suffix := String(ca.suffix.Value)
+	if strings.HasSuffix(proptools.StringDefault(ca.stem.Value, module.Name())+suffix, "-host") {
+		return
+	}
+	for _, os := range android.OsTypeList() {
+		if os.Class == android.Host {
+			ca.suffix.SetSelectValue(bazel.OsConfigurationAxis, os.Name, proptools.StringPtr(suffix+"-host"))
+		}
+	}
+}
+
+// setSrcsAndCflags sets the srcs, exclude_srcs and cflags of a property struct which is not
+// returned by GetArchVariantProperties for the given config of axis.
+func (ca *compilerAttributes) setSrcsAndCflags(ctx android.Bp2buildMutatorContext, axis bazel.ConfigurationAxis, config string, srcs, excludeSrcs, cflags []string) {
+	if len(srcs) > 0 || len(excludeSrcs) > 0 {
+		srcsList := android.BazelLabelForModuleSrc(ctx, srcs)
+		srcsList.Excludes = android.BazelLabelForModuleSrc(ctx, excludeSrcs).Includes
+		ca.srcs.SetSelectValue(axis, config, srcsList)
+	}
+	ca.copts.SetSelectValue(axis, config, bp2buildMakeVarReferences(ctx, "cflags", parseCommandLineFlags(cflags, filterOutStdFlag, filterOutClangUnknownCflags, filterOutHiddenVisibility)))
 }
 
 func (ca *compilerAttributes) convertStlProps(ctx android.ArchVariantContext, module *Module) {
@@ -609,11 +786,12 @@ func (ca *compilerAttributes) convertStlProps(ctx android.ArchVariantContext, mo
 	})
 }
 
-func (ca *compilerAttributes) convertProductVariables(ctx android.BazelConversionPathContext, productVariableProps android.ProductConfigProperties) {
+func (ca *compilerAttributes) convertProductVariables(ctx android.Bp2buildMutatorContext, productVariableProps android.ProductConfigProperties) {
 	productVarPropNameToAttribute := map[string]*bazel.StringListAttribute{
-		"Cflags":   &ca.copts,
-		"Asflags":  &ca.asFlags,
-		"Cppflags": &ca.cppFlags,
+		"Cflags":     &ca.copts,
+		"Asflags":    &ca.asFlags,
+		"Conlyflags": &ca.conlyFlags,
+		"Cppflags":   &ca.cppFlags,
 	}
 	for propName, attr := range productVarPropNameToAttribute {
 		if productConfigProps, exists := productVariableProps[propName]; exists {
@@ -627,6 +805,38 @@ func (ca *compilerAttributes) convertProductVariables(ctx android.BazelConversio
 			}
 		}
 	}
+
+	// The srcs added or excluded for a product or soong config variable are partitioned by
+	// language in finalize, like the other srcs.
+	srcsProps, srcsExist := productVariableProps["Srcs"]
+	excludeSrcsProps, excludeSrcsExist := productVariableProps["Exclude_srcs"]
+	if !srcsExist && !excludeSrcsExist {
+		return
+	}
+	productConfigProps := make(map[android.ProductConfigOrSoongConfigProperty]bool, len(srcsProps)+len(excludeSrcsProps))
+	for p := range srcsProps {
+		productConfigProps[p] = true
+	}
+	for p := range excludeSrcsProps {
+		productConfigProps[p] = true
+	}
+	for productConfigProp := range productConfigProps {
+		prop, srcsExist := srcsProps[productConfigProp]
+		excludesProp, excludesExist := excludeSrcsProps[productConfigProp]
+		var srcs, excludeSrcs []string
+		var ok bool
+		if srcs, ok = prop.([]string); srcsExist && !ok {
+			ctx.ModuleErrorf("Could not convert product variable srcs property")
+		}
+		if excludeSrcs, ok = excludesProp.([]string); excludesExist && !ok {
+			ctx.ModuleErrorf("Could not convert product variable exclude_srcs property")
+		}
+		ca.srcs.SetSelectValue(
+			productConfigProp.ConfigurationAxis(),
+			productConfigProp.SelectKey(),
+			android.BazelLabelForModuleSrcExcludes(ctx, srcs, excludeSrcs),
+		)
+	}
 }
 
 func (ca *compilerAttributes) finalize(ctx android.BazelConversionPathContext, implementationHdrs, exportHdrs bazel.LabelListAttribute) {
@@ -743,10 +953,61 @@ func packageFromLabel(label string) (string, bool) {
 	return split[0][2:], true
 }
 
+// bp2buildValidateStubsSymbolFile reports an error if stubs.symbol_file is a glob or a file that
+// does not exist, rather than passing it through verbatim and deferring the failure to Bazel.
+func bp2buildValidateStubsSymbolFile(ctx android.Bp2buildMutatorContext, symbolFile string) bool {
+	if android.SrcIsModule(symbolFile) != "" {
+		return true
+	}
+	if pathtools.IsGlob(symbolFile) {
+		ctx.PropertyErrorf("stubs.symbol_file", "globs are not supported, got %q", symbolFile)
+		return false
+	}
+	if !android.ExistentPathForSource(ctx, ctx.ModuleDir(), symbolFile).Valid() {
+		ctx.PropertyErrorf("stubs.symbol_file", "%q does not exist, expected at %q",
+			symbolFile, filepath.Join(ctx.ModuleDir(), symbolFile))
+		return false
+	}
+	return true
+}
+
+// bp2buildStubsVersions resolves stubs.versions to API levels (e.g. the codenames of finalized
+// releases are replaced by their API level) and returns them deduplicated and sorted by API level,
+// as expected by the Bazel stub suite. Unlike in Soong, the versions need not be sorted already.
+func bp2buildStubsVersions(ctx android.Bp2buildMutatorContext, versions []string) []string {
+	apiLevels := make([]android.ApiLevel, 0, len(versions))
+	for _, v := range versions {
+		apiLevel, err := android.ApiLevelFromUser(ctx, v)
+		if err != nil {
+			ctx.PropertyErrorf("stubs.versions", "%s", err.Error())
+			return nil
+		}
+		apiLevels = append(apiLevels, apiLevel)
+	}
+	sort.SliceStable(apiLevels, func(i, j int) bool {
+		return apiLevels[i].LessThan(apiLevels[j])
+	})
+	sorted := make([]string, 0, len(apiLevels))
+	for i, apiLevel := range apiLevels {
+		if i > 0 && apiLevel.EqualTo(apiLevels[i-1]) {
+			continue
+		}
+		sorted = append(sorted, apiLevel.String())
+	}
+	return sorted
+}
+
 // includesFromHeaders gets the include directories needed from generated headers
 func (ca *compilerAttributes) includesFromHeaders(ctx android.BazelConversionPathContext, implHdrs, hdrs bazel.LabelListAttribute) {
-	local, absolute := includesFromLabelListAttribute(implHdrs, ca.localIncludes, ca.absoluteIncludes)
-	localExport, absoluteExport := includesFromLabelListAttribute(hdrs, ca.includes.Includes, ca.includes.AbsoluteIncludes)
+	local, absolute := includesFromLabelListAttribute(implHdrs, ctx.ModuleDir(), ca.localIncludes, ca.absoluteIncludes)
+	localExport, absoluteExport := includesFromLabelListAttribute(hdrs, ctx.ModuleDir(), ca.includes.Includes, ca.includes.AbsoluteIncludes)
+
+	// Headers from the same genrule can be listed both in the base value and in arch or os
+	// specific values, so drop the include directories which are already in the base value.
+	local.DeduplicateAxesFromBase()
+	absolute.DeduplicateAxesFromBase()
+	localExport.DeduplicateAxesFromBase()
+	absoluteExport.DeduplicateAxesFromBase()
 
 	ca.localIncludes = local
 	ca.absoluteIncludes = absolute
@@ -757,11 +1018,11 @@ func (ca *compilerAttributes) includesFromHeaders(ctx android.BazelConversionPat
 
 // includesFromLabelList extracts the packages from a LabelListAttribute that should be includes and
 // combines them with existing local/absolute includes.
-func includesFromLabelListAttribute(attr bazel.LabelListAttribute, existingLocal, existingAbsolute bazel.StringListAttribute) (bazel.StringListAttribute, bazel.StringListAttribute) {
+func includesFromLabelListAttribute(attr bazel.LabelListAttribute, moduleDir string, existingLocal, existingAbsolute bazel.StringListAttribute) (bazel.StringListAttribute, bazel.StringListAttribute) {
 	localAttr := existingLocal.Clone()
 	absoluteAttr := existingAbsolute.Clone()
 	if !attr.Value.IsEmpty() {
-		l, a := includesFromLabelList(attr.Value, existingLocal.Value, existingAbsolute.Value)
+		l, a := includesFromLabelList(attr.Value, moduleDir, existingLocal.Value, existingAbsolute.Value)
 		localAttr.SetSelectValue(bazel.NoConfigAxis, "", l)
 		absoluteAttr.SetSelectValue(bazel.NoConfigAxis, "", a)
 	}
@@ -769,7 +1030,7 @@ func includesFromLabelListAttribute(attr bazel.LabelListAttribute, existingLocal
 		for c, labels := range configToLabels {
 			local := existingLocal.SelectValue(axis, c)
 			absolute := existingAbsolute.SelectValue(axis, c)
-			l, a := includesFromLabelList(labels, local, absolute)
+			l, a := includesFromLabelList(labels, moduleDir, local, absolute)
 			localAttr.SetSelectValue(axis, c, l)
 			absoluteAttr.SetSelectValue(axis, c, a)
 		}
@@ -777,11 +1038,15 @@ func includesFromLabelListAttribute(attr bazel.LabelListAttribute, existingLocal
 	return *localAttr, *absoluteAttr
 }
 
-// includesFromLabelList extracts relative/absolute includes from a bazel.LabelList.
-func includesFromLabelList(labelList bazel.LabelList, existingRel, existingAbs []string) ([]string, []string) {
+// includesFromLabelList extracts relative/absolute includes from a bazel.LabelList. Fully
+// qualified labels in moduleDir's package are converted to relative includes, since an absolute
+// include of the module's own package is equivalent to ".".
+func includesFromLabelList(labelList bazel.LabelList, moduleDir string, existingRel, existingAbs []string) ([]string, []string) {
 	var relative, absolute []string
 	for _, hdr := range labelList.Includes {
-		if pkg, hasPkg := packageFromLabel(hdr.Label); hasPkg {
+		if pkg, hasPkg := packageFromLabel(hdr.Label); hasPkg && pkg == moduleDir {
+			relative = append(relative, ".")
+		} else if hasPkg {
 			absolute = append(absolute, pkg)
 		} else if pkg != "" {
 			relative = append(relative, pkg)
@@ -876,6 +1141,7 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 	linkerAttrs := linkerAttributes{}
 
 	var aidlLibs bazel.LabelList
+	var aidlIncludeDirs bazel.StringListAttribute
 	var implementationHdrs, exportHdrs bazel.LabelListAttribute
 
 	// Iterate through these axes in a deterministic order. This is required
@@ -900,6 +1166,7 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 				}
 				(&compilerAttrs).bp2buildForAxisAndConfig(ctx, axis, cfg, baseCompilerProps)
 				aidlLibs.Append(android.BazelLabelForModuleDeps(ctx, baseCompilerProps.Aidl.Libs))
+				aidlIncludeDirs.SetSelectValue(axis, cfg, baseCompilerProps.Aidl.Include_dirs)
 			}
 
 			var exportedHdrs []string
@@ -916,10 +1183,9 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 
 			if libraryProps, ok := archVariantLibraryProperties[axis][cfg].(*LibraryProperties); ok {
 				if axis == bazel.NoConfigAxis {
-					if libraryProps.Stubs.Symbol_file != nil {
+					if libraryProps.Stubs.Symbol_file != nil && bp2buildValidateStubsSymbolFile(ctx, *libraryProps.Stubs.Symbol_file) {
 						compilerAttrs.stubsSymbolFile = libraryProps.Stubs.Symbol_file
-						versions := android.CopyOf(libraryProps.Stubs.Versions)
-						normalizeVersions(ctx, versions)
+						versions := bp2buildStubsVersions(ctx, libraryProps.Stubs.Versions)
 						versions = addCurrentVersionIfNotPresent(versions)
 						compilerAttrs.stubsVersions.SetSelectValue(axis, cfg, versions)
 					}
@@ -935,6 +1201,8 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 		}
 	}
 
+	(&compilerAttrs).convertNativeBridgeProps(ctx, module)
+	(&compilerAttrs).convertUniqueHostSoname(ctx, module)
 	compilerAttrs.convertStlProps(ctx, module)
 	(&linkerAttrs).convertStripProps(ctx, module)
 
@@ -975,8 +1243,9 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 		ctx, module,
 		compilerAttrs.aidlSrcs,
 		bazel.LabelListAttribute{
-			Value: aidlLibs,
+			Value: bazel.FirstUniqueBazelLabelList(aidlLibs),
 		},
+		aidlIncludeDirs,
 		linkerAttrs,
 		compilerAttrs,
 	)
@@ -1001,12 +1270,9 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 	(&compilerAttrs).cSrcs.Add(&convertedLSrcs.cSrcName)
 
 	if module.afdo != nil && module.afdo.Properties.Afdo {
-		fdoProfileDep := bp2buildFdoProfile(ctx, module)
-		if fdoProfileDep != nil {
-			// TODO(b/276287371): Only set fdo_profile for android platform
-			// https://cs.android.com/android/platform/superproject/main/+/main:build/soong/cc/afdo.go;l=105;drc=2dbe160d1af445de32725098570ec594e3944fc5
-			(&compilerAttrs).fdoProfile.SetValue(*fdoProfileDep)
-		}
+		// TODO(b/276287371): Only set fdo_profile for android platform
+		// https://cs.android.com/android/platform/superproject/main/+/main:build/soong/cc/afdo.go;l=105;drc=2dbe160d1af445de32725098570ec594e3944fc5
+		compilerAttrs.fdoProfile = bp2buildFdoProfile(ctx, module)
 	}
 
 	if !compilerAttrs.syspropSrcs.IsEmpty() {
@@ -1030,10 +1296,12 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 	features := compilerAttrs.features.Clone().Append(linkerAttrs.features).Append(sanitizerValues.features)
 	features = features.Append(bp2buildLtoFeatures(ctx, module))
 	features = features.Append(convertHiddenVisibilityToFeatureBase(ctx, module))
+	resolveReenabledFeatures(features, linkerAttrs.reenabledFeatures)
 	features.DeduplicateAxesFromBase()
 
 	compilerAttrs.copts = *compilerAttrs.copts.Append(sanitizerValues.copts)
 	compilerAttrs.additionalCompilerInputs = *compilerAttrs.additionalCompilerInputs.Append(sanitizerValues.additionalCompilerInputs)
+	linkerAttrs.sanitizerRuntimeDynamicDeps = sanitizerValues.runtimeDynamicDeps
 
 	addMuslSystemDynamicDeps(ctx, linkerAttrs)
 
@@ -1051,6 +1319,7 @@ func bp2BuildParseBaseProps(ctx android.Bp2buildMutatorContext, module *Module)
 		protoDep.protoDep,
 		aidlDep,
 		nativeCoverage,
+		bazel.MakeLabelListAttribute(android.BazelLabelForModuleSrc(ctx, module.Properties.Logtags)),
 	}
 }
 
@@ -1110,20 +1379,37 @@ type fdoProfileAttributes struct {
 	Absolute_path_profile string
 }
 
+// bp2buildFdoProfile returns the label of the fdo_profile target of the module's
+// afdo profile. A profile specific to an architecture, e.g. <module>-arm64.afdo,
+// takes precedence over the profile of all architectures, <module>.afdo.
 func bp2buildFdoProfile(
 	ctx android.Bp2buildMutatorContext,
 	m *Module,
-) *bazel.Label {
+) bazel.LabelAttribute {
+	var fdoProfile bazel.LabelAttribute
+	if label := bp2buildFdoProfileLabel(ctx, m.Name()); label != nil {
+		fdoProfile.SetValue(*label)
+	}
+	for _, arch := range bazel.ArchConfigurationAxis.PlatformConfigs() {
+		if label := bp2buildFdoProfileLabel(ctx, m.Name()+"-"+arch); label != nil {
+			fdoProfile.SetSelectValue(bazel.ArchConfigurationAxis, arch, *label)
+		}
+	}
+	return fdoProfile
+}
+
+// bp2buildFdoProfileLabel returns the label of the fdo_profile target of the given
+// profile, if the profile exists in one of the afdo profile projects.
+func bp2buildFdoProfileLabel(ctx android.Bp2buildMutatorContext, profile string) *bazel.Label {
 	// TODO(b/267229066): Convert to afdo boolean attribute and let Bazel handles finding
 	// fdo_profile target from AfdoProfiles product var
 	for _, project := range globalAfdoProfileProjects {
 		// Ensure it's a Soong package
 		bpPath := android.ExistentPathForSource(ctx, project, "Android.bp")
 		if bpPath.Valid() {
-			// TODO(b/260714900): Handle arch-specific afdo profiles (e.g. `<module-name>-arm<64>.afdo`)
-			path := android.ExistentPathForSource(ctx, project, m.Name()+".afdo")
+			path := android.ExistentPathForSource(ctx, project, profile+".afdo")
 			if path.Valid() {
-				fdoProfileLabel := "//" + strings.TrimSuffix(project, "/") + ":" + m.Name()
+				fdoProfileLabel := "//" + strings.TrimSuffix(project, "/") + ":" + profile
 				return &bazel.Label{
 					Label: fdoProfileLabel,
 				}
@@ -1139,6 +1425,7 @@ func bp2buildCcAidlLibrary(
 	m *Module,
 	aidlSrcs bazel.LabelListAttribute,
 	aidlLibs bazel.LabelListAttribute,
+	aidlIncludeDirs bazel.StringListAttribute,
 	linkerAttrs linkerAttributes,
 	compilerAttrs compilerAttributes,
 ) *bazel.LabelAttribute {
@@ -1156,6 +1443,24 @@ func bp2buildCcAidlLibrary(
 
 		if !aidlFiles.IsEmpty() {
 			aidlLibName := m.Name() + "_aidl_library"
+			// The default name may already be taken by a module of the tree (e.g. an aidl_library
+			// listed in aidl.libs), which would shadow the aidl srcs of this module.
+			if _, exists := ctx.ModuleFromName(aidlLibName); exists {
+				aidlLibName = m.Name() + "_srcs_aidl_library"
+			}
+			// The .aidl files of aidl.include_dirs are imported by the srcs, so they are deps of
+			// the aidl_library of the srcs, and thus transitive deps of the cc_aidl_library.
+			var aidlIncludeDirLibs bazel.LabelListAttribute
+			if len(aidlIncludeDirs.Value) > 0 {
+				aidlIncludeDirLibs.SetValue(android.CreateAidlLibraryTargetsForIncludeDirs(ctx, aidlIncludeDirs.Value))
+			}
+			for _, axis := range aidlIncludeDirs.SortedConfigurationAxes() {
+				for _, config := range android.SortedKeys(aidlIncludeDirs.ConfigurableValues[axis]) {
+					if dirs := aidlIncludeDirs.SelectValue(axis, config); len(dirs) > 0 {
+						aidlIncludeDirLibs.SetSelectValue(axis, config, android.CreateAidlLibraryTargetsForIncludeDirs(ctx, dirs))
+					}
+				}
+			}
 			ctx.CreateBazelTargetModule(
 				bazel.BazelTargetModuleProperties{
 					Rule_class:        "aidl_library",
@@ -1167,6 +1472,7 @@ func bp2buildCcAidlLibrary(
 				},
 				&aidlLibraryAttributes{
 					Srcs: aidlFiles,
+					Deps: aidlIncludeDirLibs,
 				},
 			)
 			aidlLibsFromSrcs.Add(&bazel.LabelAttribute{Value: &bazel.Label{Label: ":" + aidlLibName}})
@@ -1182,6 +1488,8 @@ func bp2buildCcAidlLibrary(
 		// deps so that they don't re-export
 		implementationDeps := linkerAttrs.deps.Clone()
 		implementationDeps.Append(linkerAttrs.implementationDeps)
+		implementationDeps.Append(linkerAttrs.wholeArchiveDeps)
+		implementationDeps.Append(linkerAttrs.implementationWholeArchiveDeps)
 		implementationDynamicDeps := linkerAttrs.dynamicDeps.Clone()
 		implementationDynamicDeps.Append(linkerAttrs.implementationDynamicDeps)
 
@@ -1256,6 +1564,37 @@ type linkerAttributes struct {
 	stripAll                      bazel.BoolAttribute
 	stripNone                     bazel.BoolAttribute
 	features                      bazel.StringListAttribute
+
+	// linker flags of a static library passed when linking its dependents, see wrapLdflags
+	staticLinkopts bazel.StringListAttribute
+
+	// features disabled in the base value which are explicitly enabled again for a configuration,
+	// e.g. "-link_crt" for an arch variant setting `nocrt: false`.
+	reenabledFeatures bazel.StringListAttribute
+
+	// sanitizer runtime libraries needed when linking a shared library or a dynamically linked
+	// binary, e.g. libclang_rt.ubsan_standalone for sanitize.all_undefined
+	sanitizerRuntimeDynamicDeps bazel.LabelListAttribute
+
+	// ldflags that were dropped because the toolchain already sets them
+	removedToolchainLdflags map[string]bool
+
+	// whether the version script is selected on the image axis, see convertImageVersionScripts
+	imageVersionScripts bool
+}
+
+// wrapLdflags returns the linker flags wrapping symbols, e.g. -Wl,--wrap=malloc. The references
+// to the wrapped symbols of a static library are resolved when linking its dependents, which
+// need the flags too. Soong ignores the ldflags of static libraries, so they are only passed
+// when opted in with BP2BUILD_STATIC_LIBRARY_WRAP_LDFLAGS.
+func wrapLdflags(ldflags []string) []string {
+	var wrapFlags []string
+	for _, flag := range ldflags {
+		if strings.HasPrefix(flag, "-Wl,--wrap=") || strings.HasPrefix(flag, "-Wl,--wrap,") {
+			wrapFlags = append(wrapFlags, flag)
+		}
+	}
+	return wrapFlags
 }
 
 var (
@@ -1263,6 +1602,56 @@ var (
 	versionLib            = "libbuildversion"
 )
 
+// toolchainOwnedLdflags are linker flags the cc toolchains may set (see DeviceGlobalLldflags,
+// LinuxBionicLdflags and the per-arch ldflags in cc/config). Passing them again in linkopts only
+// duplicates them on the link command line, so exact copies are removed from converted modules
+// when every toolchain the configuration may select sets them. Flags that set the same option to
+// a different value override the toolchain and are kept.
+var toolchainOwnedLdflags = []string{
+	"-Wl,--hash-style=gnu",
+	"-Wl,--build-id=md5",
+	"-fuse-ld=lld",
+	"-Wl,-z,noexecstack",
+	"-Wl,-z,relro",
+	"-Wl,-z,now",
+	"-Wl,--fatal-warnings",
+	"-Wl,--no-undefined-version",
+	"-Wl,--no-demangle",
+}
+
+// toolchainLdflagsForConfig returns the ldflags set by every toolchain the given configuration
+// may select.
+func toolchainLdflagsForConfig(axis bazel.ConfigurationAxis, axisConfig string) []string {
+	return config.Bp2buildCommonToolchainLdflags(func(os android.OsType, arch android.ArchType) bool {
+		switch axis {
+		case bazel.ArchConfigurationAxis:
+			return arch.Name == axisConfig
+		case bazel.OsConfigurationAxis:
+			return os.Name == axisConfig
+		case bazel.OsArchConfigurationAxis:
+			return os.Name+"_"+arch.Name == axisConfig
+		}
+		return true
+	})
+}
+
+// toolchainOwnedLdflagFilter returns a filterOutFn for the ldflags of the given configuration,
+// which removes the toolchain owned flags set by all the toolchains it may select and records
+// them, so that they can be reported once all configurations of the module have been converted.
+func (la *linkerAttributes) toolchainOwnedLdflagFilter(axis bazel.ConfigurationAxis, axisConfig string) func(string) bool {
+	toolchainLdflags := toolchainLdflagsForConfig(axis, axisConfig)
+	return func(flag string) bool {
+		if !android.InList(flag, toolchainOwnedLdflags) || !android.InList(flag, toolchainLdflags) {
+			return false
+		}
+		if la.removedToolchainLdflags == nil {
+			la.removedToolchainLdflags = map[string]bool{}
+		}
+		la.removedToolchainLdflags[flag] = true
+		return true
+	}
+}
+
 // resolveTargetApex re-adds the shared and static libs in target.apex.exclude_shared|static_libs props to non-apex variant
 // since all libs are already excluded by default
 func (la *linkerAttributes) resolveTargetApexProp(ctx android.Bp2buildMutatorContext, props *BaseLinkerProperties) {
@@ -1283,6 +1672,75 @@ func (la *linkerAttributes) resolveTargetApexProp(ctx android.Bp2buildMutatorCon
 	la.implementationDeps.Append(staticExcludesLabelList)
 }
 
+// resolveTargetImageProps converts the shared, static and header libs specific to the
+// vendor, product and recovery variants of the module, and their excludes, to selects
+// on the image axis.
+func (la *linkerAttributes) resolveTargetImageProps(ctx android.Bp2buildMutatorContext, props *BaseLinkerProperties) {
+	setImageDeps := func(image string, sharedLibs, staticLibs, headerLibs, excludeSharedLibs, excludeStaticLibs, excludeHeaderLibs []string) {
+		excludeShared := bazelLabelForSharedDeps(ctx, excludeSharedLibs).Includes
+		excludeStatic := bazelLabelForStaticDeps(ctx, excludeStaticLibs).Includes
+		excludeHeaders := bazelLabelForHeaderDeps(ctx, excludeHeaderLibs).Includes
+		excludeDeps := append(append([]bazel.Label{}, excludeStatic...), excludeHeaders...)
+
+		setImageValue := func(lla *bazel.LabelListAttribute, includes bazel.LabelList, excludes []bazel.Label) {
+			if len(includes.Includes) == 0 && len(excludes) == 0 {
+				return
+			}
+			includes.Excludes = excludes
+			imageList := bazel.LabelListAttribute{}
+			imageList.SetSelectValue(bazel.ImageAxis, image, includes)
+			lla.Append(imageList)
+		}
+
+		implementationDeps := bazelLabelForStaticDeps(ctx, staticLibs)
+		implementationDeps.Append(bazelLabelForHeaderDeps(ctx, headerLibs))
+		setImageValue(&la.implementationDeps, implementationDeps, excludeDeps)
+		setImageValue(&la.deps, bazel.LabelList{}, excludeDeps)
+		setImageValue(&la.wholeArchiveDeps, bazel.LabelList{}, bazelLabelForWholeDeps(ctx, excludeStaticLibs).Includes)
+		setImageValue(&la.implementationDynamicDeps, bazelLabelForSharedDeps(ctx, sharedLibs), excludeShared)
+		setImageValue(&la.dynamicDeps, bazel.LabelList{}, excludeShared)
+	}
+	vendor, product := props.Target.Vendor, props.Target.Product
+	setImageDeps(bazel.ImageVendor, vendor.Shared_libs, vendor.Static_libs, vendor.Header_libs,
+		vendor.Exclude_shared_libs, vendor.Exclude_static_libs, vendor.Exclude_header_libs)
+	setImageDeps(bazel.ImageProduct, product.Shared_libs, product.Static_libs, product.Header_libs,
+		product.Exclude_shared_libs, product.Exclude_static_libs, product.Exclude_header_libs)
+	// The recovery variant can't have additional header libs.
+	recovery := props.Target.Recovery
+	setImageDeps(bazel.ImageRecovery, recovery.Shared_libs, recovery.Static_libs, nil,
+		recovery.Exclude_shared_libs, recovery.Exclude_static_libs, recovery.Exclude_header_libs)
+}
+
+// versionScriptFlag returns the linker flag passing the given version script.
+func versionScriptFlag(label bazel.Label) string {
+	return fmt.Sprintf("-Wl,--version-script,$(location %s)", label.Label)
+}
+
+// convertImageVersionScripts converts the version scripts of the vendor and product variants to
+// selects on the image axis. Unlike the other linker properties of these variants, they replace
+// the version script of the core variant rather than add to it, so the core version script is
+// moved to the default condition of the selects.
+func (la *linkerAttributes) convertImageVersionScripts(ctx android.Bp2buildMutatorContext, props *BaseLinkerProperties) {
+	vendor, product := props.Target.Vendor.Version_script, props.Target.Product.Version_script
+	if vendor == nil && product == nil {
+		return
+	}
+	la.imageVersionScripts = true
+	setVersionScript := func(config string, versionScript *string) {
+		if versionScript == nil {
+			return
+		}
+		label := android.BazelLabelForModuleSrcSingle(ctx, *versionScript)
+		la.additionalLinkerInputs.SetSelectValue(bazel.ImageAxis, config, bazel.MakeLabelList([]bazel.Label{label}))
+		la.linkopts.SetSelectValue(bazel.ImageAxis, config, []string{versionScriptFlag(label)})
+		la.features.SetSelectValue(bazel.ImageAxis, config, []string{"android_cfi_exports_map"})
+	}
+	// The recovery variant uses the version script of the core variant.
+	setVersionScript(bazel.ConditionsDefaultConfigKey, props.Version_script)
+	setVersionScript(bazel.ImageVendor, vendor)
+	setVersionScript(bazel.ImageProduct, product)
+}
+
 func (la *linkerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutatorContext, module *Module, axis bazel.ConfigurationAxis, config string, props *BaseLinkerProperties) {
 	isBinary := module.Binary()
 	// Use a single variable to capture usage of nocrt in arch variants, so there's only 1 error message for this module
@@ -1329,7 +1787,14 @@ func (la *linkerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutator
 	)
 
 	headerLibs := android.FirstUniqueStrings(props.Header_libs)
-	hDeps := maybePartitionExportedAndImplementationsDeps(ctx, !isBinary, headerLibs, props.Export_header_lib_headers, bazelLabelForHeaderDeps)
+	hDeps := maybePartitionExportedAndImplementationsDepsExcludes(
+		ctx,
+		!isBinary,
+		headerLibs,
+		props.Exclude_header_libs,
+		props.Export_header_lib_headers,
+		bazelLabelForHeaderDepsExcludes,
+	)
 
 	(&hDeps.export).Append(staticDeps.export)
 	la.deps.SetSelectValue(axis, config, hDeps.export)
@@ -1370,6 +1835,9 @@ func (la *linkerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutator
 	la.dynamicDeps.SetSelectValue(axis, config, sharedDeps.export)
 	la.implementationDynamicDeps.SetSelectValue(axis, config, sharedDeps.implementation)
 	la.resolveTargetApexProp(ctx, props)
+	if axis == bazel.NoConfigAxis {
+		la.resolveTargetImageProps(ctx, props)
+	}
 
 	if axis == bazel.NoConfigAxis || (axis == bazel.OsConfigurationAxis && config == bazel.OsAndroid) {
 		// If a dependency in la.implementationDynamicDeps or la.dynamicDeps has stubs, its
@@ -1395,6 +1863,14 @@ func (la *linkerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutator
 
 	var linkerFlags []string
 	if len(props.Ldflags) > 0 {
+		for _, flag := range props.Ldflags {
+			if strings.Contains(flag, "$(location") {
+				// Soong doesn't expand $(location) in ldflags, so the flag can't be meaningfully converted.
+				ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED,
+					"$(location) reference in ldflags")
+				break
+			}
+		}
 		linkerFlags = append(linkerFlags, proptools.NinjaEscapeList(props.Ldflags)...)
 		// binaries remove static flag if -shared is in the linker flags
 		if isBinary && android.InList("-shared", linkerFlags) {
@@ -1402,22 +1878,40 @@ func (la *linkerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutator
 		}
 	}
 
+	var reenabledFeatures []string
 	if !props.libCrt() {
 		axisFeatures = append(axisFeatures, "-use_libcrt")
+	} else if props.No_libcrt != nil && axis != bazel.NoConfigAxis {
+		reenabledFeatures = append(reenabledFeatures, "-use_libcrt")
 	}
 	if !props.crt() {
 		axisFeatures = append(axisFeatures, "-link_crt")
+	} else if props.Nocrt != nil && axis != bazel.NoConfigAxis {
+		reenabledFeatures = append(reenabledFeatures, "-link_crt")
+	}
+	if reenabledFeatures != nil {
+		la.reenabledFeatures.SetSelectValue(axis, config, reenabledFeatures)
 	}
 
 	// This must happen before the addition of flags for Version Script and
 	// Dynamic List, as these flags must be split on spaces and those must not
-	linkerFlags = parseCommandLineFlags(linkerFlags, filterOutClangUnknownCflags)
+	linkerFlags = parseCommandLineFlags(linkerFlags, filterOutClangUnknownCflags, la.toolchainOwnedLdflagFilter(axis, config))
+
+	if axis == bazel.NoConfigAxis {
+		la.convertImageVersionScripts(ctx, props)
+	} else if props.Target.Vendor.Version_script != nil || props.Target.Product.Version_script != nil ||
+		(props.Version_script != nil && la.imageVersionScripts) {
+		// The version script of a configuration would have to be replaced by the one of an
+		// image, which can't be expressed with selects on independent axes.
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED,
+			"version_script for a specific configuration with target.vendor.version_script or target.product.version_script")
+	}
 
 	additionalLinkerInputs := bazel.LabelList{}
-	if props.Version_script != nil {
+	if props.Version_script != nil && !(axis == bazel.NoConfigAxis && la.imageVersionScripts) {
 		label := android.BazelLabelForModuleSrcSingle(ctx, *props.Version_script)
 		additionalLinkerInputs.Add(&label)
-		linkerFlags = append(linkerFlags, fmt.Sprintf("-Wl,--version-script,$(location %s)", label.Label))
+		linkerFlags = append(linkerFlags, versionScriptFlag(label))
 		axisFeatures = append(axisFeatures, "android_cfi_exports_map")
 	}
 
@@ -1427,11 +1921,22 @@ func (la *linkerAttributes) bp2buildForAxisAndConfig(ctx android.Bp2buildMutator
 		linkerFlags = append(linkerFlags, fmt.Sprintf("-Wl,--dynamic-list,$(location %s)", label.Label))
 	}
 
+	for _, linkerScript := range props.Linker_scripts {
+		label := android.BazelLabelForModuleSrcSingle(ctx, linkerScript)
+		additionalLinkerInputs.Add(&label)
+		linkerFlags = append(linkerFlags, fmt.Sprintf("-Wl,--script,$(location %s)", label.Label))
+	}
+
 	la.additionalLinkerInputs.SetSelectValue(axis, config, additionalLinkerInputs)
 	if axis == bazel.OsConfigurationAxis && (config == bazel.OsDarwin || config == bazel.OsLinux || config == bazel.OsWindows) {
 		linkerFlags = append(linkerFlags, props.Host_ldlibs...)
 	}
 	la.linkopts.SetSelectValue(axis, config, linkerFlags)
+	if ctx.Config().Bp2buildStaticLibraryWrapLdflags() {
+		if wrapFlags := wrapLdflags(linkerFlags); len(wrapFlags) > 0 {
+			la.staticLinkopts.SetSelectValue(axis, config, wrapFlags)
+		}
+	}
 
 	if axisFeatures != nil {
 		la.features.SetSelectValue(axis, config, axisFeatures)
@@ -1455,6 +1960,70 @@ func availableToSameApexes(a, b []string) bool {
 	return !differ
 }
 
+// ApexRuntimeLibs returns the libraries the given cc modules transitively depend on
+// through runtime_libs when built for Android on the given arch, in the order they
+// are found, excluding the given modules. Soong installs these libraries in an apex
+// alongside the modules, unless they have stubs, in which case they are provided by
+// the apex or platform owning them.
+func ApexRuntimeLibs(ctx android.Bp2buildMutatorContext, modules []string, arch string) []string {
+	var runtimeLibs []string
+	seen := make(map[string]bool, len(modules))
+	for _, module := range modules {
+		seen[module] = true
+	}
+	for queue := modules; len(queue) > 0; queue = queue[1:] {
+		m, exists := ctx.ModuleFromName(queue[0])
+		if !exists {
+			continue
+		}
+		c, ok := m.(*Module)
+		if !ok {
+			continue
+		}
+		for _, lib := range androidArchRuntimeLibs(ctx, c, arch) {
+			if seen[lib] {
+				continue
+			}
+			seen[lib] = true
+			if dep, exists := ctx.ModuleFromName(lib); exists {
+				if depModule, ok := dep.(*Module); ok && depModule.HasStubsVariants() {
+					continue
+				}
+			}
+			runtimeLibs = append(runtimeLibs, lib)
+			queue = append(queue, lib)
+		}
+	}
+	return runtimeLibs
+}
+
+// androidArchRuntimeLibs returns the runtime_libs of the module when built for Android
+// on the given arch, merging the top level, arch, multilib and target properties.
+func androidArchRuntimeLibs(ctx android.Bp2buildMutatorContext, c *Module, arch string) []string {
+	var libs, excludes []string
+	axisToProps := c.GetArchVariantProperties(ctx, &BaseLinkerProperties{})
+	for _, axis := range bazel.SortedConfigurationAxes(axisToProps) {
+		var config string
+		switch axis {
+		case bazel.NoConfigAxis:
+			config = ""
+		case bazel.ArchConfigurationAxis:
+			config = arch
+		case bazel.OsConfigurationAxis:
+			config = bazel.OsAndroid
+		case bazel.OsArchConfigurationAxis:
+			config = bazel.OsAndroid + "_" + arch
+		default:
+			continue
+		}
+		if props, ok := axisToProps[axis][config].(*BaseLinkerProperties); ok {
+			libs = append(libs, props.Runtime_libs...)
+			excludes = append(excludes, props.Exclude_runtime_libs...)
+		}
+	}
+	return android.RemoveListFromList(android.FirstUniqueStrings(libs), excludes)
+}
+
 var (
 	apiDomainConfigSettingKey  = android.NewOnceKey("apiDomainConfigSettingKey")
 	apiDomainConfigSettingLock sync.Mutex
@@ -1556,6 +2125,11 @@ type stubSelectionInfo struct {
 	// Boolean value for determining if the dep is in the same api domain
 	// If false, the label will be rewritten to to the stub label
 	sameApiDomain bool
+	// If true, //conditions:default holds the selection for apexes not listed in apex_available,
+	// and the implementation is only selected by default for hostConfigs.
+	defaultIsAnyApex bool
+	// Host OS keys of the os_in_apex axis the module is built for
+	hostConfigs []string
 }
 
 func useStubOrImplInApexWithName(ssi stubSelectionInfo) {
@@ -1565,19 +2139,31 @@ func useStubOrImplInApexWithName(ssi stubSelectionInfo) {
 			Label: apiSurfaceModuleLibCurrentPackage + strings.TrimPrefix(lib.OriginalModuleName, ":"),
 		}
 	}
-	// Create a select statement specific to this apex
-	inApexSelectValue := ssi.dynamicDeps.SelectValue(bazel.OsAndInApexAxis, inApexConfigSetting(ssi.apiDomain))
+	// Create a select statement specific to this apex. Apexes that are not listed in
+	// apex_available use the default condition, as an android-in_apex key would match together
+	// with the key of any listed apex.
+	configKey := inApexConfigSetting(ssi.apiDomain)
+	if ssi.apiDomain == android.AvailableToAnyApex {
+		configKey = bazel.ConditionsDefaultConfigKey
+	}
+	inApexSelectValue := ssi.dynamicDeps.SelectValue(bazel.OsAndInApexAxis, configKey)
 	(&inApexSelectValue).Append(bazel.MakeLabelList([]bazel.Label{lib}))
-	ssi.dynamicDeps.SetSelectValue(bazel.OsAndInApexAxis, inApexConfigSetting(ssi.apiDomain), bazel.FirstUniqueBazelLabelList(inApexSelectValue))
+	ssi.dynamicDeps.SetSelectValue(bazel.OsAndInApexAxis, configKey, bazel.FirstUniqueBazelLabelList(inApexSelectValue))
 	// Delete the library from the common config for this apex
 	implDynamicDeps := ssi.dynamicDeps.SelectValue(ssi.axis, ssi.config)
 	implDynamicDeps = bazel.SubtractBazelLabelList(implDynamicDeps, bazel.MakeLabelList([]bazel.Label{ssi.impl}))
 	ssi.dynamicDeps.SetSelectValue(ssi.axis, ssi.config, implDynamicDeps)
 	if ssi.axis == bazel.NoConfigAxis {
 		// Set defaults. Defaults (i.e. host) should use impl and not stubs.
-		defaultSelectValue := ssi.dynamicDeps.SelectValue(bazel.OsAndInApexAxis, bazel.ConditionsDefaultConfigKey)
-		(&defaultSelectValue).Append(bazel.MakeLabelList([]bazel.Label{ssi.impl}))
-		ssi.dynamicDeps.SetSelectValue(bazel.OsAndInApexAxis, bazel.ConditionsDefaultConfigKey, bazel.FirstUniqueBazelLabelList(defaultSelectValue))
+		implConfigs := []string{bazel.ConditionsDefaultConfigKey}
+		if ssi.defaultIsAnyApex {
+			implConfigs = ssi.hostConfigs
+		}
+		for _, implConfig := range implConfigs {
+			implSelectValue := ssi.dynamicDeps.SelectValue(bazel.OsAndInApexAxis, implConfig)
+			(&implSelectValue).Append(bazel.MakeLabelList([]bazel.Label{ssi.impl}))
+			ssi.dynamicDeps.SetSelectValue(bazel.OsAndInApexAxis, implConfig, bazel.FirstUniqueBazelLabelList(implSelectValue))
+		}
 	}
 }
 
@@ -1606,7 +2192,17 @@ func SetStubsForDynamicDeps(ctx android.Bp2buildMutatorContext, axis bazel.Confi
 	if !android.InList(android.AvailableToPlatform, apiDomainForSelects) {
 		apiDomainForSelects = append(apiDomainForSelects, android.AvailableToPlatform)
 	}
+	// Optionally select the dependencies for an arbitrary apex in the default condition as well, so
+	// that the library can be built for apexes that are not listed in apex_available.
+	stubsForAnyApex := ctx.Config().Bp2buildStubsForAnyApex()
+	if stubsForAnyApex && !android.InList(android.AvailableToAnyApex, apiDomainForSelects) {
+		apiDomainForSelects = append(apiDomainForSelects, android.AvailableToAnyApex)
+	}
 	apiDomainForSelects = android.SortedUniqueStrings(apiDomainForSelects)
+	var hostConfigs []string
+	if stubsForAnyApex && ctx.Module().HostSupported() {
+		hostConfigs = bazel.HostOses
+	}
 
 	// Create a select for each apex this library could be included in.
 	for _, l := range dynamicLibs.Includes {
@@ -1637,12 +2233,14 @@ func SetStubsForDynamicDeps(ctx android.Bp2buildMutatorContext, axis bazel.Confi
 				sameApiDomain = android.InList(apiDomain, dep.(*Module).ApexAvailable())
 			}
 			ssi := stubSelectionInfo{
-				impl:          l,
-				axis:          axis,
-				config:        config,
-				apiDomain:     apiDomain,
-				dynamicDeps:   dynamicDeps,
-				sameApiDomain: sameApiDomain,
+				impl:             l,
+				axis:             axis,
+				config:           config,
+				apiDomain:        apiDomain,
+				dynamicDeps:      dynamicDeps,
+				sameApiDomain:    sameApiDomain,
+				defaultIsAnyApex: stubsForAnyApex,
+				hostConfigs:      hostConfigs,
 			}
 			useStubOrImplInApexWithName(ssi)
 		}
@@ -1684,7 +2282,7 @@ var (
 	}
 )
 
-func (la *linkerAttributes) convertStripProps(ctx android.BazelConversionPathContext, module *Module) {
+func (la *linkerAttributes) convertStripProps(ctx android.Bp2buildMutatorContext, module *Module) {
 	bp2BuildPropParseHelper(ctx, module, &StripProperties{}, func(axis bazel.ConfigurationAxis, config string, props interface{}) {
 		if stripProperties, ok := props.(*StripProperties); ok {
 			la.stripKeepSymbols.SetSelectValue(axis, config, stripProperties.Strip.Keep_symbols)
@@ -1696,6 +2294,34 @@ func (la *linkerAttributes) convertStripProps(ctx android.BazelConversionPathCon
 	})
 }
 
+// resolveReenabledFeatures moves each feature of the base value that is explicitly re-enabled
+// for some configs of an axis into the remaining configs of that axis, so the base value doesn't
+// disable it everywhere, e.g. for `nocrt: true` with an arch variant setting `nocrt: false`.
+func resolveReenabledFeatures(features *bazel.StringListAttribute, reenabled bazel.StringListAttribute) {
+	for _, axis := range reenabled.SortedConfigurationAxes() {
+		configToFeatures := reenabled.ConfigurableValues[axis]
+		configs := axis.PlatformConfigs()
+		if configs == nil {
+			continue
+		}
+		for _, config := range android.SortedKeys(configToFeatures) {
+			for _, feature := range configToFeatures[config] {
+				if !android.InList(feature, features.Value) {
+					continue
+				}
+				_, features.Value = android.RemoveFromList(feature, features.Value)
+				for _, c := range configs {
+					if android.InList(feature, configToFeatures[c]) {
+						continue
+					}
+					value := append([]string{}, features.SelectValue(axis, c)...)
+					features.SetSelectValue(axis, c, append(value, feature))
+				}
+			}
+		}
+	}
+}
+
 func (la *linkerAttributes) convertProductVariables(ctx android.Bp2buildMutatorContext, productVariableProps android.ProductConfigProperties) {
 
 	type productVarDep struct {
@@ -1717,7 +2343,7 @@ func (la *linkerAttributes) convertProductVariables(ctx android.Bp2buildMutatorC
 		"Shared_libs":       {attribute: &la.implementationDynamicDeps, depResolutionFunc: bazelLabelForSharedDepsExcludes},
 		"Static_libs":       {"Exclude_static_libs", &la.implementationDeps, bazelLabelForStaticDepsExcludes},
 		"Whole_static_libs": {"Exclude_static_libs", &la.wholeArchiveDeps, bazelLabelForWholeDepsExcludes},
-		"Header_libs":       {attribute: &headerDeps, depResolutionFunc: bazelLabelForHeaderDepsExcludes},
+		"Header_libs":       {"Exclude_header_libs", &headerDeps, bazelLabelForHeaderDepsExcludes},
 	}
 
 	for name, dep := range productVarToDepFields {
@@ -1805,8 +2431,15 @@ func (la *linkerAttributes) finalize(ctx android.Bp2buildMutatorContext) {
 	la.dynamicDeps.ResolveExcludes()
 	la.implementationDynamicDeps.ResolveExcludes()
 	la.wholeArchiveDeps.ResolveExcludes()
+	la.runtimeDeps.ResolveExcludes()
 	la.systemDynamicDeps.ForceSpecifyEmptyList = true
 
+	// Configurations are visited in an arbitrary order, sort the flags to keep the report stable.
+	if len(la.removedToolchainLdflags) > 0 {
+		ctx.AddBp2buildWarning("removed ldflags set by the toolchain: %s",
+			strings.Join(android.SortedKeys(la.removedToolchainLdflags), " "))
+	}
+
 }
 
 // Relativize a list of root-relative paths with respect to the module's
@@ -1856,6 +2489,14 @@ func bp2BuildParseExportedIncludes(ctx android.BazelConversionPathContext, modul
 
 	bp2BuildPropParseHelper(ctx, module, &FlagExporterProperties{}, func(axis bazel.ConfigurationAxis, config string, props interface{}) {
 		if flagExporterProperties, ok := props.(*FlagExporterProperties); ok {
+			for _, dir := range flagExporterProperties.Export_include_dirs {
+				// Soong rejects include dirs in generated directories, e.g. $(genDir)/include,
+				// they are exported by the generating genrule instead.
+				if strings.Contains(dir, "$") {
+					ctx.PropertyErrorf("export_include_dirs", "%q: generated include directories must be exported by the generating genrule, "+
+						"use its export_include_dirs together with export_generated_headers instead", dir)
+				}
+			}
 			if len(flagExporterProperties.Export_include_dirs) > 0 {
 				exported.Includes.SetSelectValue(axis, config, android.FirstUniqueStrings(append(exported.Includes.SelectValue(axis, config), flagExporterProperties.Export_include_dirs...)))
 			}
@@ -1890,11 +2531,16 @@ func bazelLabelForSharedModule(ctx android.BazelConversionPathContext, m bluepri
 }
 
 func bazelLabelForStaticWholeModuleDeps(ctx android.BazelConversionPathContext, m blueprint.Module) string {
+	// A filegroup converted to a proto_library provides no CcInfo, its sources are
+	// compiled into the cc proto library of the module listing it in srcs.
+	if fg, ok := m.(android.FileGroupAsLibrary); ok && fg.ShouldConvertToProtoLibrary(ctx) {
+		ctx.PropertyErrorf("whole_static_libs", "%q is a filegroup of .proto files, which is not a library; list it in srcs instead",
+			ctx.OtherModuleName(m))
+	}
 	label := bazelLabelForStaticModule(ctx, m)
-	if aModule, ok := m.(android.Module); ok {
-		if android.IsModulePrebuilt(aModule) {
-			label += "_alwayslink"
-		}
+	// Only cc prebuilts generate an additional _alwayslink target.
+	if ccModule, ok := m.(*Module); ok && android.IsModulePrebuilt(ccModule) {
+		label += "_alwayslink"
 	}
 	return label
 }
@@ -1930,8 +2576,7 @@ func bazelLabelForHeaderDeps(ctx android.Bp2buildMutatorContext, modules []strin
 }
 
 func bazelLabelForHeaderDepsExcludes(ctx android.Bp2buildMutatorContext, modules, excludes []string) bazel.LabelList {
-	// This is only used when product_variable header_libs is processed, to follow
-	// the pattern of depResolutionFunc
+	// Header libs are labeled like shared libs, see bazelLabelForHeaderDeps.
 	return android.BazelLabelForModuleDepsExcludesWithFn(ctx, modules, excludes, bazelLabelForSharedModule)
 }
 
@@ -1945,6 +2590,16 @@ type binaryLinkerAttrs struct {
 	Suffix     bazel.StringAttribute
 }
 
+// staticExecutableSupported returns whether static executables are supported for
+// the given config of the OS or OS and arch axes. Like Soong, which ignores
+// static_executable on Darwin and Windows, it returns false for those.
+func staticExecutableSupported(axis bazel.ConfigurationAxis, config string) bool {
+	if axis != bazel.OsConfigurationAxis && axis != bazel.OsArchConfigurationAxis {
+		return true
+	}
+	return !strings.HasPrefix(config, bazel.OsDarwin) && !strings.HasPrefix(config, bazel.OsWindows)
+}
+
 func bp2buildBinaryLinkerProps(ctx android.BazelConversionPathContext, m *Module) binaryLinkerAttrs {
 	attrs := binaryLinkerAttrs{}
 	bp2BuildPropParseHelper(ctx, m, &BinaryLinkerProperties{}, func(axis bazel.ConfigurationAxis, config string, props interface{}) {
@@ -1954,9 +2609,10 @@ func bp2buildBinaryLinkerProps(ctx android.BazelConversionPathContext, m *Module
 			if linkBinaryShared := !proptools.Bool(staticExecutable); !linkBinaryShared {
 				attrs.Linkshared = &linkBinaryShared
 			}
-		} else if staticExecutable != nil {
+		} else if staticExecutable != nil && staticExecutableSupported(axis, config) {
 			// TODO(b/202876379): Static_executable is arch-variant; however, linkshared is a
-			// nonconfigurable attribute. Only 4 AOSP modules use this feature, defer handling
+			// nonconfigurable attribute, and static executables also drop the dynamic deps
+			// and crt objects of the binary. Only 4 AOSP modules use this feature, defer handling
 			ctx.ModuleErrorf("bp2build cannot migrate a module with arch/target-specific static_executable values")
 		}
 		if stem := linkerProps.Stem; stem != nil {
@@ -1974,17 +2630,40 @@ type sanitizerValues struct {
 	features                 bazel.StringListAttribute
 	copts                    bazel.StringListAttribute
 	additionalCompilerInputs bazel.LabelListAttribute
+	runtimeDynamicDeps       bazel.LabelListAttribute
 }
 
-func bp2buildSanitizerFeatures(ctx android.BazelConversionPathContext, m *Module) sanitizerValues {
+// bp2buildUbsanRuntimeDeps returns the label of the full UBSan runtime library. The name of the
+// runtime library doesn't depend on the toolchain.
+func bp2buildUbsanRuntimeDeps(ctx android.Bp2buildMutatorContext) bazel.LabelList {
+	return android.BazelLabelForModuleDeps(ctx, []string{config.UndefinedBehaviorSanitizerRuntimeLibrary(nil)})
+}
+
+func bp2buildSanitizerFeatures(ctx android.Bp2buildMutatorContext, m *Module) sanitizerValues {
 	sanitizerFeatures := bazel.StringListAttribute{}
 	sanitizerCopts := bazel.StringListAttribute{}
 	sanitizerCompilerInputs := bazel.LabelListAttribute{}
+	sanitizerRuntimeDeps := bazel.LabelListAttribute{}
 	memtagFeatures := bazel.StringListAttribute{}
-	memtagFeature := ""
+	var memtagProps, arm64MemtagProps *SanitizeProperties
+	hwasanFeatures := bazel.StringListAttribute{}
+	hwasanFeature := ""
+	cfiEnabled := false
 	bp2BuildPropParseHelper(ctx, m, &SanitizeProperties{}, func(axis bazel.ConfigurationAxis, config string, props interface{}) {
 		var features []string
 		if sanitizerProps, ok := props.(*SanitizeProperties); ok {
+			if proptools.Bool(sanitizerProps.Sanitize.All_undefined) {
+				features = append(features, "ubsan_undefined")
+				// Soong links the full UBSan runtime into bionic and musl shared libraries and
+				// dynamically linked binaries; glibc host variants get it from clang directly.
+				ubsanRuntime := bp2buildUbsanRuntimeDeps(ctx)
+				if axis == bazel.NoConfigAxis {
+					sanitizerRuntimeDeps.SetSelectValue(bazel.OsConfigurationAxis, bazel.OsAndroid, ubsanRuntime)
+					sanitizerRuntimeDeps.SetSelectValue(bazel.OsConfigurationAxis, "linux_musl", ubsanRuntime)
+				} else {
+					sanitizerRuntimeDeps.SetSelectValue(axis, config, ubsanRuntime)
+				}
+			}
 			if sanitizerProps.Sanitize.Integer_overflow != nil && *sanitizerProps.Sanitize.Integer_overflow {
 				features = append(features, "ubsan_integer_overflow")
 			}
@@ -2000,49 +2679,139 @@ func bp2buildSanitizerFeatures(ctx android.BazelConversionPathContext, m *Module
 			}
 			if sanitizerProps.Sanitize.Cfi != nil && !proptools.Bool(sanitizerProps.Sanitize.Cfi) {
 				features = append(features, "-android_cfi")
-			} else if proptools.Bool(sanitizerProps.Sanitize.Cfi) {
-				features = append(features, "android_cfi")
-				if proptools.Bool(sanitizerProps.Sanitize.Config.Cfi_assembly_support) {
-					features = append(features, "android_cfi_assembly_support")
+			} else {
+				if proptools.Bool(sanitizerProps.Sanitize.Cfi) {
+					cfiEnabled = true
+					features = append(features, "android_cfi")
+					if proptools.Bool(sanitizerProps.Sanitize.Config.Cfi_assembly_support) {
+						features = append(features, "android_cfi_assembly_support")
+					}
 				}
+				features = append(features, bp2buildCfiCrossDsoFeatures(m, sanitizerProps)...)
 			}
 
-			if sanitizerProps.Sanitize.Memtag_heap != nil {
-				if (axis == bazel.NoConfigAxis && memtagFeature == "") ||
+			if sanitizerProps.Sanitize.Memtag_heap != nil || sanitizerProps.Sanitize.Memtag_stack != nil {
+				if axis == bazel.NoConfigAxis {
+					memtagProps = sanitizerProps
+				} else if axis == bazel.OsArchConfigurationAxis && config == bazel.OsArchAndroidArm64 {
+					arm64MemtagProps = sanitizerProps
+				}
+			}
+			if sanitizerProps.Sanitize.Hwaddress != nil {
+				if (axis == bazel.NoConfigAxis && hwasanFeature == "") ||
 					(axis == bazel.OsArchConfigurationAxis && config == bazel.OsArchAndroidArm64) {
-					memtagFeature = setMemtagValue(sanitizerProps, &memtagFeatures)
+					hwasanFeature = setHwasanValue(sanitizerProps, &hwasanFeatures)
 				}
 			}
 			sanitizerFeatures.SetSelectValue(axis, config, features)
 		}
 	})
+	if memtagProps != nil || arm64MemtagProps != nil {
+		setMemtagValue(&memtagFeatures, memtagProps, arm64MemtagProps)
+	}
 	sanitizerFeatures.Append(memtagFeatures)
+	sanitizerFeatures.Append(hwasanFeatures)
+	if cfiEnabled {
+		sanitizerFeatures.Append(bp2buildCfiVisibilityFeatures(ctx, m))
+	}
 
 	return sanitizerValues{
 		features:                 sanitizerFeatures,
 		copts:                    sanitizerCopts,
 		additionalCompilerInputs: sanitizerCompilerInputs,
+		runtimeDynamicDeps:       sanitizerRuntimeDeps,
 	}
 }
 
-func setMemtagValue(sanitizerProps *SanitizeProperties, memtagFeatures *bazel.StringListAttribute) string {
+// bp2buildCfiCrossDsoFeatures returns the cfi cross-dso features for a single configuration of
+// the sanitize properties. sanitize.config.cfi_cross_dso is converted even when cfi is enabled in
+// another configuration, as the cross-dso feature has no effect on variants without cfi.
+func bp2buildCfiCrossDsoFeatures(m *Module, sanitizerProps *SanitizeProperties) []string {
+	if proptools.Bool(sanitizerProps.Sanitize.Cfi) && m.Binary() && m.StaticExecutable() {
+		// Soong always removes the cross-dso flags from static executables.
+		return []string{"-android_cfi_cross_dso"}
+	} else if crossDso := sanitizerProps.Sanitize.Config.Cfi_cross_dso; crossDso != nil {
+		if *crossDso {
+			return []string{"android_cfi_cross_dso"}
+		}
+		return []string{"-android_cfi_cross_dso"}
+	}
+	return nil
+}
+
+// bp2buildCfiVisibilityFeatures disables the default visibility of cfi in the configurations
+// setting hidden visibility in their cflags, as Soong only adds -fvisibility=default for cfi if
+// visibility was not already set to hidden. Like the cross-dso feature, it has no effect on
+// variants without cfi, so cfi and the cflags may be set in different axes.
+func bp2buildCfiVisibilityFeatures(ctx android.Bp2buildMutatorContext, m *Module) bazel.StringListAttribute {
+	features := bazel.StringListAttribute{}
+	bp2BuildPropParseHelper(ctx, m, &BaseCompilerProperties{}, func(axis bazel.ConfigurationAxis, cfg string, props interface{}) {
+		if compilerProps, ok := props.(*BaseCompilerProperties); ok && inList(config.VisibilityHiddenFlag, compilerProps.Cflags) {
+			features.SetSelectValue(axis, cfg, []string{"-android_cfi_visibility_default"})
+		}
+	})
+	return features
+}
+
+// setMemtagValue sets the memtag features of the given sanitize properties, which
+// set memtag_heap or memtag_stack, for android_arm64, the only target supporting
+// memtag in Soong. Like Soong merging the android_arm64 properties over the
+// top-level ones, each property is taken from the last of the given properties
+// setting it.
+func setMemtagValue(memtagFeatures *bazel.StringListAttribute, sanitizerProps ...*SanitizeProperties) {
+	var memtagHeap, memtagStack, diagMemtagHeap *bool
+	for _, props := range sanitizerProps {
+		if props == nil {
+			continue
+		}
+		if props.Sanitize.Memtag_heap != nil {
+			memtagHeap = props.Sanitize.Memtag_heap
+		}
+		if props.Sanitize.Memtag_stack != nil {
+			memtagStack = props.Sanitize.Memtag_stack
+		}
+		if props.Sanitize.Diag.Memtag_heap != nil {
+			diagMemtagHeap = props.Sanitize.Diag.Memtag_heap
+		}
+	}
+
 	var features []string
-	if proptools.Bool(sanitizerProps.Sanitize.Memtag_heap) {
-		features = append(features, "memtag_heap")
-	} else {
-		features = append(features, "-memtag_heap")
+	if memtagHeap != nil {
+		if *memtagHeap {
+			features = append(features, "memtag_heap")
+		} else {
+			features = append(features, "-memtag_heap")
+		}
+	}
+	if memtagStack != nil {
+		if *memtagStack {
+			features = append(features, "memtag_stack")
+		} else {
+			features = append(features, "-memtag_stack")
+		}
 	}
 	// Logic comes from: https://cs.android.com/android/platform/superproject/main/+/32ea1afbd1148b0b78553f24fa61116c999eb968:build/soong/cc/sanitize.go;l=910
-	if sanitizerProps.Sanitize.Diag.Memtag_heap != nil {
-		if proptools.Bool(sanitizerProps.Sanitize.Diag.Memtag_heap) {
+	if diagMemtagHeap != nil {
+		if *diagMemtagHeap {
 			features = append(features, "diag_memtag_heap")
 		} else {
 			features = append(features, "-diag_memtag_heap")
 		}
 	}
 	memtagFeatures.SetSelectValue(bazel.OsArchConfigurationAxis, bazel.OsArchAndroidArm64, features)
+}
 
-	return features[0]
+// setHwasanValue sets the hwasan feature of the given sanitize properties, which set
+// hwaddress, for android_arm64, the only target supporting HWASan in Soong, and
+// returns it. Disabling the feature opts the module out of HWASan when it is enabled
+// for all the modules of the product, i.e. with SANITIZE_TARGET=hwaddress.
+func setHwasanValue(sanitizerProps *SanitizeProperties, hwasanFeatures *bazel.StringListAttribute) string {
+	feature := "hwasan"
+	if !proptools.Bool(sanitizerProps.Sanitize.Hwaddress) {
+		feature = "-hwasan"
+	}
+	hwasanFeatures.SetSelectValue(bazel.OsArchConfigurationAxis, bazel.OsArchAndroidArm64, []string{feature})
+	return feature
 }
 
 func bp2buildLtoFeatures(ctx android.BazelConversionPathContext, m *Module) bazel.StringListAttribute {
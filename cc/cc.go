@@ -4225,11 +4225,27 @@ func (c *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 		}
 	}
 
+	if bp2buildHasProtoPlugin(ctx, c) {
+		// There are no Bazel rules generating code with custom protoc plugins,
+		// so the module would be converted to plain cc_proto_library targets.
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "proto.plugin")
+		return
+	}
+
+	if proptools.String(c.Properties.Min_sdk_version) == "apex_inherit" {
+		// The min_sdk_version is the one of the apex the module is built for, which
+		// isn't known when converting the module.
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "min_sdk_version: \"apex_inherit\"")
+		return
+	}
+
 	prebuilt := c.IsPrebuilt()
 	switch c.typ() {
 	case binary:
 		if prebuilt {
 			prebuiltBinaryBp2Build(ctx, c)
+		} else if c.benchmarkBinary() {
+			benchmarkBinaryBp2build(ctx, c)
 		} else {
 			binaryBp2build(ctx, c)
 		}
@@ -4255,6 +4255,53 @@ func TestStubsLibReexportsHeaders(t *testing.T) {
 	}
 }
 
+func TestExcludeHeaderLibs(t *testing.T) {
+	t.Parallel()
+	ctx := testCc(t, `
+		cc_library_headers {
+			name: "libheader_a",
+			export_include_dirs: ["include/header_a"],
+		}
+
+		cc_library_headers {
+			name: "libheader_b",
+			export_include_dirs: ["include/header_b"],
+		}
+
+		cc_library_static {
+			name: "libfoo",
+			srcs: ["foo.c"],
+			header_libs: ["libheader_a", "libheader_b"],
+			export_header_lib_headers: ["libheader_b"],
+			arch: {
+				arm64: {
+					exclude_header_libs: ["libheader_a", "libheader_b"],
+				},
+			},
+		}
+
+		cc_library_shared {
+			name: "libclient",
+			srcs: ["foo.c"],
+			static_libs: ["libfoo"],
+		}`)
+
+	cFlags := ctx.ModuleForTests("libfoo", "android_arm_armv7-a-neon_static").Rule("cc").Args["cFlags"]
+	android.AssertStringDoesContain(t, "arm cflags", cFlags, "-Iinclude/header_a")
+	android.AssertStringDoesContain(t, "arm cflags", cFlags, "-Iinclude/header_b")
+
+	cFlags = ctx.ModuleForTests("libfoo", "android_arm64_armv8-a_static").Rule("cc").Args["cFlags"]
+	android.AssertStringDoesNotContain(t, "arm64 cflags", cFlags, "-Iinclude/header_a")
+	android.AssertStringDoesNotContain(t, "arm64 cflags", cFlags, "-Iinclude/header_b")
+
+	// The excluded header libs are not reexported either.
+	cFlags = ctx.ModuleForTests("libclient", "android_arm_armv7-a-neon_shared").Rule("cc").Args["cFlags"]
+	android.AssertStringDoesContain(t, "arm client cflags", cFlags, "-Iinclude/header_b")
+
+	cFlags = ctx.ModuleForTests("libclient", "android_arm64_armv8-a_shared").Rule("cc").Args["cFlags"]
+	android.AssertStringDoesNotContain(t, "arm64 client cflags", cFlags, "-Iinclude/header_b")
+}
+
 func TestAidlLibraryWithHeaders(t *testing.T) {
 	t.Parallel()
 	ctx := android.GroupFixturePreparers(
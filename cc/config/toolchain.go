@@ -37,6 +37,83 @@ func registerToolchainFactory(os android.OsType, arch android.ArchType, factory
 	toolchainFactories[os][arch] = factory
 }
 
+// Bp2buildCommonToolchainLdflags returns the ldflags added to every link by all the toolchains
+// whose os and arch match. bp2build drops the copies of these flags from the converted ldflags
+// of a module, as the Bazel cc toolchain adds them as well.
+func Bp2buildCommonToolchainLdflags(match func(os android.OsType, arch android.ArchType) bool) []string {
+	var common []string
+	matched := false
+	for os, archs := range toolchainFactories {
+		for arch := range archs {
+			if !match(os, arch) {
+				continue
+			}
+			flags := toolchainLdflags(os.Name, arch.Name)
+			if !matched {
+				common, matched = android.SortedUniqueStrings(flags), true
+			} else {
+				common = android.FilterListPred(common, func(flag string) bool {
+					return android.InList(flag, flags)
+				})
+			}
+		}
+	}
+	return common
+}
+
+// toolchainLdflags returns the ldflags the toolchain of the given os and arch, e.g. "android"
+// and "arm64", adds to every link.
+func toolchainLdflags(os, arch string) []string {
+	var flags []string
+	switch os {
+	case android.Android.Name:
+		flags = append(flags, deviceGlobalLldflags...)
+		switch arch {
+		case android.Arm.Name:
+			flags = append(flags, armLldflags...)
+		case android.Arm64.Name:
+			flags = append(flags, arm64Lldflags...)
+		case android.Riscv64.Name:
+			flags = append(flags, riscv64Lldflags...)
+		case android.X86.Name:
+			flags = append(flags, x86Ldflags...)
+		case android.X86_64.Name:
+			flags = append(flags, x86_64Ldflags...)
+		}
+	case android.LinuxBionic.Name:
+		switch arch {
+		case android.Arm64.Name:
+			flags = append(flags, arm64Lldflags...)
+			flags = append(flags, linuxCrossLdflags...)
+		case android.X86_64.Name:
+			flags = append(flags, linuxBionicLldflags...)
+		}
+	case android.Linux.Name, android.LinuxMusl.Name:
+		flags = append(flags, linuxLldflags...)
+		switch arch {
+		case android.Arm.Name:
+			flags = append(flags, linuxArmLldflags...)
+		case android.Arm64.Name:
+			flags = append(flags, linuxArm64Lldflags...)
+		case android.X86.Name:
+			flags = append(flags, linuxX86Ldflags...)
+		case android.X86_64.Name:
+			flags = append(flags, linuxX8664Ldflags...)
+		}
+	case android.Darwin.Name:
+		flags = append(flags, darwinLdflags...)
+	case android.Windows.Name:
+		flags = append(flags, windowsLldflags...)
+		switch arch {
+		case android.X86.Name:
+			flags = append(flags, windowsX86Ldflags...)
+		case android.X86_64.Name:
+			flags = append(flags, windowsX8664Ldflags...)
+		}
+	}
+	return flags
+}
+
 type toolchainContext interface {
 	Os() android.OsType
 	Arch() android.Arch
@@ -76,6 +76,45 @@ func TestArchGenruleCmd(t *testing.T) {
 	}
 }
 
+func TestArchGenruleCmdAndTools(t *testing.T) {
+	bp := `
+		cc_genrule {
+			name: "gen",
+			tool_files: ["tool"],
+			cmd: "$(location tool) $(out)",
+			out: ["out"],
+			arch: {
+				arm64: {
+					tool_files: ["tool_arm64"],
+					cmd: "$(location tool_arm64) --arm64 $(out)",
+				},
+			},
+		}
+		`
+	result := android.GroupFixturePreparers(
+		PrepareForIntegrationTestWithCc,
+		android.FixtureMergeMockFs(android.MockFS{
+			"tool":       nil,
+			"tool_arm64": nil,
+		}),
+	).RunTestWithBp(t, bp)
+
+	for _, tc := range []struct {
+		variant     string
+		expectedCmd string
+		unexpected  string
+	}{
+		{"android_arm_armv7-a-neon", "__SBOX_SANDBOX_DIR__/tools/src/tool __SBOX_SANDBOX_DIR__/out/out", "tool_arm64"},
+		{"android_arm64_armv8-a", "__SBOX_SANDBOX_DIR__/tools/src/tool_arm64 --arm64 __SBOX_SANDBOX_DIR__/out/out", "tool "},
+	} {
+		gen := result.ModuleForTests("gen", tc.variant)
+		sboxProto := android.RuleBuilderSboxProtoForTests(t, gen.Output("genrule.sbox.textproto"))
+		cmd := *sboxProto.Commands[0].Command
+		android.AssertStringDoesContain(t, tc.variant+" cmd", cmd, tc.expectedCmd)
+		android.AssertStringDoesNotContain(t, tc.variant+" cmd", cmd, tc.unexpected)
+	}
+}
+
 func TestLibraryGenruleCmd(t *testing.T) {
 	bp := `
 		cc_library {
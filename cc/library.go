@@ -277,6 +277,7 @@ type bazelCcLibraryAttributes struct {
 type aidlLibraryAttributes struct {
 	Srcs        bazel.LabelListAttribute
 	Include_dir *string
+	Deps        bazel.LabelListAttribute
 	Tags        bazel.StringListAttribute
 }
 
@@ -349,6 +350,7 @@ func libraryBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 		Runtime_deps:                      linkerAttrs.runtimeDeps,
 		SdkAttributes:                     Bp2BuildParseSdkAttributes(m),
 		Native_coverage:                   baseAttributes.Native_coverage,
+		Logtags:                           baseAttributes.Logtags,
 		Additional_compiler_inputs:        compilerAttrs.additionalCompilerInputs,
 	}
 
@@ -370,16 +372,24 @@ func libraryBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 		Deps:                              *linkerAttrs.deps.Clone().Append(sharedAttrs.Deps),
 		Implementation_deps:               *linkerAttrs.implementationDeps.Clone().Append(sharedAttrs.Implementation_deps),
 		Dynamic_deps:                      *linkerAttrs.dynamicDeps.Clone().Append(sharedAttrs.Dynamic_deps),
-		Implementation_dynamic_deps:       *linkerAttrs.implementationDynamicDeps.Clone().Append(sharedAttrs.Implementation_dynamic_deps),
+		Implementation_dynamic_deps:       *linkerAttrs.implementationDynamicDeps.Clone().Append(sharedAttrs.Implementation_dynamic_deps).Append(linkerAttrs.sanitizerRuntimeDynamicDeps),
 		Whole_archive_deps:                *linkerAttrs.wholeArchiveDeps.Clone().Append(sharedAttrs.Whole_archive_deps),
 		Implementation_whole_archive_deps: linkerAttrs.implementationWholeArchiveDeps,
 		System_dynamic_deps:               *linkerAttrs.systemDynamicDeps.Clone().Append(sharedAttrs.System_dynamic_deps),
 		Runtime_deps:                      linkerAttrs.runtimeDeps,
 		SdkAttributes:                     Bp2BuildParseSdkAttributes(m),
 		Native_coverage:                   baseAttributes.Native_coverage,
+		Logtags:                           baseAttributes.Logtags,
 		Additional_compiler_inputs:        compilerAttrs.additionalCompilerInputs,
 	}
 
+	m.convertTidyAttributes(ctx, &staticCommonAttrs.tidyAttributes)
+	staticCommonAttrs.Tidy_disabled_srcs.Append(staticAttrs.Tidy_disabled_srcs)
+	staticCommonAttrs.Tidy_timeout_srcs.Append(staticAttrs.Tidy_timeout_srcs)
+	m.convertTidyAttributes(ctx, &sharedCommonAttrs.tidyAttributes)
+	sharedCommonAttrs.Tidy_disabled_srcs.Append(sharedAttrs.Tidy_disabled_srcs)
+	sharedCommonAttrs.Tidy_timeout_srcs.Append(sharedAttrs.Tidy_timeout_srcs)
+
 	staticTargetAttrs := &bazelCcLibraryStaticAttributes{
 		staticOrSharedAttributes: staticCommonAttrs,
 		includesAttributes:       includeAttrs,
@@ -394,6 +404,10 @@ func libraryBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 		C_std:   compilerAttrs.cStd,
 
 		Features: *staticFeatures,
+
+		Linkopts: linkerAttrs.staticLinkopts,
+
+		Required: android.Bp2buildRequiredLabels(ctx),
 	}
 
 	sharedTargetAttrs := &bazelCcLibrarySharedAttributes{
@@ -418,6 +432,10 @@ func libraryBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 		bazelCcHeaderAbiCheckerAttributes: bp2buildParseAbiCheckerProps(ctx, m),
 
 		Fdo_profile: compilerAttrs.fdoProfile,
+
+		Overrides: bp2buildLibraryOverrides(m),
+
+		Required: android.Bp2buildRequiredLabels(ctx),
 	}
 
 	if compilerAttrs.stubsSymbolFile != nil && len(compilerAttrs.stubsVersions.Value) > 0 {
@@ -460,9 +478,13 @@ func libraryBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 		tagsForStaticVariant = android.ApexAvailableTagsWithoutTestApexes(ctx, m)
 	}
 	tagsForStaticVariant.Append(bazel.StringListAttribute{Value: staticAttrs.Apex_available})
+	// The static and shared variants are available to the apexes of the module, in
+	// addition to the ones of their own static or shared props, which may overlap.
+	tagsForStaticVariant.Value = android.FirstUniqueStrings(tagsForStaticVariant.Value)
 
 	tagsForSharedVariant := android.ApexAvailableTagsWithoutTestApexes(ctx, m)
 	tagsForSharedVariant.Append(bazel.StringListAttribute{Value: sharedAttrs.Apex_available})
+	tagsForSharedVariant.Value = android.FirstUniqueStrings(tagsForSharedVariant.Value)
 
 	ctx.CreateBazelTargetModuleWithRestrictions(staticProps,
 		android.CommonAttributes{
@@ -480,6 +502,8 @@ func libraryBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 			SkipData: proptools.BoolPtr(true),
 		},
 		sharedTargetAttrs, sharedAttrs.Enabled)
+	createStemAliasIfNeeded(ctx, m, compilerAttrs.stem)
+	createDistBazelTargetsIfNeeded(ctx, m)
 
 	createStubsBazelTargetIfNeeded(ctx, m, compilerAttrs, exportedIncludes, baseAttributes)
 }
@@ -2786,6 +2810,15 @@ func maybeInjectBoringSSLHash(ctx android.ModuleContext, outputFile android.Modu
 	return outputFile
 }
 
+// bp2buildLibraryOverrides returns the names of the modules overridden by the library, so that
+// packaging rules can honor the replacement at install time.
+func bp2buildLibraryOverrides(module *Module) []string {
+	if lib, ok := module.linker.(*libraryDecorator); ok {
+		return lib.Properties.Overrides
+	}
+	return nil
+}
+
 func bp2buildParseAbiCheckerProps(ctx android.Bp2buildMutatorContext, module *Module) bazelCcHeaderAbiCheckerAttributes {
 	lib, ok := module.linker.(*libraryDecorator)
 	if !ok {
@@ -2800,6 +2833,7 @@ func bp2buildParseAbiCheckerProps(ctx android.Bp2buildMutatorContext, module *Mo
 		Abi_checker_exclude_symbol_tags:     abiChecker.Exclude_symbol_tags,
 		Abi_checker_check_all_apis:          abiChecker.Check_all_apis,
 		Abi_checker_diff_flags:              abiChecker.Diff_flags,
+		Abi_checker_ref_dump_dirs:           abiChecker.Ref_dump_dirs,
 	}
 	if abiChecker.Symbol_file != nil {
 		symbolFile := android.BazelLabelForModuleSrcSingle(ctx, *abiChecker.Symbol_file)
@@ -2837,6 +2871,9 @@ func sharedOrStaticLibraryBp2Build(ctx android.Bp2buildMutatorContext, module *M
 	linkerAttrs.implementationDeps.Append(libSharedOrStaticAttrs.Implementation_deps)
 	linkerAttrs.dynamicDeps.Append(libSharedOrStaticAttrs.Dynamic_deps)
 	linkerAttrs.implementationDynamicDeps.Append(libSharedOrStaticAttrs.Implementation_dynamic_deps)
+	if !isStatic {
+		linkerAttrs.implementationDynamicDeps.Append(linkerAttrs.sanitizerRuntimeDynamicDeps)
+	}
 	linkerAttrs.systemDynamicDeps.Append(libSharedOrStaticAttrs.System_dynamic_deps)
 
 	asFlags := compilerAttrs.asFlags
@@ -2865,6 +2902,7 @@ func sharedOrStaticLibraryBp2Build(ctx android.Bp2buildMutatorContext, module *M
 		SdkAttributes:                     Bp2BuildParseSdkAttributes(module),
 		Runtime_deps:                      linkerAttrs.runtimeDeps,
 		Native_coverage:                   baseAttributes.Native_coverage,
+		Logtags:                           baseAttributes.Logtags,
 		Additional_compiler_inputs:        compilerAttrs.additionalCompilerInputs,
 	}
 
@@ -2892,6 +2930,10 @@ func sharedOrStaticLibraryBp2Build(ctx android.Bp2buildMutatorContext, module *M
 
 			Alwayslink: alwayslink,
 			Features:   *features,
+
+			Linkopts: linkerAttrs.staticLinkopts,
+
+			Required: android.Bp2buildRequiredLabels(ctx),
 		}
 
 	} else {
@@ -2926,6 +2968,10 @@ func sharedOrStaticLibraryBp2Build(ctx android.Bp2buildMutatorContext, module *M
 			bazelCcHeaderAbiCheckerAttributes: bp2buildParseAbiCheckerProps(ctx, module),
 
 			Fdo_profile: compilerAttrs.fdoProfile,
+
+			Overrides: bp2buildLibraryOverrides(module),
+
+			Required: android.Bp2buildRequiredLabels(ctx),
 		}
 		if compilerAttrs.stubsSymbolFile != nil && len(compilerAttrs.stubsVersions.Value) > 0 {
 			sharedLibAttrs.Stubs_symbol_file = compilerAttrs.stubsSymbolFile
@@ -2953,6 +2999,60 @@ func sharedOrStaticLibraryBp2Build(ctx android.Bp2buildMutatorContext, module *M
 		// TODO: b/303307456 - Remove this when data is properly supported in cc rules.
 		SkipData: proptools.BoolPtr(true),
 	}, attrs)
+	if !isStatic {
+		createStemAliasIfNeeded(ctx, module, compilerAttrs.stem)
+	}
+	createDistBazelTargetsIfNeeded(ctx, module)
+}
+
+// createStemAliasIfNeeded creates an alias named after the stem of a shared library, so that
+// the library can be found in Bazel by the name of its output. No alias is created for a stem
+// which differs between variants or which is the name of another module. The alias is
+// optional, so it is dropped if another target of the package has the same name, including
+// the stem alias of another library.
+func createStemAliasIfNeeded(ctx android.Bp2buildMutatorContext, m *Module, stem bazel.StringAttribute) {
+	if stem.Value == nil || stem.HasConfigurableValues() {
+		return
+	}
+	name := *stem.Value
+	if name == m.Name() || ctx.OtherModuleExists(name) {
+		return
+	}
+	ctx.CreateOptionalBazelTargetAlias(name, bazel.Label{Label: ":" + m.Name()})
+}
+
+// bazelCopyToDistDirAttributes contains the attributes of a copy_to_dist_dir target besides
+// data, which is one of the common attributes.
+type bazelCopyToDistDirAttributes struct {
+	Flat   *bool
+	Prefix *string
+}
+
+// createDistBazelTargetsIfNeeded creates a copy_to_dist_dir target for each of the dist and
+// dists properties of a library, which copies the library to the dist directory when run.
+// The make goals of the dist properties have no Bazel equivalent, and the dist properties
+// renaming the library or selecting another output with a tag can't be expressed with
+// copy_to_dist_dir, so they are reported as warnings.
+func createDistBazelTargetsIfNeeded(ctx android.Bp2buildMutatorContext, m *Module) {
+	props := bazel.BazelTargetModuleProperties{
+		Rule_class:        "copy_to_dist_dir",
+		Bzl_load_location: "//build/bazel_common_rules/dist:dist.bzl",
+	}
+	for i, dist := range m.Dists() {
+		if dist.Dest != nil || dist.Suffix != nil || dist.Tag != nil || dist.Append_artifact_with_product != nil {
+			ctx.AddBp2buildWarning("dist %d: dest, suffix, tag and append_artifact_with_product are not converted", i)
+			continue
+		}
+		attrs := &bazelCopyToDistDirAttributes{
+			Flat:   proptools.BoolPtr(true),
+			Prefix: dist.Dir,
+		}
+		ctx.CreateBazelTargetModule(props, android.CommonAttributes{
+			Name:     fmt.Sprintf("%s_dist_%d", m.Name(), i),
+			Data:     bazel.MakeLabelListAttribute(bazel.MakeLabelList([]bazel.Label{{Label: ":" + m.Name()}})),
+			SkipData: proptools.BoolPtr(true),
+		}, attrs)
+	}
 }
 
 type includesAttributes struct {
@@ -2982,6 +3082,13 @@ type bazelCcLibraryStaticAttributes struct {
 
 	Alwayslink *bool
 	Features   bazel.StringListAttribute
+
+	// Linker flags passed when linking the dependents of this library, e.g. the
+	// --wrap flags of its ldflags.
+	Linkopts bazel.StringListAttribute
+
+	// Labels of the modules installed along with this library.
+	Required bazel.LabelListAttribute
 }
 
 // TODO(b/199902614): Can this be factored to share with the other Attributes?
@@ -3018,6 +3125,12 @@ type bazelCcLibrarySharedAttributes struct {
 	bazelCcHeaderAbiCheckerAttributes
 
 	Fdo_profile bazel.LabelAttribute
+
+	// Names of the modules this library replaces at install time.
+	Overrides []string
+
+	// Labels of the modules installed along with this library.
+	Required bazel.LabelListAttribute
 }
 
 type bazelCcStubSuiteAttributes struct {
@@ -3044,4 +3157,5 @@ type bazelCcHeaderAbiCheckerAttributes struct {
 	Abi_checker_exclude_symbol_tags     []string
 	Abi_checker_check_all_apis          *bool
 	Abi_checker_diff_flags              []string
+	Abi_checker_ref_dump_dirs           []string
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"android/soong/android"
+	"android/soong/bazel"
 	"android/soong/bazel/cquery"
 )
 
@@ -588,3 +589,16 @@ func TestWholeStaticLibPrebuilts(t *testing.T) {
 	android.AssertStringDoesContain(t, "missing flag for baz.o",
 		libtransitiveWithSrcs.Args["arObjs"], bazObj.Output.String())
 }
+
+func TestIncludesFromLabelListPackageLocal(t *testing.T) {
+	t.Parallel()
+	labels := bazel.MakeLabelList([]bazel.Label{
+		{Label: "//foo/bar:gen_hdr"},
+		{Label: "//other:gen_hdr"},
+		{Label: ":local_gen_hdr"},
+		{Label: "//other:gen_hdr2"},
+	})
+	relative, absolute := includesFromLabelList(labels, "foo/bar", nil, nil)
+	android.AssertDeepEquals(t, "relative includes", []string{"."}, relative)
+	android.AssertDeepEquals(t, "absolute includes", []string{"other"}, absolute)
+}
@@ -580,6 +580,29 @@ type bazelCcApiContributionAttributes struct {
 	Library_name string
 }
 
+// ndkLibraryFirstVersionForBp2build returns the first API level of the stubs
+// generated by bp2build for an ndk_library with the given first_version.
+//
+// Like nativeApiLevelFromUser, which resolves the first_version of the stubs of
+// each architecture, it resolves "minimum" and raises lower API levels to the
+// first API level supporting the architecture. The stub suite is shared by all
+// the architectures though, so it uses the lowest of those, i.e. the minimum
+// supported SDK version.
+func ndkLibraryFirstVersionForBp2build(ctx android.Bp2buildMutatorContext, raw string) (android.ApiLevel, error) {
+	min := ctx.Config().MinSupportedSdkVersion()
+	if raw == "minimum" {
+		return min, nil
+	}
+	value, err := android.ApiLevelFromUser(ctx, raw)
+	if err != nil {
+		return android.NoneApiLevel, err
+	}
+	if value.LessThan(min) {
+		return min, nil
+	}
+	return value, nil
+}
+
 func ndkLibraryBp2build(ctx android.Bp2buildMutatorContext, c *Module) {
 	ndk, _ := c.linker.(*stubDecorator)
 	props := bazel.BazelTargetModuleProperties{
@@ -587,7 +610,7 @@ func ndkLibraryBp2build(ctx android.Bp2buildMutatorContext, c *Module) {
 		Bzl_load_location: "//build/bazel/rules/cc:cc_stub_library.bzl",
 	}
 	sourceLibraryName := strings.TrimSuffix(c.Name(), ".ndk")
-	fromApiLevel, err := android.ApiLevelFromUser(ctx, proptools.String(ndk.properties.First_version))
+	fromApiLevel, err := ndkLibraryFirstVersionForBp2build(ctx, proptools.String(ndk.properties.First_version))
 	if err != nil {
 		ctx.PropertyErrorf("first_version", "error converting first_version %v", proptools.String(ndk.properties.First_version))
 	}
@@ -598,11 +621,17 @@ func ndkLibraryBp2build(ctx android.Bp2buildMutatorContext, c *Module) {
 		Soname:          proptools.StringPtr(sourceLibraryName + ".so"),
 		Api_surface:     proptools.StringPtr(android.PublicApi.String()),
 		Included_in_ndk: proptools.BoolPtr(true),
+		Deps:            bazel.MakeLabelListAttribute(bazelLabelForHeaderDeps(ctx, ndk.properties.Export_header_libs)),
 	}
+	var tags bazel.StringListAttribute
 	if sourceLibrary, exists := ctx.ModuleFromName(sourceLibraryName); exists {
 		// the source library might not exist in minimal/unbuildable branches like kernel-build-tools.
 		// check for its existence
 		attrs.Source_library_label = proptools.StringPtr(c.GetBazelLabel(ctx, sourceLibrary))
+		// The stubs can be linked from the same apexes as the source library.
+		if m, ok := sourceLibrary.(android.Module); ok {
+			tags = android.ApexAvailableTagsWithoutTestApexes(ctx, m)
+		}
 	}
 	if ctx.Config().RawPlatformSdkVersion() != nil {
 		// This is a hack to populate `versions` only on branches that set a platform_sdk_version
@@ -613,7 +642,7 @@ func ndkLibraryBp2build(ctx android.Bp2buildMutatorContext, c *Module) {
 
 	ctx.CreateBazelTargetModule(
 		props,
-		android.CommonAttributes{Name: c.Name() + "_stub_libs"},
+		android.CommonAttributes{Name: c.Name() + "_stub_libs", Tags: tags},
 		attrs,
 	)
 }
@@ -145,6 +145,7 @@ type bazelObjectAttributes struct {
 	Deps                bazel.LabelListAttribute
 	System_dynamic_deps bazel.LabelListAttribute
 	Copts               bazel.StringListAttribute
+	Features            bazel.StringListAttribute
 	Asflags             bazel.StringListAttribute
 	Local_includes      bazel.StringListAttribute
 	Absolute_includes   bazel.StringListAttribute
@@ -212,6 +213,7 @@ func objectBp2Build(ctx android.Bp2buildMutatorContext, m *Module) {
 		Deps:                deps,
 		System_dynamic_deps: systemDynamicDeps,
 		Copts:               compilerAttrs.copts,
+		Features:            compilerAttrs.features,
 		Asflags:             asFlags,
 		Local_includes:      compilerAttrs.localIncludes,
 		Absolute_includes:   compilerAttrs.absoluteIncludes,
@@ -176,7 +176,7 @@ type protoAttributes struct {
 	// A list of cc_library_* targets that the generated cpp code depends on
 	Cc_deps bazel.LabelListAttribute
 
-	Min_sdk_version *string
+	SdkAttributes
 }
 
 type bp2buildProtoDeps struct {
@@ -185,6 +185,19 @@ type bp2buildProtoDeps struct {
 	protoDep                     *bazel.LabelAttribute
 }
 
+// bp2buildHasProtoPlugin returns whether the given module sets proto.plugin, to
+// generate the code of its .proto sources with a custom protoc plugin rather than
+// the C++ code generator the cc_proto_library rules use.
+func bp2buildHasProtoPlugin(ctx android.Bp2buildMutatorContext, m *Module) bool {
+	hasPlugin := false
+	bp2BuildPropParseHelper(ctx, m, &android.ProtoProperties{}, func(axis bazel.ConfigurationAxis, config string, props interface{}) {
+		if protoProps, ok := props.(*android.ProtoProperties); ok && protoProps.Proto.Plugin != nil {
+			hasPlugin = true
+		}
+	})
+	return hasPlugin
+}
+
 func bp2buildProto(ctx android.Bp2buildMutatorContext, m *Module, protoSrcs bazel.LabelListAttribute, la linkerAttributes) bp2buildProtoDeps {
 	var ret bp2buildProtoDeps
 
@@ -223,6 +236,9 @@ func bp2buildProto(ctx android.Bp2buildMutatorContext, m *Module, protoSrcs baze
 	protoAttrs.Cc_deps.Append(la.implementationDynamicDeps)
 	protoAttrs.Cc_deps.Append(la.implementationWholeArchiveDeps)
 	protoAttrs.Cc_deps.Append(la.wholeArchiveDeps)
+	// Add the exported deps as well, since the generated code may include their headers.
+	protoAttrs.Cc_deps.Append(la.deps)
+	protoAttrs.Cc_deps.Append(la.dynamicDeps)
 	// Subtract myself to prevent possible circular dep
 	protoAttrs.Cc_deps = bazel.SubtractBazelLabelListAttribute(
 		protoAttrs.Cc_deps,
@@ -243,7 +259,7 @@ func bp2buildProto(ctx android.Bp2buildMutatorContext, m *Module, protoSrcs baze
 		),
 	)
 
-	protoAttrs.Min_sdk_version = m.Properties.Min_sdk_version
+	protoAttrs.SdkAttributes = Bp2BuildParseSdkAttributes(m)
 
 	name := m.Name() + suffix
 	tags := android.ApexAvailableTagsWithoutTestApexes(ctx, m)
@@ -311,6 +311,9 @@ type SanitizeUserProps struct {
 	Config struct {
 		// Enables CFI support flags for assembly-heavy libraries
 		Cfi_assembly_support *bool `android:"arch_variant"`
+		// Enables cross-DSO CFI checks. Defaults to true; set to false to restrict CFI checks to
+		// calls within the module.
+		Cfi_cross_dso *bool `android:"arch_variant"`
 	} `android:"arch_variant"`
 
 	// List of sanitizers to pass to -fsanitize-recover
@@ -891,7 +894,7 @@ func (s *sanitize) flags(ctx ModuleContext, flags Flags) Flags {
 		}
 		flags.Local.LdFlags = append(flags.Local.LdFlags, cfiLdflags...)
 
-		if ctx.staticBinary() {
+		if ctx.staticBinary() || !BoolDefault(s.Properties.Sanitize.Config.Cfi_cross_dso, true) {
 			_, flags.Local.CFlags = removeFromList("-fsanitize-cfi-cross-dso", flags.Local.CFlags)
 			_, flags.Local.LdFlags = removeFromList("-fsanitize-cfi-cross-dso", flags.Local.LdFlags)
 		}
@@ -1275,6 +1275,67 @@ func TestCfi(t *testing.T) {
 	}
 }
 
+func TestCfiCrossDso(t *testing.T) {
+	t.Parallel()
+
+	bp := `
+	cc_library_shared {
+		name: "libcross_dso",
+		srcs: ["src.cc"],
+		sanitize: {
+			cfi: true,
+		},
+	}
+
+	cc_library_shared {
+		name: "libno_cross_dso",
+		srcs: ["src.cc"],
+		sanitize: {
+			cfi: true,
+			config: {
+				cfi_cross_dso: false,
+			},
+		},
+	}
+
+	cc_library_shared {
+		name: "libarch_no_cross_dso",
+		srcs: ["src.cc"],
+		sanitize: {
+			cfi: true,
+		},
+		arch: {
+			arm64: {
+				sanitize: {
+					config: {
+						cfi_cross_dso: false,
+					},
+				},
+			},
+		},
+	}
+`
+	result := prepareForCcTest.RunTestWithBp(t, bp)
+
+	check := func(t *testing.T, module, variant string, expectCrossDso bool) {
+		t.Helper()
+		m := result.ModuleForTests(module, variant)
+		cFlags := m.Rule("cc").Args["cFlags"]
+		ldFlags := m.Rule("ld").Args["ldFlags"]
+		if g := strings.Contains(cFlags, cfiCrossDsoFlag); g != expectCrossDso {
+			t.Errorf("%s %s: expected cFlags to contain %s: %t, got %q", module, variant, cfiCrossDsoFlag, expectCrossDso, cFlags)
+		}
+		if g := strings.Contains(ldFlags, cfiCrossDsoFlag); g != expectCrossDso {
+			t.Errorf("%s %s: expected ldFlags to contain %s: %t, got %q", module, variant, cfiCrossDsoFlag, expectCrossDso, ldFlags)
+		}
+	}
+
+	check(t, "libcross_dso", "android_arm64_armv8-a_shared_cfi", true)
+	check(t, "libno_cross_dso", "android_arm64_armv8-a_shared_cfi", false)
+	check(t, "libarch_no_cross_dso", "android_arm64_armv8-a_shared_cfi", false)
+	check(t, "libarch_no_cross_dso", "android_arm_armv7-a-neon_shared_cfi", true)
+}
+
 func TestHwasan(t *testing.T) {
 	t.Parallel()
 
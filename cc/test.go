@@ -625,7 +625,7 @@ func (benchmark *benchmarkDecorator) install(ctx ModuleContext, file android.Pat
 }
 
 func NewBenchmark(hod android.HostOrDeviceSupported) *Module {
-	module, binary := newBinary(hod, false)
+	module, binary := newBinary(hod, true)
 	module.multilib = android.MultilibBoth
 	binary.baseInstaller = NewBaseInstaller("benchmarktest", "benchmarktest64", InstallInData)
 
@@ -637,6 +637,25 @@ func NewBenchmark(hod android.HostOrDeviceSupported) *Module {
 	return module
 }
 
+// benchmarkBinaryBp2build is the bp2build converter for cc_benchmark modules. A cc_benchmark
+// is a cc_binary with an implicit dependency on libgoogle-benchmark and data files installed
+// alongside it.
+func benchmarkBinaryBp2build(ctx android.Bp2buildMutatorContext, m *Module) {
+	binaryAttrs := binaryBp2buildAttrs(ctx, m)
+	binaryAttrs.Deps.Append(bazel.MakeLabelListAttribute(bazelLabelForStaticDeps(ctx, []string{"libgoogle-benchmark"})))
+	binaryAttrs.Deps.Value = bazel.FirstUniqueBazelLabelList(binaryAttrs.Deps.Value)
+
+	benchmark := m.linker.(*benchmarkDecorator)
+	data := bazel.MakeLabelListAttribute(android.BazelLabelForModuleSrc(ctx, benchmark.Properties.Data))
+
+	ctx.CreateBazelTargetModule(bazel.BazelTargetModuleProperties{
+		Rule_class:        "cc_binary",
+		Bzl_load_location: "//build/bazel/rules/cc:cc_binary.bzl",
+	},
+		android.CommonAttributes{Name: m.Name(), Data: data},
+		&binaryAttrs)
+}
+
 type ccTestBazelHandler struct {
 	module *Module
 }
@@ -684,12 +703,14 @@ func (handler *ccTestBazelHandler) ProcessBazelQueryResponse(ctx android.ModuleC
 type testBinaryAttributes struct {
 	binaryAttributes
 
-	Gtest *bool
+	Gtest    *bool
+	Isolated *bool
 
 	tidyAttributes
 	tradefed.TestConfigAttributes
 
-	Runs_on bazel.StringListAttribute
+	Runs_on     bazel.StringListAttribute
+	Test_suites bazel.StringListAttribute
 }
 
 // testBinaryBp2build is the bp2build converter for cc_test modules. A cc_test's
@@ -697,7 +718,6 @@ type testBinaryAttributes struct {
 // cc_binary, but has additional dependencies on test deps like gtest, and
 // produces additional runfiles like XML plans for Tradefed orchestration
 //
-// TODO(b/244432609): handle `isolated` property.
 // TODO(b/244432134): handle custom runpaths for tests that assume runfile layouts not
 // default to bazel. (see linkerInit function)
 func testBinaryBp2build(ctx android.Bp2buildMutatorContext, m *Module) {
@@ -715,13 +735,22 @@ func testBinaryBp2build(ctx android.Bp2buildMutatorContext, m *Module) {
 				var combinedData bazel.LabelList
 				combinedData.Append(android.BazelLabelForModuleSrc(ctx, p.Data))
 				combinedData.Append(android.BazelLabelForModuleDeps(ctx, p.Data_bins))
-				combinedData.Append(android.BazelLabelForModuleDeps(ctx, p.Data_libs))
+				combinedData.Append(bazelLabelForSharedDeps(ctx, p.Data_libs))
 				data.SetSelectValue(axis, config, combinedData)
 				tags.SetSelectValue(axis, config, p.Test_options.Tags)
 			}
 		}
 	}
 
+	testInstallerProps := m.GetArchVariantProperties(ctx, &TestInstallerProperties{})
+	for axis, configToProps := range testInstallerProps {
+		for config, props := range configToProps {
+			if p, ok := props.(*TestInstallerProperties); ok {
+				testBinaryAttrs.Test_suites.SetSelectValue(axis, config, p.Test_suites)
+			}
+		}
+	}
+
 	// The logic comes from https://cs.android.com/android/platform/superproject/main/+/0df8153267f96da877febc5332240fa06ceb8533:build/soong/cc/sanitize.go;l=488
 	var features bazel.StringListAttribute
 	curFeatures := testBinaryAttrs.binaryAttributes.Features.SelectValue(bazel.OsArchConfigurationAxis, bazel.OsArchAndroidArm64)
@@ -746,6 +775,7 @@ func testBinaryBp2build(ctx android.Bp2buildMutatorContext, m *Module) {
 	// This ensures that if this property is not set in Android.bp file, it will not be set in BUILD file either
 	// cc_test macro will default gtest to True
 	testBinaryAttrs.Gtest = testBinary.LinkerProperties.Gtest
+	testBinaryAttrs.Isolated = testBinary.LinkerProperties.Isolated
 
 	addImplicitGtestDeps(ctx, &testBinaryAttrs, gtest, gtestIsolated)
 
@@ -28,16 +28,16 @@ import (
 
 type TidyProperties struct {
 	// whether to run clang-tidy over C-like sources.
-	Tidy *bool
+	Tidy *bool `android:"arch_variant"`
 
 	// Extra flags to pass to clang-tidy
-	Tidy_flags []string
+	Tidy_flags []string `android:"arch_variant"`
 
 	// Extra checks to enable or disable in clang-tidy
-	Tidy_checks []string
+	Tidy_checks []string `android:"arch_variant"`
 
 	// Checks that should be treated as errors.
-	Tidy_checks_as_errors []string
+	Tidy_checks_as_errors []string `android:"arch_variant"`
 }
 
 type tidyFeature struct {
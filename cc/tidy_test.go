@@ -169,6 +169,46 @@ func TestTidyChecks(t *testing.T) {
 	})
 }
 
+func TestArchVariantTidyProperties(t *testing.T) {
+	// The tidy properties can be set per arch and per target.
+	bp := `
+		cc_library_shared {
+			name: "libfoo",
+			srcs: ["foo.c"],
+			tidy_checks: ["common-*"],
+			arch: {
+				arm: {
+					tidy: false,
+				},
+				arm64: {
+					tidy_flags: ["-header-filter=arm64/"],
+					tidy_checks: ["arm64-*"],
+				},
+			},
+			target: {
+				android: {
+					tidy_checks_as_errors: ["android-*"],
+				},
+			},
+		}`
+	ctx := testCc(t, bp)
+
+	flags := ctx.ModuleForTests("libfoo", "android_arm64_armv8-a_shared").Rule("clangTidy").Args["tidyFlags"]
+	for _, flag := range []string{
+		"-header-filter=arm64/",
+		"'common-*','arm64-*'",
+		"-warnings-as-errors='android-*',${config.TidyGlobalNoErrorChecks}",
+	} {
+		if !strings.Contains(flags, flag) {
+			t.Errorf("tidyFlags %v for arm64 does not contain %s.", flags, flag)
+		}
+	}
+
+	if rule := ctx.ModuleForTests("libfoo", "android_arm_armv7-a-neon_shared").MaybeRule("clangTidy"); rule.Rule != nil {
+		t.Errorf("expected no clangTidy rule for arm, got %v", rule.Args["tidyFlags"])
+	}
+}
+
 func TestWithTidy(t *testing.T) {
 	// When WITH_TIDY=1 or (ALLOW_LOCAL_TIDY_TRUE=1 and local tidy:true)
 	// a C++ library should depend on .tidy files.
@@ -86,6 +86,9 @@ func init() {
 	flag.BoolVar(&cmdlineArgs.UseBazelProxy, "use-bazel-proxy", false, "communicate with bazel using unix socket proxy instead of spawning subprocesses")
 	flag.BoolVar(&cmdlineArgs.BuildFromTextStub, "build-from-text-stub", false, "build Java stubs from API text files instead of source files")
 	flag.BoolVar(&cmdlineArgs.EnsureAllowlistIntegrity, "ensure-allowlist-integrity", false, "verify that allowlisted modules are mixed-built")
+	flag.BoolVar(&cmdlineArgs.Bp2buildCheckOnly, "check-only", false, "with --bp2build_marker, fail if the generated bp2build files on disk are stale instead of rewriting them")
+	flag.BoolVar(&cmdlineArgs.Bp2buildRunValidations, "run-validations", false, "with --bp2build_marker, fail if a generated BUILD or .bzl file has a Starlark syntax error or references a label which does not resolve")
+	flag.StringVar(&cmdlineArgs.Bp2buildCoverageFile, "bp2build-coverage", "", "If set, write whether bp2build converts each property of the cc module types to the specified CSV (or .html) file, then exit")
 	// Flags that probably shouldn't be flags of soong_build, but we haven't found
 	// the time to remove them yet
 	flag.BoolVar(&cmdlineArgs.RunGoTests, "t", false, "build and run go tests during bootstrap")
@@ -403,6 +406,13 @@ func main() {
 	shared.ReexecWithDelveMaybe(delveListen, delvePath)
 	android.InitSandbox(topDir)
 
+	if cmdlineArgs.Bp2buildCoverageFile != "" {
+		// The coverage is probed by converting modules of each cc module type in
+		// isolation, so it does not depend on the source tree.
+		writeBp2buildCoverage(shared.JoinPath(topDir, cmdlineArgs.Bp2buildCoverageFile))
+		return
+	}
+
 	availableEnv := parseAvailableEnv()
 	configuration, err := android.NewConfig(cmdlineArgs, availableEnv)
 	maybeQuit(err, "")
@@ -497,6 +507,16 @@ func touch(path string) {
 	maybeQuit(err, "error touching '%s'", path)
 }
 
+// writeBp2buildCoverage writes the bp2build property coverage report of the cc
+// module types to path. The probe modules are converted in a temporary directory.
+func writeBp2buildCoverage(path string) {
+	buildDir, err := os.MkdirTemp("", "bp2build_coverage")
+	maybeQuit(err, "error creating the bp2build coverage directory")
+	defer os.RemoveAll(buildDir)
+	err = bp2build.WriteCcPropertyCoverage(path, buildDir)
+	maybeQuit(err, "error writing the bp2build coverage file '%s'", path)
+}
+
 // Read the bazel.list file that the Soong Finder already dumped earlier (hopefully)
 // It contains the locations of BUILD files, BUILD.bazel files, etc. in the source dir
 func getExistingBazelRelatedFiles(topDir string) ([]string, error) {
@@ -658,12 +678,21 @@ func runBp2Build(ctx *android.Context, extraNinjaDeps []string, metricsDir strin
 
 		globListFiles := writeBuildGlobsNinjaFile(ctx)
 		ninjaDeps = append(ninjaDeps, globListFiles...)
+		ninjaDeps = append(ninjaDeps, ctx.Config().Bp2buildAllowlistFiles()...)
 
 		// Run the code-generation phase to convert BazelTargetModules to BUILD files
 		// and print conversion codegenMetrics to the user.
 		codegenContext := bp2build.NewCodegenContext(ctx.Config(), ctx, bp2build.Bp2Build, topDir)
+		codegenContext.SetCheckOnly(cmdlineArgs.Bp2buildCheckOnly)
+		codegenContext.SetRunValidations(cmdlineArgs.Bp2buildRunValidations)
 		codegenMetrics = bp2build.Codegen(codegenContext)
 
+		// A check-only run doesn't update the bp2build files, so it must not mark
+		// them up to date either.
+		if cmdlineArgs.Bp2buildCheckOnly {
+			return
+		}
+
 		ninjaDeps = append(ninjaDeps, codegenContext.AdditionalNinjaDeps()...)
 
 		writeDepFile(cmdlineArgs.Bp2buildMarker, ctx.EventHandler, ninjaDeps)
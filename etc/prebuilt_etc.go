@@ -569,6 +569,7 @@ func PrebuiltFirmwareFactory() android.Module {
 	// This module is device-only
 	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibFirst)
 	android.InitDefaultableModule(module)
+	android.InitBazelModule(module)
 	return module
 }
 
@@ -730,11 +731,20 @@ func generatePrebuiltSnapshot(s snapshot.SnapshotSingleton, ctx android.Singleto
 type bazelPrebuiltFileAttributes struct {
 	Src               bazel.LabelAttribute
 	Filename          bazel.LabelAttribute
-	Dir               string
+	Dir               bazel.StringAttribute
 	Installable       bazel.BoolAttribute
 	Filename_from_src bazel.BoolAttribute
 }
 
+// bp2buildInstallDirBase returns the install directory base of the module, taking
+// socInstallDirBase into account for SoC specific modules.
+func (module *PrebuiltEtc) bp2buildInstallDirBase() string {
+	if module.SocSpecific() && module.socInstallDirBase != "" {
+		return module.socInstallDirBase
+	}
+	return module.installDirBase
+}
+
 // Bp2buildHelper returns a bazelPrebuiltFileAttributes used for the conversion
 // of prebuilt_*  modules. bazelPrebuiltFileAttributes has the common attributes
 // used by both prebuilt_etc_xml and other prebuilt_* moodules
@@ -792,9 +802,25 @@ func (module *PrebuiltEtc) Bp2buildHelper(ctx android.Bp2buildMutatorContext) (*
 		filename = ctx.ModuleName()
 	}
 
-	var dir = module.installDirBase
-	if module.SubDir() != "" {
-		dir = dir + "/" + module.SubDir()
+	// sub_dir and relative_install_path may be arch variant, so the install
+	// directory is selected by configuration, defaulting to the base directory.
+	var dir bazel.StringAttribute
+	dirBase := module.bp2buildInstallDirBase()
+	dir.SetValue(dirBase)
+	for axis, configToProps := range module.GetArchVariantProperties(ctx, &prebuiltSubdirProperties{}) {
+		for config, p := range configToProps {
+			props, ok := p.(*prebuiltSubdirProperties)
+			if !ok {
+				continue
+			}
+			subDir := proptools.String(props.Sub_dir)
+			if subDir == "" {
+				subDir = proptools.String(props.Relative_install_path)
+			}
+			if subDir != "" {
+				dir.SetSelectValue(axis, config, proptools.StringPtr(dirBase+"/"+subDir))
+			}
+		}
 	}
 
 	var installable bazel.BoolAttribute
@@ -821,9 +847,10 @@ func (module *PrebuiltEtc) Bp2buildHelper(ctx android.Bp2buildMutatorContext) (*
 // prebuilt_* modules (except prebuilt_etc_xml) are PrebuiltEtc,
 // which we treat as *PrebuiltFile*
 func (module *PrebuiltEtc) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
-	var dir = module.installDirBase
-	// prebuilt_file only supports "etc" or "usr/share" or "." as module installDirBase
-	if !(dir == "etc" || dir == "usr/share" || dir == ".") {
+	var dir = module.bp2buildInstallDirBase()
+	// prebuilt_file only supports "etc", "usr/share", "." or the firmware directories as
+	// module installDirBase
+	if !(dir == "etc" || dir == "usr/share" || dir == "." || dir == "etc/firmware" || dir == "firmware") {
 		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_TYPE_UNSUPPORTED, "dir")
 		return
 	}
@@ -21,10 +21,13 @@ import (
 	"github.com/google/blueprint/proptools"
 
 	"android/soong/android"
+	"android/soong/bazel"
+	"android/soong/ui/metrics/bp2build_metrics_proto"
 )
 
 type avbAddHashFooter struct {
 	android.ModuleBase
+	android.BazelModuleBase
 
 	properties avbAddHashFooterProperties
 
@@ -76,10 +79,11 @@ type avbAddHashFooterProperties struct {
 }
 
 // The AVB footer adds verification information to the image.
-func avbAddHashFooterFactory() android.Module {
+func AvbAddHashFooterFactory() android.Module {
 	module := &avbAddHashFooter{}
 	module.AddProperties(&module.properties)
 	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibFirst)
+	android.InitBazelModule(module)
 	return module
 }
 
@@ -206,3 +210,62 @@ var _ android.SourceFileProducer = (*avbAddHashFooter)(nil)
 func (a *avbAddHashFooter) Srcs() android.Paths {
 	return append(android.Paths{}, a.output)
 }
+
+type bazelAvbAddHashFooterAttributes struct {
+	Src                             bazel.LabelAttribute
+	Filename                        *string
+	Partition_name                  *string
+	Partition_size                  *int64
+	Private_key                     bazel.LabelAttribute
+	Algorithm                       *string
+	Salt                            *string
+	Props                           bazel.StringMapAttribute
+	Rollback_index                  *int64
+	Include_descriptors_from_images bazel.LabelListAttribute
+}
+
+// ConvertWithBp2build performs bp2build conversion of avb_add_hash_footer
+func (a *avbAddHashFooter) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
+	if a.properties.Src == nil {
+		ctx.PropertyErrorf("src", "missing source file")
+		return
+	}
+	if a.properties.Private_key == nil {
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "private_key is required")
+		return
+	}
+
+	var props bazel.StringMapAttribute
+	for _, prop := range a.properties.Props {
+		if prop.File != nil {
+			// TODO: Support props read from files once the Bazel rule supports them.
+			ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "props.file")
+			return
+		}
+		if props == nil {
+			props = bazel.StringMapAttribute{}
+		}
+		props[proptools.String(prop.Name)] = proptools.String(prop.Value)
+	}
+
+	attrs := &bazelAvbAddHashFooterAttributes{
+		Src:                             *bazel.MakeLabelAttribute(android.BazelLabelForModuleSrcSingle(ctx, *a.properties.Src).Label),
+		Filename:                        a.properties.Filename,
+		Partition_name:                  a.properties.Partition_name,
+		Partition_size:                  a.properties.Partition_size,
+		Private_key:                     *bazel.MakeLabelAttribute(android.BazelLabelForModuleSrcSingle(ctx, *a.properties.Private_key).Label),
+		Algorithm:                       a.properties.Algorithm,
+		Salt:                            a.properties.Salt,
+		Props:                           props,
+		Rollback_index:                  a.properties.Rollback_index,
+		Include_descriptors_from_images: bazel.MakeLabelListAttribute(android.BazelLabelForModuleSrc(ctx, a.properties.Include_descriptors_from_images)),
+	}
+
+	ctx.CreateBazelTargetModule(
+		bazel.BazelTargetModuleProperties{
+			Rule_class:        "avb_add_hash_footer",
+			Bzl_load_location: "//build/bazel/rules/avb:avb_add_hash_footer.bzl",
+		},
+		android.CommonAttributes{Name: a.Name()},
+		attrs)
+}
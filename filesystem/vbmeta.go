@@ -22,14 +22,17 @@ import (
 	"github.com/google/blueprint/proptools"
 
 	"android/soong/android"
+	"android/soong/bazel"
+	"android/soong/ui/metrics/bp2build_metrics_proto"
 )
 
 func init() {
-	android.RegisterModuleType("vbmeta", vbmetaFactory)
+	android.RegisterModuleType("vbmeta", VbmetaFactory)
 }
 
 type vbmeta struct {
 	android.ModuleBase
+	android.BazelModuleBase
 
 	properties vbmetaProperties
 
@@ -84,10 +87,11 @@ type chainedPartitionProperties struct {
 }
 
 // vbmeta is the partition image that has the verification information for other partitions.
-func vbmetaFactory() android.Module {
+func VbmetaFactory() android.Module {
 	module := &vbmeta{}
 	module.AddProperties(&module.properties)
 	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibFirst)
+	android.InitBazelModule(module)
 	return module
 }
 
@@ -273,3 +277,61 @@ func (v *vbmeta) OutputFiles(tag string) (android.Paths, error) {
 	}
 	return nil, fmt.Errorf("unsupported module reference tag %q", tag)
 }
+
+type bazelVbmetaAttributes struct {
+	Partition_name          *string
+	Stem                    *string
+	Private_key             bazel.LabelAttribute
+	Algorithm               *string
+	Rollback_index_file     bazel.LabelAttribute
+	Rollback_index_location *int64
+	Partitions              bazel.LabelListAttribute
+	// Chained partitions as "<name>:<rollback index location>", with their public keys at the same
+	// index of Chained_partition_keys.
+	Chained_partitions     []string
+	Chained_partition_keys bazel.LabelListAttribute
+}
+
+// ConvertWithBp2build performs bp2build conversion of vbmeta
+func (v *vbmeta) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
+	if v.properties.Private_key == nil {
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "private_key is required")
+		return
+	}
+
+	var chainedPartitions []string
+	var chainedPartitionKeys bazel.LabelList
+	for i, cp := range v.properties.Chained_partitions {
+		if cp.Public_key == nil {
+			// TODO: Support extracting the public key from chained_partitions.private_key.
+			ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED, "chained_partitions without public_key")
+			return
+		}
+		ril := proptools.IntDefault(cp.Rollback_index_location, i+1)
+		chainedPartitions = append(chainedPartitions, fmt.Sprintf("%s:%d", proptools.String(cp.Name), ril))
+		publicKey := android.BazelLabelForModuleSrcSingle(ctx, *cp.Public_key)
+		chainedPartitionKeys.Add(&publicKey)
+	}
+
+	attrs := &bazelVbmetaAttributes{
+		Partition_name:          v.properties.Partition_name,
+		Stem:                    v.properties.Stem,
+		Private_key:             *bazel.MakeLabelAttribute(android.BazelLabelForModuleSrcSingle(ctx, *v.properties.Private_key).Label),
+		Algorithm:               v.properties.Algorithm,
+		Rollback_index_location: v.properties.Rollback_index_location,
+		Partitions:              bazel.MakeLabelListAttribute(android.BazelLabelForModuleDeps(ctx, v.properties.Partitions)),
+		Chained_partitions:      chainedPartitions,
+		Chained_partition_keys:  bazel.MakeLabelListAttribute(chainedPartitionKeys),
+	}
+	if v.properties.Rollback_index_file != nil {
+		attrs.Rollback_index_file = *bazel.MakeLabelAttribute(android.BazelLabelForModuleSrcSingle(ctx, *v.properties.Rollback_index_file).Label)
+	}
+
+	ctx.CreateBazelTargetModule(
+		bazel.BazelTargetModuleProperties{
+			Rule_class:        "vbmeta",
+			Bzl_load_location: "//build/bazel/rules/avb:vbmeta.bzl",
+		},
+		android.CommonAttributes{Name: v.Name()},
+		attrs)
+}
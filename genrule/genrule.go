@@ -131,17 +131,17 @@ type generatorProperties struct {
 	//  $(depfile): a file to which dependencies will be written, if the depfile property is set to true.
 	//  $(genDir): the sandbox directory for this tool; contains $(out).
 	//  $$: a literal $
-	Cmd *string
+	Cmd *string `android:"arch_variant"`
 
 	// Enable reading a file containing dependencies in gcc format after the command completes
 	Depfile *bool
 
 	// name of the modules (if any) that produces the host executable.   Leave empty for
 	// prebuilts or scripts that do not need a module to build them.
-	Tools []string
+	Tools []string `android:"arch_variant"`
 
 	// Local files that are used by the tool
-	Tool_files []string `android:"path"`
+	Tool_files []string `android:"path,arch_variant"`
 
 	// List of directories to export generated headers from
 	Export_include_dirs []string
@@ -921,14 +921,11 @@ type BazelGenruleAttributes struct {
 
 // ConvertWithBp2build converts a Soong module -> Bazel target.
 func (m *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
-	// Bazel only has the "tools" attribute.
-	tools_prop := android.BazelLabelForModuleDeps(ctx, m.properties.Tools)
-	tool_files_prop := android.BazelLabelForModuleSrc(ctx, m.properties.Tool_files)
-	tools_prop.Append(tool_files_prop)
-
-	tools := bazel.MakeLabelListAttribute(tools_prop)
+	tools := bazel.LabelListAttribute{}
+	tools_labels := bazel.LabelList{}
 	srcs := bazel.LabelListAttribute{}
 	srcs_labels := bazel.LabelList{}
+	archCmds := map[bazel.ConfigurationAxis]map[string]*string{}
 	// Only cc_genrule is arch specific
 	if ctx.ModuleType() == "cc_genrule" {
 		for axis, configToProps := range m.GetArchVariantProperties(ctx, &generatorProperties{}) {
@@ -937,16 +934,34 @@ func (m *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 					labels := android.BazelLabelForModuleSrcExcludes(ctx, props.Srcs, props.Exclude_srcs)
 					srcs_labels.Append(labels)
 					srcs.SetSelectValue(axis, config, labels)
+
+					// Bazel only has the "tools" attribute.
+					toolLabels := android.BazelLabelForModuleDeps(ctx, props.Tools)
+					toolLabels.Append(android.BazelLabelForModuleSrc(ctx, props.Tool_files))
+					tools_labels.Append(toolLabels)
+					tools.SetSelectValue(axis, config, toolLabels)
+
+					if axis != bazel.NoConfigAxis && props.Cmd != nil {
+						if archCmds[axis] == nil {
+							archCmds[axis] = map[string]*string{}
+						}
+						archCmds[axis][config] = props.Cmd
+					}
 				}
 			}
 		}
 	} else {
+		// Bazel only has the "tools" attribute.
+		tools_labels = android.BazelLabelForModuleDeps(ctx, m.properties.Tools)
+		tools_labels.Append(android.BazelLabelForModuleSrc(ctx, m.properties.Tool_files))
+		tools = bazel.MakeLabelListAttribute(tools_labels)
+
 		srcs_labels = android.BazelLabelForModuleSrcExcludes(ctx, m.properties.Srcs, m.properties.Exclude_srcs)
 		srcs = bazel.MakeLabelListAttribute(srcs_labels)
 	}
 
 	var allReplacements bazel.LabelList
-	allReplacements.Append(tools.Value)
+	allReplacements.Append(bazel.FirstUniqueBazelLabelList(tools_labels))
 	allReplacements.Append(bazel.FirstUniqueBazelLabelList(srcs_labels))
 
 	// The Output_extension prop is not in an immediately accessible field
@@ -966,7 +981,9 @@ func (m *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 		}
 	}
 
-	replaceVariables := func(cmd string) string {
+	// replaceVariables replaces the Soong variables of cmd by their Bazel
+	// equivalent, with $(location) referring to the first of the given tools.
+	replaceVariables := func(cmd string, tools bazel.LabelList) string {
 		// Replace in and out variables with $< and $@
 		if ctx.ModuleType() == "gensrcs" {
 			cmd = strings.ReplaceAll(cmd, "$(in)", "$(SRC)")
@@ -976,9 +993,9 @@ func (m *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 			cmd = strings.Replace(cmd, "$(out)", "$(OUTS)", -1)
 		}
 		cmd = strings.Replace(cmd, "$(genDir)", "$(RULEDIR)", -1)
-		if len(tools.Value.Includes) > 0 {
-			cmd = strings.Replace(cmd, "$(location)", fmt.Sprintf("$(location %s)", tools.Value.Includes[0].Label), -1)
-			cmd = strings.Replace(cmd, "$(locations)", fmt.Sprintf("$(locations %s)", tools.Value.Includes[0].Label), -1)
+		if len(tools.Includes) > 0 {
+			cmd = strings.Replace(cmd, "$(location)", fmt.Sprintf("$(location %s)", tools.Includes[0].Label), -1)
+			cmd = strings.Replace(cmd, "$(locations)", fmt.Sprintf("$(locations %s)", tools.Includes[0].Label), -1)
 		}
 		for _, l := range allReplacements.Includes {
 			bpLoc := fmt.Sprintf("$(location %s)", l.OriginalModuleName)
@@ -992,7 +1009,19 @@ func (m *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 	}
 
 	var cmdProp bazel.StringAttribute
-	cmdProp.SetValue(replaceVariables(proptools.String(m.properties.Cmd)))
+	cmdProp.SetValue(replaceVariables(proptools.String(m.properties.Cmd), tools.Value))
+	for axis, configToCmd := range archCmds {
+		for config, cmd := range configToCmd {
+			// The arch-variant tools are appended to the base tools, so
+			// $(location) refers to the first base tool if there is any.
+			configTools := tools.Value
+			if len(configTools.Includes) == 0 {
+				configTools = tools.SelectValue(axis, config)
+			}
+			archCmd := replaceVariables(*cmd, configTools)
+			cmdProp.SetSelectValue(axis, config, &archCmd)
+		}
+	}
 	allProductVariableProps, errs := android.ProductVariableProperties(ctx, m)
 	for _, err := range errs {
 		ctx.ModuleErrorf("ProductVariableProperties error: %s", err)
@@ -1003,7 +1032,7 @@ func (m *Module) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 			if strValue, ok := value.(*string); ok && strValue != nil {
 				cmd = *strValue
 			}
-			cmd = replaceVariables(cmd)
+			cmd = replaceVariables(cmd, tools.Value)
 			cmdProp.SetSelectValue(productVariable.ConfigurationAxis(), productVariable.SelectKey(), &cmd)
 		}
 	}
@@ -1096,6 +1125,26 @@ func GenruleCcHeaderLabelMapper(ctx bazel.OtherModuleContext, label bazel.Label)
 	return label.Label, false
 }
 
+// GensrcsOutputExtensionLabelMapper returns a bazel.LabelMapper function to map the gensrcs
+// modules whose outputs have one of the given extensions (without the dot) to their target, e.g.
+// to partition the generated sources of a cc module by language.
+func GensrcsOutputExtensionLabelMapper(extensions ...string) bazel.LabelMapper {
+	return func(ctx bazel.OtherModuleContext, label bazel.Label) (string, bool) {
+		mod, exists := ctx.ModuleFromName(label.OriginalModuleName)
+		if !exists {
+			return label.Label, false
+		}
+		if m, ok := mod.(*Module); ok {
+			for _, propIntf := range m.GetProperties() {
+				if props, ok := propIntf.(*genSrcsProperties); ok {
+					return label.Label, android.InList(proptools.String(props.Output_extension), extensions)
+				}
+			}
+		}
+		return label.Label, false
+	}
+}
+
 type ccHeaderLibraryAttrs struct {
 	Hdrs []string
 
@@ -779,6 +779,28 @@ func TestGenruleDefaults(t *testing.T) {
 	android.AssertDeepEquals(t, "srcs", expectedSrcs, gen.properties.Srcs)
 }
 
+func TestGenruleArchVariantProperties(t *testing.T) {
+	// Only cc_genrule is arch specific, other genrules don't accept arch-variant
+	// cmd, tools or tool_files.
+	bp := `
+		genrule {
+			name: "gen",
+			tool_files: ["tool"],
+			cmd: "$(location) > $(out)",
+			out: ["out"],
+			arch: {
+				arm64: {
+					cmd: "$(location) --arm64 > $(out)",
+				},
+			},
+		}
+	`
+
+	prepareForGenRuleTest.
+		ExtendWithErrorHandler(android.FixtureExpectsOneErrorPattern(`unrecognized property "arch"`)).
+		RunTestWithBp(t, bp)
+}
+
 func TestGenruleAllowMissingDependencies(t *testing.T) {
 	bp := `
 		output {
@@ -1365,6 +1365,7 @@ func (c *AndroidAppCertificate) GenerateAndroidBuildActions(ctx android.ModuleCo
 type OverrideAndroidApp struct {
 	android.ModuleBase
 	android.OverrideModuleBase
+	android.BazelModuleBase
 }
 
 func (i *OverrideAndroidApp) GenerateAndroidBuildActions(_ android.ModuleContext) {
@@ -1383,6 +1384,7 @@ func OverrideAndroidAppModuleFactory() android.Module {
 
 	android.InitAndroidMultiTargetsArchModule(m, android.DeviceSupported, android.MultilibCommon)
 	android.InitOverrideModule(m)
+	android.InitBazelModule(m)
 	return m
 }
 
@@ -1879,3 +1881,92 @@ func (atha *AndroidTestHelperApp) ConvertWithBp2build(ctx android.Bp2buildMutato
 		ctx.CreateBazelTargetModule(props, commonAttrs, appAttrs)
 	}
 }
+
+// ConvertWithBp2build is used to convert override_android_app to Bazel. The
+// base android_app is converted again, with the overridden properties, into an
+// android_binary named after the override_android_app.
+func (o *OverrideAndroidApp) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
+	baseAppModuleName := o.GetOverriddenModuleName()
+	baseModule, exists := ctx.ModuleFromName(baseAppModuleName)
+	if !exists {
+		ctx.ModuleErrorf("base module %q doesn't exist", baseAppModuleName)
+		return
+	}
+	a, ok := baseModule.(*AndroidApp)
+	if !ok {
+		ctx.ModuleErrorf("base module %q is not an android_app", baseAppModuleName)
+		return
+	}
+	// The sources of the base app are relative to its package.
+	if ctx.OtherModuleDir(a) != ctx.ModuleDir() {
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_UNSUPPORTED,
+			"override_android_app in a different package than its base android_app")
+		return
+	}
+
+	var overridableProperties *overridableAppProperties
+	var overridableDeviceProperties *OverridableDeviceProperties
+	for _, p := range o.GetProperties() {
+		switch p := p.(type) {
+		case *overridableAppProperties:
+			overridableProperties = p
+		case *OverridableDeviceProperties:
+			overridableDeviceProperties = p
+		}
+	}
+	if unsupported := unsupportedOverrideAppProperties(a, overridableProperties, overridableDeviceProperties); len(unsupported) > 0 {
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_PROPERTY_UNSUPPORTED,
+			"override_android_app with "+strings.Join(unsupported, ", "))
+		return
+	}
+
+	ok, commonAttrs, appAttrs := convertWithBp2build(ctx, a)
+	if !ok {
+		return
+	}
+	// Helper targets are named after the base app, which already generates them
+	// in the same package.
+	if len(o.Bp2buildTargets()) > 0 {
+		ctx.MarkBp2buildUnconvertible(bp2build_metrics_proto.UnconvertedReasonType_UNSUPPORTED,
+			"override_android_app of an android_app generating helper targets")
+		return
+	}
+	if overridableProperties.Package_name != nil {
+		appAttrs.Custom_package = overridableProperties.Package_name
+	}
+	if overridableProperties.Certificate != nil {
+		appAttrs.Certificate, appAttrs.Certificate_name = android.BazelStringOrLabelFromProp(ctx, overridableProperties.Certificate)
+	}
+	commonAttrs.Name = o.Name()
+
+	props := bazel.BazelTargetModuleProperties{
+		Rule_class:        "android_binary",
+		Bzl_load_location: "//build/bazel/rules/android:android_binary.bzl",
+	}
+	ctx.CreateBazelTargetModule(props, commonAttrs, appAttrs)
+}
+
+// unsupportedOverrideAppProperties returns the properties of an override_android_app, or of its
+// base android_app, which the android_binary it is converted to can't express.
+func unsupportedOverrideAppProperties(a *AndroidApp, props *overridableAppProperties, deviceProps *OverridableDeviceProperties) []string {
+	var unsupported []string
+	if len(a.appProperties.Additional_certificates) > 0 {
+		unsupported = append(unsupported, "additional_certificates")
+	}
+	if props.Lineage != nil || a.overridableAppProperties.Lineage != nil {
+		unsupported = append(unsupported, "lineage")
+	}
+	if props.RotationMinSdkVersion != nil || a.overridableAppProperties.RotationMinSdkVersion != nil {
+		unsupported = append(unsupported, "rotationMinSdkVersion")
+	}
+	if props.Logging_parent != nil || a.overridableAppProperties.Logging_parent != nil {
+		unsupported = append(unsupported, "logging_parent")
+	}
+	if props.Rename_resources_package != nil {
+		unsupported = append(unsupported, "rename_resources_package")
+	}
+	if deviceProps.Stem != nil {
+		unsupported = append(unsupported, "stem")
+	}
+	return unsupported
+}
@@ -3198,9 +3198,15 @@ func javaLibraryBazelTargetModuleProperties() bazel.BazelTargetModuleProperties
 }
 
 func javaLibraryBp2Build(ctx android.Bp2buildMutatorContext, m *Library) {
+	javaLibraryBp2BuildWithName(ctx, m, m.Name())
+}
+
+// javaLibraryBp2BuildWithName creates a java_library (or kt_jvm_library) target with the given
+// name from m, along with its -neverlink counterpart. It returns false if m can't be converted.
+func javaLibraryBp2BuildWithName(ctx android.Bp2buildMutatorContext, m *Library, name string) bool {
 	commonAttrs, bp2BuildInfo, supported := m.convertLibraryAttrsBp2Build(ctx)
 	if !supported {
-		return
+		return false
 	}
 	depLabels := bp2BuildInfo.DepLabels
 
@@ -3222,7 +3228,6 @@ func javaLibraryBp2Build(ctx android.Bp2buildMutatorContext, m *Library) {
 		Deps:                 deps,
 		Exports:              exports,
 	}
-	name := m.Name()
 
 	if !bp2BuildInfo.hasKotlin {
 		props = javaLibraryBazelTargetModuleProperties()
@@ -3241,7 +3246,7 @@ func javaLibraryBp2Build(ctx android.Bp2buildMutatorContext, m *Library) {
 		},
 	}
 	ctx.CreateBazelTargetModule(props, android.CommonAttributes{Name: name + "-neverlink"}, neverLinkAttrs)
-
+	return true
 }
 
 type javaBinaryHostAttributes struct {
@@ -17,7 +17,10 @@ package java
 // This file contains the module implementations for runtime_resource_overlay and
 // override_runtime_resource_overlay.
 
-import "android/soong/android"
+import (
+	"android/soong/android"
+	"android/soong/bazel"
+)
 
 func init() {
 	RegisterRuntimeResourceOverlayBuildComponents(android.InitRegistrationContext)
@@ -32,6 +35,7 @@ type RuntimeResourceOverlay struct {
 	android.ModuleBase
 	android.DefaultableModuleBase
 	android.OverridableModuleBase
+	android.BazelModuleBase
 	aapt
 
 	properties            RuntimeResourceOverlayProperties
@@ -220,6 +224,7 @@ func RuntimeResourceOverlayFactory() android.Module {
 	android.InitAndroidMultiTargetsArchModule(module, android.DeviceSupported, android.MultilibCommon)
 	android.InitDefaultableModule(module)
 	android.InitOverridableModule(module, &module.properties.Overrides)
+	android.InitBazelModule(module)
 	return module
 }
 
@@ -255,3 +260,49 @@ func OverrideRuntimeResourceOverlayModuleFactory() android.Module {
 	android.InitOverrideModule(m)
 	return m
 }
+
+type bazelRuntimeResourceOverlayAttributes struct {
+	*bazelAapt
+	Deps                bazel.LabelListAttribute
+	Resource_libs       bazel.LabelListAttribute
+	Certificate         bazel.LabelAttribute
+	Certificate_name    bazel.StringAttribute
+	Theme               *string
+	Sdk_version         bazel.StringAttribute
+	Manifest_values     *manifestValueAttribute
+	Custom_package      *string
+	Target_package_name *string
+	Category            *string
+}
+
+// ConvertWithBp2build is used to convert runtime_resource_overlay to Bazel.
+func (r *RuntimeResourceOverlay) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
+	aapt, supported := r.convertAaptAttrsWithBp2Build(ctx)
+	if !supported {
+		return
+	}
+	certificate, certificateName := android.BazelStringOrLabelFromProp(ctx, r.properties.Certificate)
+
+	attrs := &bazelRuntimeResourceOverlayAttributes{
+		bazelAapt:        aapt,
+		Deps:             bazel.MakeLabelListAttribute(android.BazelLabelForModuleDeps(ctx, r.properties.Static_libs)),
+		Resource_libs:    bazel.MakeLabelListAttribute(android.BazelLabelForModuleDeps(ctx, r.properties.Resource_libs)),
+		Certificate:      certificate,
+		Certificate_name: certificateName,
+		Theme:            r.properties.Theme,
+		Sdk_version:      bazel.StringAttribute{Value: r.properties.Sdk_version},
+		Manifest_values: &manifestValueAttribute{
+			MinSdkVersion: r.properties.Min_sdk_version,
+		},
+		// TODO(b/209576404): handle package name override by product variable PRODUCT_MANIFEST_PACKAGE_NAME_OVERRIDES
+		Custom_package:      r.overridableProperties.Package_name,
+		Target_package_name: r.overridableProperties.Target_package_name,
+		Category:            r.overridableProperties.Category,
+	}
+
+	props := bazel.BazelTargetModuleProperties{
+		Rule_class:        "runtime_resource_overlay",
+		Bzl_load_location: "//build/bazel/rules/android:runtime_resource_overlay.bzl",
+	}
+	ctx.CreateBazelTargetModule(props, android.CommonAttributes{Name: r.Name()}, attrs)
+}
@@ -2311,6 +2311,13 @@ type bazelSdkLibraryAttributes struct {
 	System_server *bazel.Label
 }
 
+type bazelSdkLibraryStubsAttributes struct {
+	Api_surface string
+	Api_file    bazel.Label
+	Sdk_version bazel.StringAttribute
+	Deps        bazel.LabelListAttribute
+}
+
 // java_sdk_library bp2build converter
 func (module *SdkLibrary) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
 	if ctx.ModuleType() != "java_sdk_library" {
@@ -2318,11 +2325,25 @@ func (module *SdkLibrary) ConvertWithBp2build(ctx android.Bp2buildMutatorContext
 		return
 	}
 
+	// Without an sdk_version the implementation library can't be converted yet (see
+	// convertLibraryAttrsBp2Build), so only the api surfaces are converted.
+	sdkVersion := proptools.String(module.deviceProperties.Sdk_version)
+	convertComponents := sdkVersion != "" && sdkVersion != "core_platform"
+	if convertComponents && module.requiresRuntimeImplementationLibrary() {
+		if !javaLibraryBp2BuildWithName(ctx, &module.Library, module.implLibraryModuleName()) {
+			return
+		}
+	}
+
 	nameToAttr := make(map[string]*bazel.Label)
 
 	for _, scope := range module.getGeneratedApiScopes(ctx) {
 		apiSurfaceFile := android.BazelLabelForModuleSrcSingle(ctx, path.Join(module.getApiDir(), scope.apiFilePrefix+"current.txt"))
 		nameToAttr[scope.name] = &apiSurfaceFile
+
+		if convertComponents {
+			module.createStubsLibraryBp2build(ctx, scope, apiSurfaceFile)
+		}
 	}
 
 	attrs := bazelSdkLibraryAttributes{
@@ -2340,6 +2361,38 @@ func (module *SdkLibrary) ConvertWithBp2build(ctx android.Bp2buildMutatorContext
 	ctx.CreateBazelTargetModule(props, android.CommonAttributes{Name: module.Name()}, &attrs)
 }
 
+// createStubsLibraryBp2build creates the target for the stubs library of the given api scope,
+// which is compiled from the api file of the scope rather than from droidstubs generated sources.
+func (module *SdkLibrary) createStubsLibraryBp2build(ctx android.Bp2buildMutatorContext, apiScope *apiScope, apiFile bazel.Label) {
+	libs := append([]string{}, module.sdkLibraryProperties.Stub_only_libs...)
+	libs = append(libs, module.scopeToProperties[apiScope].Libs...)
+	if proptools.Bool(module.sdkLibraryProperties.Annotations_enabled) {
+		libs = append(libs, "stub-annotations")
+	}
+	var libLabels []bazel.Label
+	for _, lib := range android.FirstUniqueStrings(libs) {
+		neverlinkLabel := android.BazelLabelForModuleDepSingle(ctx, lib)
+		neverlinkLabel.Label = neverlinkLabel.Label + "-neverlink"
+		libLabels = append(libLabels, neverlinkLabel)
+	}
+	deps := bazel.MakeLabelList(libLabels)
+	deps.Append(android.BazelLabelForModuleDeps(ctx, module.sdkLibraryProperties.Stub_only_static_libs))
+	sdkVersion := module.sdkVersionForStubsLibrary(ctx, apiScope)
+
+	attrs := bazelSdkLibraryStubsAttributes{
+		Api_surface: apiScope.name,
+		Api_file:    apiFile,
+		Sdk_version: bazel.StringAttribute{Value: &sdkVersion},
+		Deps:        bazel.MakeLabelListAttribute(deps),
+	}
+	props := bazel.BazelTargetModuleProperties{
+		Rule_class:        "java_sdk_library_stubs",
+		Bzl_load_location: "//build/bazel/rules/java:sdk_library.bzl",
+	}
+
+	ctx.CreateBazelTargetModule(props, android.CommonAttributes{Name: module.stubsLibraryModuleName(apiScope)}, &attrs)
+}
+
 //
 // SDK library prebuilts
 //
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"android/soong/android"
+	"android/soong/bazel"
 	_ "android/soong/cc/config"
 
 	"github.com/google/blueprint"
@@ -32,11 +33,12 @@ func init() {
 }
 
 func registerKernelBuildComponents(ctx android.RegistrationContext) {
-	ctx.RegisterModuleType("prebuilt_kernel_modules", prebuiltKernelModulesFactory)
+	ctx.RegisterModuleType("prebuilt_kernel_modules", PrebuiltKernelModulesFactory)
 }
 
 type prebuiltKernelModules struct {
 	android.ModuleBase
+	android.BazelModuleBase
 
 	properties prebuiltKernelModulesProperties
 
@@ -55,10 +57,11 @@ type prebuiltKernelModulesProperties struct {
 // prebuilt_kernel_modules installs a set of prebuilt kernel module files to the correct directory.
 // In addition, this module builds modules.load, modules.dep, modules.softdep and modules.alias
 // using depmod and installs them as well.
-func prebuiltKernelModulesFactory() android.Module {
+func PrebuiltKernelModulesFactory() android.Module {
 	module := &prebuiltKernelModules{}
 	module.AddProperties(&module.properties)
 	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibFirst)
+	android.InitBazelModule(module)
 	return module
 }
 
@@ -70,16 +73,21 @@ func (pkm *prebuiltKernelModules) DepsMutator(ctx android.BottomUpMutatorContext
 	// do nothing
 }
 
+// installDirRel returns the install directory of the kernel modules, relative to the partition.
+func (pkm *prebuiltKernelModules) installDirRel() string {
+	if pkm.KernelVersion() != "" {
+		return filepath.Join("lib", "modules", pkm.KernelVersion())
+	}
+	return filepath.Join("lib", "modules")
+}
+
 func (pkm *prebuiltKernelModules) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	modules := android.PathsForModuleSrc(ctx, pkm.properties.Srcs)
 
 	depmodOut := runDepmod(ctx, modules)
 	strippedModules := stripDebugSymbols(ctx, modules)
 
-	installDir := android.PathForModuleInstall(ctx, "lib", "modules")
-	if pkm.KernelVersion() != "" {
-		installDir = installDir.Join(ctx, pkm.KernelVersion())
-	}
+	installDir := android.PathForModuleInstall(ctx, pkm.installDirRel())
 
 	for _, m := range strippedModules {
 		ctx.InstallFile(installDir, filepath.Base(m.String()), m)
@@ -168,3 +176,32 @@ func runDepmod(ctx android.ModuleContext, modules android.Paths) depmodOutputs {
 
 	return depmodOutputs{modulesLoad, modulesDep, modulesSoftdep, modulesAlias}
 }
+
+type bazelPrebuiltKernelModulesAttributes struct {
+	Srcs bazel.LabelListAttribute
+	Dir  string
+}
+
+// ConvertWithBp2build performs bp2build conversion of prebuilt_kernel_modules.
+func (pkm *prebuiltKernelModules) ConvertWithBp2build(ctx android.Bp2buildMutatorContext) {
+	var srcs bazel.LabelListAttribute
+	for axis, configToProps := range pkm.GetArchVariantProperties(ctx, &prebuiltKernelModulesProperties{}) {
+		for config, p := range configToProps {
+			if props, ok := p.(*prebuiltKernelModulesProperties); ok && props.Srcs != nil {
+				srcs.SetSelectValue(axis, config, android.BazelLabelForModuleSrc(ctx, props.Srcs))
+			}
+		}
+	}
+
+	props := bazel.BazelTargetModuleProperties{
+		Rule_class:        "prebuilt_kernel_modules",
+		Bzl_load_location: "//build/bazel/rules:prebuilt_kernel_modules.bzl",
+	}
+
+	attrs := &bazelPrebuiltKernelModulesAttributes{
+		Srcs: srcs,
+		Dir:  pkm.installDirRel(),
+	}
+
+	ctx.CreateBazelTargetModule(props, android.CommonAttributes{Name: pkm.Name()}, attrs)
+}
@@ -0,0 +1,128 @@
+#!/bin/bash -eu
+
+set -o pipefail
+
+# Tests that the BUILD files generated by bp2build for a curated set of
+# conversion scenarios are successfully loaded and analyzed by Bazel. This
+# catches regressions which only show up at Bazel loading or analysis time,
+# which the string comparisons of the bp2build unit tests miss.
+
+source "$(dirname "$0")/lib.sh"
+
+# Generates the BUILD files and runs the analysis of all the targets of the
+# given package.
+function _analyze_package {
+  local -r pkg="$1"
+
+  run_soong bp2build
+  if ! run_bazel cquery --config=android --config=bp2build --config=ci "//${pkg}/..."; then
+    fail "Bazel analysis of the targets generated for //${pkg} failed"
+  fi
+}
+
+function test_cc_library_arch_and_os_selects {
+  setup
+
+  mkdir -p a
+  cat > a/Android.bp <<'EOF'
+cc_library {
+  name: "liba",
+  srcs: ["a.cc"],
+  host_supported: true,
+  arch: {
+    arm64: {
+      cflags: ["-DARM64"],
+    },
+    x86_64: {
+      srcs: ["x86_64.cc"],
+    },
+  },
+  target: {
+    android: {
+      cflags: ["-DANDROID"],
+    },
+    host_linux: {
+      cflags: ["-DHOST_LINUX"],
+    },
+  },
+  static: {
+    cflags: ["-DSTATIC"],
+  },
+  shared: {
+    cflags: ["-DSHARED"],
+  },
+  stl: "none",
+  system_shared_libs: [],
+  bazel_module: { bp2build_available: true },
+}
+EOF
+  touch a/a.cc a/x86_64.cc
+
+  _analyze_package a
+}
+
+function test_cc_library_static_generated_headers {
+  setup
+
+  mkdir -p a
+  cat > a/Android.bp <<'EOF'
+genrule {
+  name: "gen_hdrs",
+  srcs: ["gen.h.in"],
+  out: ["include/gen.h"],
+  cmd: "cp $(in) $(out)",
+  export_include_dirs: ["include"],
+  bazel_module: { bp2build_available: true },
+}
+
+cc_library_static {
+  name: "liba",
+  srcs: ["a.cc"],
+  generated_headers: ["gen_hdrs"],
+  export_generated_headers: ["gen_hdrs"],
+  stl: "none",
+  system_shared_libs: [],
+  bazel_module: { bp2build_available: true },
+}
+EOF
+  touch a/gen.h.in
+  cat > a/a.cc <<'EOF'
+#include "gen.h"
+EOF
+
+  _analyze_package a
+}
+
+function test_gensrcs_with_python_binary_host_tool {
+  setup
+
+  mkdir -p a
+  cat > a/Android.bp <<'EOF'
+python_binary_host {
+  name: "gen_tool",
+  srcs: ["gen_tool.py"],
+  main: "gen_tool.py",
+  bazel_module: { bp2build_available: true },
+}
+
+gensrcs {
+  name: "gen_srcs",
+  srcs: ["a.in"],
+  tools: ["gen_tool"],
+  cmd: "$(location) $(in) $(out)",
+  output_extension: "txt",
+  bazel_module: { bp2build_available: true },
+}
+EOF
+  touch a/a.in
+  cat > a/gen_tool.py <<'EOF'
+import shutil
+import sys
+
+shutil.copyfile(sys.argv[1], sys.argv[2])
+EOF
+
+  _analyze_package a
+}
+
+scan_and_run_tests
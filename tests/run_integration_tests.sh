@@ -8,6 +8,10 @@ TOP="$(readlink -f "$(dirname "$0")"/../../..)"
 "$TOP/build/soong/tests/bootstrap_test.sh"
 "$TOP/build/soong/tests/mixed_mode_test.sh"
 "$TOP/build/soong/tests/bp2build_bazel_test.sh"
+# Runs the Bazel analysis of generated BUILD files, which is slow, so it is opt-in.
+if [[ "${RUN_BP2BUILD_ANALYSIS_TESTS:-}" == "true" ]]; then
+  "$TOP/build/soong/tests/bp2build_analysis_test.sh"
+fi
 "$TOP/build/soong/tests/persistent_bazel_test.sh"
 "$TOP/build/soong/tests/soong_test.sh"
 "$TOP/build/soong/tests/stale_metrics_files_test.sh"
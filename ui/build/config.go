@@ -87,6 +87,8 @@ type configImpl struct {
 	buildStartedTime         int64 // For metrics-upload-only - manually specify a build-started time
 	buildFromTextStub        bool
 	ensureAllowlistIntegrity bool   // For CI builds - make sure modules are mixed-built
+	bp2buildCheckOnly        bool   // For CI builds - fail on stale bp2build files instead of writing them
+	bp2buildRunValidations   bool   // For CI builds - validate the generated bp2build files
 	bazelExitCode            int32  // For b runs - necessary for updating NonZeroExit
 	besId                    string // For b runs, to identify the BuildEventService logs
 
@@ -803,6 +805,10 @@ func (c *configImpl) parseArgs(ctx Context, args []string) {
 			}
 		} else if arg == "--ensure-allowlist-integrity" {
 			c.ensureAllowlistIntegrity = true
+		} else if arg == "--bp2build-check-only" {
+			c.bp2buildCheckOnly = true
+		} else if arg == "--bp2build-run-validations" {
+			c.bp2buildRunValidations = true
 		} else if len(arg) > 0 && arg[0] == '-' {
 			parseArgNum := func(def int) int {
 				if len(arg) > 2 {
@@ -1687,6 +1693,14 @@ func (c *configImpl) EnsureAllowlistIntegrity() bool {
 	return c.ensureAllowlistIntegrity
 }
 
+func (c *configImpl) Bp2buildCheckOnly() bool {
+	return c.bp2buildCheckOnly
+}
+
+func (c *configImpl) Bp2buildRunValidations() bool {
+	return c.bp2buildRunValidations
+}
+
 // Returns a Time object if one was passed via a command-line flag.
 // Otherwise returns the passed default.
 func (c *configImpl) BuildStartedTimeOrDefault(defaultTime time.Time) time.Time {
@@ -317,6 +317,14 @@ func bootstrapBlueprint(ctx Context, config Config) {
 		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--ensure-allowlist-integrity")
 	}
 
+	bp2buildArgs := append(baseArgs, "--bp2build_marker", config.Bp2BuildFilesMarkerFile())
+	if config.Bp2buildCheckOnly() {
+		bp2buildArgs = append(bp2buildArgs, "--check-only")
+	}
+	if config.Bp2buildRunValidations() {
+		bp2buildArgs = append(bp2buildArgs, "--run-validations")
+	}
+
 	queryviewDir := filepath.Join(config.SoongOutDir(), "queryview")
 
 	pbfs := []PrimaryBuilderFactory{
@@ -328,13 +336,11 @@ func bootstrapBlueprint(ctx Context, config Config) {
 			specificArgs: mainSoongBuildExtraArgs,
 		},
 		{
-			name:        bp2buildFilesTag,
-			description: fmt.Sprintf("converting Android.bp files to BUILD files at %s/bp2build", config.SoongOutDir()),
-			config:      config,
-			output:      config.Bp2BuildFilesMarkerFile(),
-			specificArgs: append(baseArgs,
-				"--bp2build_marker", config.Bp2BuildFilesMarkerFile(),
-			),
+			name:         bp2buildFilesTag,
+			description:  fmt.Sprintf("converting Android.bp files to BUILD files at %s/bp2build", config.SoongOutDir()),
+			config:       config,
+			output:       config.Bp2BuildFilesMarkerFile(),
+			specificArgs: bp2buildArgs,
 		},
 		{
 			name:        bp2buildWorkspaceTag,
@@ -139,7 +139,7 @@ func PrebuiltEtcXmlFactory() android.Module {
 type bazelPrebuiltEtcXmlAttributes struct {
 	Src               bazel.LabelAttribute
 	Filename          bazel.LabelAttribute
-	Dir               string
+	Dir               bazel.StringAttribute
 	Installable       bazel.BoolAttribute
 	Filename_from_src bazel.BoolAttribute
 	Schema            *string